@@ -17,56 +17,103 @@ import (
 	"context"
 	"flag"
 	"fmt"
-	"net/http"
+	"log/slog"
+	"os"
 	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	awscfg "github.com/aws/aws-sdk-go-v2/config"
 	echoadapter "github.com/awslabs/aws-lambda-go-api-proxy/echo"
-	"github.com/casbin/casbin"
 	"github.com/labstack/echo/v4"
-	echoswag "github.com/swaggo/echo-swagger"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 
-	"github.com/cirius-go/portfolio-server/docs/swagger"
+	"github.com/cirius-go/portfolio-server/cmd/workers/migrate/migrations"
 	_ "github.com/cirius-go/portfolio-server/docs/swagger"
-	"github.com/cirius-go/portfolio-server/internal/api/apicms"
+	"github.com/cirius-go/portfolio-server/internal/app"
 	"github.com/cirius-go/portfolio-server/internal/config"
-	"github.com/cirius-go/portfolio-server/internal/service/servicecms"
+	"github.com/cirius-go/portfolio-server/internal/repo"
 	"github.com/cirius-go/portfolio-server/internal/uow"
+	"github.com/cirius-go/portfolio-server/pkg/awsutil"
+	"github.com/cirius-go/portfolio-server/pkg/cache"
 	"github.com/cirius-go/portfolio-server/pkg/db"
-	"github.com/cirius-go/portfolio-server/pkg/errors"
-	"github.com/cirius-go/portfolio-server/pkg/server"
-	"github.com/cirius-go/portfolio-server/util"
+	"github.com/cirius-go/portfolio-server/pkg/health"
+	"github.com/cirius-go/portfolio-server/pkg/idempotency"
+	"github.com/cirius-go/portfolio-server/pkg/lifecycle"
+	"github.com/cirius-go/portfolio-server/pkg/logging"
+	"github.com/cirius-go/portfolio-server/pkg/mailer"
+	"github.com/cirius-go/portfolio-server/pkg/metrics"
+	"github.com/cirius-go/portfolio-server/pkg/ratelimit"
+	"github.com/cirius-go/portfolio-server/pkg/tracing"
 )
 
 var (
 	cfgFile    = flag.String("cfg", ".env", "the path to the config file")
 	exampleCfg = flag.Bool("example", false, "print the example config")
+	dumpCfg    = flag.Bool("dump", false, "print the loaded config by source with its secrets redacted, and its problems")
 )
 
 func main() {
 	flag.Parse()
 
-	// load configuration
-	cfg, err := config.Load(*cfgFile)
+	if *exampleCfg {
+		panicIf(config.WriteExample(os.Stdout))
+		return
+	}
+	if *dumpCfg {
+		dump()
+		return
+	}
+
+	// load configuration, it is reloaded when its sources change.
+	store, err := loadConfig(context.Background())
 	panicIf(err)
+	cfg := store.Current().Config
+	logging.Setup(cfg.Log)
 
-	fmt.Println("Stage:", config.GetStage())
+	slog.Info("starting api", "stage", config.GetStage())
+
+	// the resources are released in reverse order on shutdown, or when main
+	// panics.
+	lc := lifecycle.New(cfg.ShutdownTimeout).WithCloseTimeout(cfg.ShutdownCloseTimeout)
+	defer lc.Shutdown()
+
+	// tracing
+	tp, err := tracing.Setup(context.Background(), cfg.Tracing)
+	panicIf(err)
+	lc.Add("tracer", tp.Shutdown)
 
 	// connect to the database
 	pg, err := db.NewPostgres(cfg.PGDB)
 	panicIf(err)
-	defer pg.Conn.Close()
+	lc.AddCloser("database", pg.Close)
+	repo.ConfigureLogger(pg.Logger)
+
+	// metrics, lambda writes them to its logs so it leaves out the runtime
+	// ones.
+	reg := metrics.NewRegistry(!config.IsInAWSLambda())
+	panicIf(pg.RegisterMetrics(reg))
+
+	// application cache
+	appCache, err := cache.New(cfg.Cache)
+	panicIf(err)
+	// the replicas may still serve the data of an invalidation.
+	appCache.WithFreshReads(cfg.Cache.FreshWindow, db.WithPrimary)
+	lc.AddCloser("cache", appCache.Close)
+
+	// rate limiter
+	limiter, err := ratelimit.New(cfg.RateLimit, pg.DB)
+	panicIf(err)
+	lc.AddCloser("rate limiter", limiter.Close)
 
 	// create unit of work
-	unitOfWork := uow.New(pg.DB)
+	unitOfWork := uow.New(pg.DB).
+		WithCache(appCache).
+		WithObserver(uow.NewMetricsObserver(reg))
 
 	// init rbac enforcer
-	enf := casbin.NewEnforcer(
-		util.NewRBACModel(),
-		config.IsLocal(), // debug if local
-	)
+	enf := app.NewEnforcer(config.IsLocal()) // debug if local
 
 	// init context
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
@@ -79,49 +126,83 @@ func main() {
 	if config.IsLocal() {
 		awsCfg, err = config.GetAWSConfigWithExecRole(ctx)
 		panicIf(err)
-		fmt.Println("AWS Runtime Environment:", awsCfg.RuntimeEnvironment)
+		slog.Info("aws runtime environment", "env", awsCfg.RuntimeEnvironment)
 	}
 
-	// create services
-	var (
-		//+codegen=DefineCmsServices
-		userSvc    = servicecms.NewUser(unitOfWork, enf)
-		projectSvc = servicecms.NewProject(unitOfWork, enf)
-		articleSvc = servicecms.NewArticle(unitOfWork, enf)
-	)
-
-	// new http server with config
-	srvCfg := server.C().
-		SetAddress(cfg.HTTPServer.Host, cfg.HTTPServer.Port).
-		SetCustomErrorHandler(errors.CreateEchoErrorHandler())
-	srv := server.NewHTTPWithConfig(srvCfg)
-	router := srv.Echo
-	router.GET("/", func(c echo.Context) error {
-		return c.JSON(http.StatusOK, map[string]any{
-			"Status": "OK",
+	// mailer
+	mail, err := mailer.New(cfg.Mailer, awsCfg)
+	panicIf(err)
+	if c, ok := mail.(interface{ Close() error }); ok {
+		lc.AddCloser("mailer", c.Close)
+	}
+
+	// readiness checks
+	checker, err := newChecker(cfg, pg, awsCfg)
+	panicIf(err)
+
+	// wire the api
+	a := app.New(app.Deps{
+		Config:      cfg,
+		ConfigStore: store,
+		UOW:         unitOfWork,
+		Cache:       appCache,
+		Mailer:      mail,
+		Enforcer:    enf,
+		Metrics:     reg,
+		Health:      checker,
+		RateLimiter: limiter,
+		Idempotency: idempotency.NewPostgres(pg.DB, cfg.Idempotency),
+	})
+
+	// invite the first admin
+	err = a.Invitations.Bootstrap(ctx, cfg.CMSOnboarding.BootstrapAdminEmail)
+	panicIf(err)
+
+	if config.IsInAWSLambda() {
+		startLambda(a.HTTP.Echo, metrics.NewEMF(reg, cfg.Metrics.Namespace, os.Stdout), tp, lc)
+		return
+	}
+
+	if port := cfg.Metrics.Port; port != 0 {
+		addr := fmt.Sprintf("%s:%d", cfg.HTTPServer.Host, port)
+		lc.Go("metrics", func(ctx context.Context) error {
+			return metrics.Serve(ctx, addr, reg, cfg.Metrics.Token)
 		})
+	}
+	lc.Go("config", func(ctx context.Context) error {
+		return store.Watch(ctx, cfg.ConfigReload)
 	})
+	lc.Go("http", a.HTTP.Serve)
+	panicIf(lc.Run())
+}
 
-	{
-		swagger.SwaggerInfo.Host = fmt.Sprintf("%s:%d", cfg.HTTPServer.Host, cfg.HTTPServer.Port)
-		router.GET("/swagger/*", echoswag.WrapHandler)
+// loadConfig loads and validates the config from the default sources into a
+// store which reloads them.
+func loadConfig(ctx context.Context) (*config.Store, error) {
+	sources, err := config.Sources(ctx, *cfgFile)
+	if err != nil {
+		return nil, err
+	}
+	cfg, origin, err := config.ReadFrom(ctx, sources...)
+	if err != nil {
+		return nil, err
 	}
-	// bind services to the http server
-	cmsRouter := router.Group("/cms")
-	for _, registrar := range []HTTPRegistrar{
-		//+codegen=DefineCmsAPIs
-		apicms.NewUser(userSvc),
-		apicms.NewProject(projectSvc),
-		apicms.NewArticle(articleSvc),
-	} {
-		registrar.RegisterHTTP(cmsRouter)
+	if err := cfg.Validate(); err != nil {
+		return nil, err
 	}
+	return config.NewStore(cfg, origin, sources...), nil
+}
 
-	if config.IsInAWSLambda() {
-		startLambda(router)
-	} else {
-		err = server.StartHTTP(srv)
-		panicIf(err)
+// dump prints the config and the source of its values without validating it
+// first, so that its problems are reported along with the values.
+func dump() {
+	cfg, origin, err := config.Read(context.Background(), *cfgFile)
+	panicIf(err)
+	panicIf(cfg.WriteDump(os.Stdout, origin))
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 }
 
@@ -131,15 +212,46 @@ func panicIf(err error) {
 	}
 }
 
-// HTTPRegistrar is a handler that registers HTTP handlers.
-type HTTPRegistrar interface {
-	RegisterHTTP(g *echo.Group)
+// newChecker checks that the database is reachable and migrated, and that
+// the assets bucket is accessible when one is set.
+func newChecker(cfg *config.Config, pg *db.Postgres, awsCfg aws.Config) (*health.Checker, error) {
+	mp, err := migrations.NewProvider(pg.Conn)
+	if err != nil {
+		return nil, err
+	}
+
+	checker := health.NewChecker(3*time.Second).
+		Add("database", pg.Conn.PingContext).
+		Add("migrations", migrations.VersionCheck(mp))
+	if bucket := cfg.AssetsBucket.Name; bucket != "" {
+		s3 := awsutil.NewS3(awsCfg)
+		checker.Add("assets_bucket", func(ctx context.Context) error {
+			return s3.HeadBucket(ctx, bucket)
+		})
+	}
+	return checker, nil
 }
 
-func startLambda(router *echo.Echo) {
+// startLambda serves the invocations, the resources are released when the
+// function instance is terminated.
+func startLambda(router *echo.Echo, emf *metrics.EMF, tp *sdktrace.TracerProvider, lc *lifecycle.Manager) {
 	router.HideBanner = true
 	echoLambda := echoadapter.NewV2(router)
-	lambda.Start(func(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	lambda.StartWithOptions(func(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+		defer flushTelemetry(ctx, emf, tp)
 		return echoLambda.ProxyWithContext(ctx, req)
-	})
+	}, lambda.WithEnableSIGTERM(func() {
+		lc.Shutdown()
+	}))
+}
+
+// flushTelemetry writes the metrics and exports the spans before the
+// function is frozen.
+func flushTelemetry(ctx context.Context, emf *metrics.EMF, tp *sdktrace.TracerProvider) {
+	if err := emf.Flush(); err != nil {
+		slog.Error("failed to write metrics", "error", err)
+	}
+	if err := tp.ForceFlush(ctx); err != nil {
+		slog.Error("failed to export spans", "error", err)
+	}
 }
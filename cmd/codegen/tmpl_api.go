@@ -72,7 +72,7 @@ type {{ $ident }}Service interface {
 }`,
 					},
 					{
-						Path: "cmd/api/main.go",
+						Path: "internal/app/app.go",
 						Name: "DeclApi",
 						Rule: codegen.TemplateDefinitionRule{
 							AppendContentAt: codegen.RuleAppendContentAtPlaceholder,
@@ -108,8 +108,8 @@ type {{ $ident }}Service interface {
 //	@Description {{ $actionIdent }}
 //	@Tags {{ $subdomain }}/{{ $ident | pKebab | lower }}
 //	@Accept json
-//	@Produce json
-//	@Security BearerAuth
+//	@Produce json{{ if ne $subdomain "public" }}
+//	@Security BearerAuth{{ end }}
 //	@Param Payload body dto{{ $subdomain }}.{{ $actionIdent }}{{ $ident }}Req true "JSON Request Payload"
 //	@Success 200 {object} dto{{ $subdomain }}.{{ $actionIdent }}{{ $ident }}Res "JSON Response Payload"
 //	@Failure 400 {object} dto.ErrorRes "JSON Response Payload"
@@ -168,8 +168,8 @@ func (s *{{ $ident }}) {{ $actionIdent }}(c echo.Context) error {
 //	@Description {{ $actionIdent }}
 //	@Tags {{ $subdomain }}/{{ $ident | pKebab | lower }}
 //	@Accept json
-//	@Produce json
-//	@Security BearerAuth
+//	@Produce json{{ if ne $subdomain "public" }}
+//	@Security BearerAuth{{ end }}
 //	@Param ID path string true "ID"
 //	@Param Payload body dto{{ $subdomain }}.{{ $actionIdent }}{{ $ident }}Req true "JSON Request Payload"
 //	@Success 200 {object} dto{{ $subdomain }}.{{ $actionIdent }}{{ $ident }}Res "JSON Response Payload"
@@ -229,8 +229,8 @@ func (s *{{ $ident }}) {{ $actionIdent }}(c echo.Context) error {
 //	@Description {{ $actionIdent }}
 //	@Tags {{ $subdomain }}/{{ $ident | pKebab | lower }}
 //	@Accept json
-//	@Produce json
-//	@Security BearerAuth
+//	@Produce json{{ if ne $subdomain "public" }}
+//	@Security BearerAuth{{ end }}
 //	@Param ID path string true "ID"
 //	@Param Payload body dto{{ $subdomain }}.{{ $actionIdent }}{{ $ident }}Req true "JSON Request Payload"
 //	@Success 200 {object} dto{{ $subdomain }}.{{ $actionIdent }}{{ $ident }}Res "JSON Response Payload"
@@ -288,8 +288,8 @@ func (s *{{ $ident }}) {{ $actionIdent }}(c echo.Context) error {
 //	@Description {{ $actionIdent }}
 //	@Tags {{ $subdomain }}/{{ $ident | pKebab | lower }}
 //	@Accept json
-//	@Produce json
-//	@Security BearerAuth
+//	@Produce json{{ if ne $subdomain "public" }}
+//	@Security BearerAuth{{ end }}
 //	@Param ID path string true "ID"
 //	@Success 200 {object} dto{{ $subdomain }}.{{ $actionIdent }}{{ $ident }}Res "JSON Response Payload"
 //	@Failure 400 {object} dto.ErrorRes "JSON Response Payload"
@@ -346,8 +346,8 @@ func (s *{{ $ident }}) {{ $actionIdent }}(c echo.Context) error {
 //	@Description {{ $actionIdent }}
 //	@Tags {{ $subdomain }}/{{ $ident | pKebab | lower }}
 //	@Accept json
-//	@Produce json
-//	@Security BearerAuth
+//	@Produce json{{ if ne $subdomain "public" }}
+//	@Security BearerAuth{{ end }}
 //	@Param ID path string true "ID"
 //	@Param Payload body dto{{ $subdomain }}.{{ $actionIdent }}{{ $ident }}Req true "JSON Request Payload"
 //	@Success 200 {object} dto{{ $subdomain }}.{{ $actionIdent }}{{ $ident }}Res "JSON Response Payload"
@@ -405,8 +405,8 @@ func (s *{{ $ident }}) {{ $actionIdent }}(c echo.Context) error {
 //	@Description {{ $actionIdent }}
 //	@Tags {{ $subdomain }}/{{ $ident | pKebab | lower }}
 //	@Accept json
-//	@Produce json
-//	@Security BearerAuth
+//	@Produce json{{ if ne $subdomain "public" }}
+//	@Security BearerAuth{{ end }}
 //	@Success 200 {object} dto{{ $subdomain }}.{{ $actionIdent }}{{ $ident }}Res "JSON Response Payload"
 //	@Failure 400 {object} dto.ErrorRes "JSON Response Payload"
 //	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
@@ -68,6 +68,34 @@ type {{ $repoName }} interface {
 // {{ $repoName }} retrieve cached unit or init a new one.
 func (u *uow) {{ $repoName }}() {{ $repoName }} {
 	return lazyCache(u, "{{ $repoName }}", repo.New{{ $repoName }})
+}`,
+					},
+					{
+						Path: "internal/uow/uowfake/uow.go",
+						Name: "ImplementFakeUnit",
+						Rule: codegen.TemplateDefinitionRule{
+							AppendContentAt: codegen.RuleAppendContentAtEnd,
+						},
+						ContentTmpl: `{{- $repoName := .entity | piCamel -}}
+{{- $modelName := .entity | siCamel }}
+
+// {{ $repoName }} returns the fake unit.
+func (u *UnitOfWork) {{ $repoName }}() uow.{{ $repoName }} {
+	return &fake{{ $repoName }}{tableOf[model.{{ $modelName }}](u.store)}
+}`,
+					},
+					{
+						Path: "internal/uow/uowfake/repos.go",
+						Name: "InitFakeRepo",
+						Rule: codegen.TemplateDefinitionRule{
+							AppendContentAt: codegen.RuleAppendContentAtEnd,
+						},
+						ContentTmpl: `{{- $repoName := .entity | piCamel -}}
+{{- $modelName := .entity | siCamel }}
+
+// fake{{ $repoName }} is the fake of uow.{{ $repoName }}.
+type fake{{ $repoName }} struct {
+	*table[model.{{ $modelName }}]
 }`,
 					},
 				},
@@ -20,18 +20,23 @@ func mkServiceImplGT() codegen.GroupTemplate {
 				},
 				ContentTmpl: `package service{{ .subdomain | gopkg }}
 {{- $service_name := .entity | siCamel }}
+{{- $rbac := ne (.subdomain | gopkg) "public" }}
 // {{ $service_name }} is a service struct that encapsulates business logic.
 type {{ $service_name }} struct {
 	service.Service
 	uow uow.UnitOfWork
+{{- if $rbac }}
 	enf RBACEnforcer
+{{- end }}
 }
 
 // New{{ $service_name }} creates a new instance of {{ $service_name }} service.
-func New{{ $service_name }}(uow uow.UnitOfWork, enf RBACEnforcer) *{{ $service_name }} {
+func New{{ $service_name }}(uow uow.UnitOfWork{{ if $rbac }}, enf RBACEnforcer{{ end }}) *{{ $service_name }} {
 	s := &{{ $service_name }}{
 		uow: uow,
+{{- if $rbac }}
 		enf: enf,
+{{- end }}
 	}
 	return s
 }`,
@@ -81,31 +86,36 @@ func mkServiceGT() codegen.GroupTemplate {
 				},
 				ContentTmpl: `package service{{ .subdomain | gopkg }}
 {{- $service_name := .entity | siCamel }}
+{{- $rbac := ne (.subdomain | gopkg) "public" }}
 // {{ $service_name }} is a service struct that encapsulates business logic.
 type {{ $service_name }} struct {
 	service.Service
 	uow uow.UnitOfWork
+{{- if $rbac }}
 	enf RBACEnforcer
+{{- end }}
 }
 
 // New{{ $service_name }} creates a new instance of {{ $service_name }} service.
-func New{{ $service_name }}(uow uow.UnitOfWork, enf RBACEnforcer) *{{ $service_name }} {
+func New{{ $service_name }}(uow uow.UnitOfWork{{ if $rbac }}, enf RBACEnforcer{{ end }}) *{{ $service_name }} {
 	s := &{{ $service_name }}{
 		uow: uow,
+{{- if $rbac }}
 		enf: enf,
+{{- end }}
 	}
 	return s
 }`,
 				Output: []codegen.SimpleTemplateOutput{
 					{
-						Path: "cmd/api/main.go",
+						Path: "internal/app/app.go",
 						Name: "DeclSvc",
 						Rule: codegen.TemplateDefinitionRule{
 							AppendContentAt: codegen.RuleAppendContentAtPlaceholder,
 							Placeholder:     "Define{{ .subdomain | siCamel }}Services",
 						},
 						ContentTmpl: `{{- $service_name := .entity | siCamel -}}
-				{{ .entity | sLowerCamel }}Svc = service{{ .subdomain | gopkg }}.New{{ $service_name }}(unitOfWork, enf)`,
+				{{ .entity | sLowerCamel }}Svc = service{{ .subdomain | gopkg }}.New{{ $service_name }}(unitOfWork{{ if ne (.subdomain | gopkg) "public" }}, enf{{ end }})`,
 					},
 				},
 			},
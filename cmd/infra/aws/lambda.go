@@ -7,6 +7,9 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/cloudwatch"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/ec2"
 	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/iam"
 	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/lambda"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
@@ -21,6 +24,9 @@ type LambdaConfig struct {
 	Arch            pulumi.String `pulumi:"arch"`
 	MemorySize      pulumi.Int    `pulumi:"memorySize"`
 	ExecRoleAssumer pulumi.String `pulumi:"execRoleAssumer"`
+	// Schedule is the EventBridge schedule expression invoking a worker,
+	// e.g. "rate(1 minute)". Workers without schedule are invoked manually.
+	Schedule pulumi.String `pulumi:"schedule"`
 }
 
 type LambdaResourceConfig struct {
@@ -32,12 +38,14 @@ type LambdaResourceConfig struct {
 }
 
 type LambdaResources struct {
-	ExecutionRole     *iam.Role                          `pulumi:"executionRole"`
-	AssumerRolePolicy *iam.RolePolicy                    `pulumi:"assumerRolePolicy"`
-	LoggingPolicy     *iam.RolePolicy                    `pulumi:"loggingPolicy"`
-	QuerySSMPolicy    *iam.RolePolicy                    `pulumi:"querySSMPolicy"`
-	ApiLambda         *lambda.Function                   `pulumi:"apiLambda"`
-	WorkerLambdas     map[pulumi.String]*lambda.Function `pulumi:"workerLambdas"`
+	ExecutionRole       *iam.Role                               `pulumi:"executionRole"`
+	AssumerRolePolicy   *iam.RolePolicy                         `pulumi:"assumerRolePolicy"`
+	LoggingPolicy       *iam.RolePolicy                         `pulumi:"loggingPolicy"`
+	QuerySSMPolicy      *iam.RolePolicy                         `pulumi:"querySSMPolicy"`
+	InvalidateCDNPolicy *iam.RolePolicy                         `pulumi:"invalidateCDNPolicy"`
+	ApiLambda           *lambda.Function                        `pulumi:"apiLambda"`
+	WorkerLambdas       map[pulumi.String]*lambda.Function      `pulumi:"workerLambdas"`
+	WorkerSchedules     map[pulumi.String]*cloudwatch.EventRule `pulumi:"workerSchedules"`
 }
 
 func createLambdaResources(ctx *pulumi.Context) (*LambdaResources, error) {
@@ -45,7 +53,8 @@ func createLambdaResources(ctx *pulumi.Context) (*LambdaResources, error) {
 		cfg  = config.New(ctx, "")
 		lCfg = &LambdaResourceConfig{}
 		res  = &LambdaResources{
-			WorkerLambdas: map[pulumi.String]*lambda.Function{},
+			WorkerLambdas:   map[pulumi.String]*lambda.Function{},
+			WorkerSchedules: map[pulumi.String]*cloudwatch.EventRule{},
 		}
 		err error
 	)
@@ -146,16 +155,62 @@ func createLambdaResources(ctx *pulumi.Context) (*LambdaResources, error) {
 		}`),
 	})
 
+	// the outbox worker invalidates the cdn copies of the public routes on
+	// publish, only on the distribution of the public api.
+	distributionID := ConfigVar(lCfg.AppEnv, "PUBLIC_CDN_DISTRIBUTION_ID")
+	if distributionID != "" {
+		caller, err := aws.GetCallerIdentity(ctx, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		invalidateCDNPolicyName := fmt.Sprintf("%s-lambda-invalidate-cdn-policy", namespace)
+		res.InvalidateCDNPolicy, err = iam.NewRolePolicy(ctx, invalidateCDNPolicyName, &iam.RolePolicyArgs{
+			Name: pulumi.String(invalidateCDNPolicyName),
+			Role: res.ExecutionRole,
+			Policy: pulumi.Sprintf(`{
+				"Version": "2012-10-17",
+				"Statement": [
+					{
+						"Effect": "Allow",
+						"Action": "cloudfront:CreateInvalidation",
+						"Resource": "arn:aws:cloudfront::%s:distribution/%s"
+					}
+				]
+			}`, caller.AccountId, distributionID),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var (
 		runtime    = util.IfZero(lambda.RuntimeCustomAL2, lambda.Runtime(lCfg.Runtime))
 		handler    = util.IfZero(pulumi.String("bootstrap"), lCfg.HandlerName)
 		arch       = util.IfZero(pulumi.String("arm64"), lCfg.Arch)
 		memorySize = util.IfZero(pulumi.Int(128), lCfg.MemorySize)
+		vars       = FlatMapConfig(lCfg.AppEnv)
 		envs       = lambda.FunctionEnvironmentArgs{
-			Variables: FlatMapConfig(lCfg.AppEnv),
+			Variables: vars,
 		}
 	)
 
+	// behind the cdn the peer of the api is a cloudfront edge, its
+	// X-Forwarded-For gives the client IP counted by the rate limits.
+	if distributionID != "" && ConfigVar(lCfg.AppEnv, "HTTP_SERVER_TRUSTED_PROXIES") == "" {
+		edges, err := ec2.LookupManagedPrefixList(ctx, &ec2.LookupManagedPrefixListArgs{
+			Name: pulumi.StringRef("com.amazonaws.global.cloudfront.origin-facing"),
+		})
+		if err != nil {
+			return nil, err
+		}
+		cidrs := make([]string, 0, len(edges.Entries))
+		for _, e := range edges.Entries {
+			cidrs = append(cidrs, e.Cidr)
+		}
+		vars["HTTP_SERVER_TRUSTED_PROXIES"] = pulumi.String(strings.Join(cidrs, ","))
+	}
+
 	apiFnName := fmt.Sprintf("%s-api", namespace)
 	apiFnArgs := &lambda.FunctionArgs{
 		Name:          pulumi.String(apiFnName),
@@ -197,18 +252,59 @@ func createLambdaResources(ctx *pulumi.Context) (*LambdaResources, error) {
 				}),
 			}
 			dependedOnPolicies = []pulumi.Resource{res.LoggingPolicy, res.QuerySSMPolicy}
+			customCfg          = lCfg.CustomWorkerLambda[pulumi.String(workerName)]
 		)
 
-		mergeLambdaArgsWithConfig(fnArgs, lCfg.CustomWorkerLambda[pulumi.String(workerName)])
+		if res.InvalidateCDNPolicy != nil {
+			dependedOnPolicies = append(dependedOnPolicies, res.InvalidateCDNPolicy)
+		}
+		mergeLambdaArgsWithConfig(fnArgs, customCfg)
 		res.WorkerLambdas[workerName], err = lambda.NewFunction(ctx, workerFnName, fnArgs, pulumi.DependsOn(dependedOnPolicies))
 		if err != nil {
 			return nil, err
 		}
+
+		if customCfg != nil && customCfg.Schedule != "" {
+			res.WorkerSchedules[workerName], err = scheduleLambda(ctx, workerFnName, res.WorkerLambdas[workerName], customCfg.Schedule)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return res, nil
 }
 
+// scheduleLambda invokes the function on the schedule expression.
+func scheduleLambda(ctx *pulumi.Context, fnName string, fn *lambda.Function, schedule pulumi.String) (*cloudwatch.EventRule, error) {
+	ruleName := fmt.Sprintf("%s-schedule", fnName)
+	rule, err := cloudwatch.NewEventRule(ctx, ruleName, &cloudwatch.EventRuleArgs{
+		Name:               pulumi.String(ruleName),
+		ScheduleExpression: schedule,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := lambda.NewPermission(ctx, ruleName, &lambda.PermissionArgs{
+		Action:    pulumi.String("lambda:InvokeFunction"),
+		Function:  fn.Name,
+		Principal: pulumi.String("events.amazonaws.com"),
+		SourceArn: rule.Arn,
+	}); err != nil {
+		return nil, err
+	}
+
+	if _, err := cloudwatch.NewEventTarget(ctx, ruleName, &cloudwatch.EventTargetArgs{
+		Rule: rule.Name,
+		Arn:  fn.Arn,
+	}); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
 func mergeLambdaArgsWithConfig(fnArgs *lambda.FunctionArgs, cfg *LambdaConfig) {
 	if fnArgs == nil || cfg == nil {
 		return
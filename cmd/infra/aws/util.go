@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
@@ -41,6 +42,20 @@ func FlatMapConfig(vars map[string]any) pulumi.StringMap {
 	return result
 }
 
+// ConfigVar returns the value of the variable of FlatMapConfig(vars), e.g.
+// PUBLIC_CDN_DISTRIBUTION_ID, or "" when it is not set.
+func ConfigVar(vars map[string]any, name string) string {
+	flattened := make(map[string]any)
+	flattenMap("", vars, "_", flattened)
+
+	for k, v := range flattened {
+		if strings.EqualFold(k, name) && v != nil {
+			return fmt.Sprint(v)
+		}
+	}
+	return ""
+}
+
 // MustSlice converts an interface to a slice of a specific type.
 func MustSlice[T any](v any) []T {
 	if v == nil {
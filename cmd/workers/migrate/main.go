@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"embed"
 	"errors"
 	"flag"
 	"text/template"
@@ -11,15 +10,14 @@ import (
 	"github.com/pressly/goose/v3"
 	"github.com/spf13/cobra"
 
-	_ "github.com/cirius-go/portfolio-server/cmd/workers/migrate/migrations"
+	"github.com/cirius-go/portfolio-server/cmd/workers/migrate/migrations"
 	"github.com/cirius-go/portfolio-server/internal/config"
 	"github.com/cirius-go/portfolio-server/pkg/db"
+	"github.com/cirius-go/portfolio-server/pkg/logging"
 )
 
 var (
-	//go:embed migrations
-	migrations embed.FS
-	cfgFile    = flag.String("cfg", ".env", "the path to the config file")
+	cfgFile = flag.String("cfg", ".env", "the path to the config file")
 
 	tmpl = template.Must(template.New("goose.gorm-migration").Parse(`
 package migrations
@@ -64,20 +62,21 @@ func down{{.CamelName}}(ctx context.Context, tx *sql.DB) error {
 func main() {
 	flag.Parse()
 
-	cfg, err := config.Load(*cfgFile)
+	cfg, err := config.Load(context.Background(), *cfgFile)
 	if err != nil {
 		panic(err)
 	}
+	logging.Setup(cfg.Log)
 
 	pg, err := db.NewPostgres(cfg.PGDB)
 	if err != nil {
 		panic(err)
 	}
-	defer pg.Conn.Close()
+	defer pg.Close()
 
-	goose.SetBaseFS(migrations)
+	goose.SetBaseFS(migrations.FS)
 	goose.SetDialect("postgres")
-	goose.SetTableName("migrations")
+	goose.SetTableName(migrations.TableName)
 	goose.SetVerbose(true)
 	ctx := context.Background()
 	ctx, cancel := context.WithTimeout(ctx, 15*time.Minute)
@@ -111,7 +110,7 @@ func main() {
 		Example: "migrate up",
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return goose.RunWithOptionsContext(ctx, "up", pg.Conn, "migrations", args, goose.WithAllowMissing())
+			return goose.RunWithOptionsContext(ctx, "up", pg.Conn, ".", args, goose.WithAllowMissing())
 		},
 	})
 
@@ -121,7 +120,7 @@ func main() {
 		Example: `migrate down`,
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return goose.RunWithOptionsContext(ctx, "down", pg.Conn, "migrations", args, goose.WithAllowMissing())
+			return goose.RunWithOptionsContext(ctx, "down", pg.Conn, ".", args, goose.WithAllowMissing())
 		},
 	})
 
@@ -0,0 +1,56 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+	"gorm.io/gorm"
+
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+)
+
+func init() {
+	goose.AddMigrationNoTxContext(upCreateContentTables, downCreateContentTables)
+}
+
+func upCreateContentTables(ctx context.Context, tx *sql.DB) error {
+	gdb, err := initDB(tx)
+	if err != nil {
+		return err
+	}
+
+	return gdb.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Migrator().AutoMigrate(&model.Tag{}, &model.Article{}, &model.Project{}, &model.Profile{}); err != nil {
+			return err
+		}
+
+		// relationships are ignored when migrating, so the join tables are
+		// created by hand.
+		return execSlice(tx,
+			`CREATE TABLE IF NOT EXISTS article_tags (
+				article_id uuid NOT NULL,
+				tag_id uuid NOT NULL,
+				PRIMARY KEY (article_id, tag_id)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_article_tags_tag_id ON article_tags (tag_id)`,
+			`CREATE TABLE IF NOT EXISTS project_tags (
+				project_id uuid NOT NULL,
+				tag_id uuid NOT NULL,
+				PRIMARY KEY (project_id, tag_id)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_project_tags_tag_id ON project_tags (tag_id)`,
+		)
+	})
+}
+
+func downCreateContentTables(ctx context.Context, tx *sql.DB) error {
+	gdb, err := initDB(tx)
+	if err != nil {
+		return err
+	}
+
+	return gdb.Transaction(func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable("project_tags", "article_tags", &model.Profile{}, &model.Project{}, &model.Article{}, &model.Tag{})
+	})
+}
@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+	"gorm.io/gorm"
+
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+)
+
+func init() {
+	goose.AddMigrationNoTxContext(upCreateOutboxEventsTable, downCreateOutboxEventsTable)
+}
+
+func upCreateOutboxEventsTable(ctx context.Context, tx *sql.DB) error {
+	gdb, err := initDB(tx)
+	if err != nil {
+		return err
+	}
+
+	return gdb.Transaction(func(tx *gorm.DB) error {
+		return tx.Migrator().AutoMigrate(&model.OutboxEvent{})
+	})
+}
+
+func downCreateOutboxEventsTable(ctx context.Context, tx *sql.DB) error {
+	gdb, err := initDB(tx)
+	if err != nil {
+		return err
+	}
+
+	return gdb.Transaction(func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&model.OutboxEvent{})
+	})
+}
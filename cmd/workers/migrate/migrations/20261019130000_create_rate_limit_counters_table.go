@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+	"gorm.io/gorm"
+
+	"github.com/cirius-go/portfolio-server/pkg/ratelimit"
+)
+
+func init() {
+	goose.AddMigrationNoTxContext(upCreateRateLimitCountersTable, downCreateRateLimitCountersTable)
+}
+
+func upCreateRateLimitCountersTable(ctx context.Context, tx *sql.DB) error {
+	gdb, err := initDB(tx)
+	if err != nil {
+		return err
+	}
+
+	return gdb.Transaction(func(tx *gorm.DB) error {
+		return tx.Migrator().AutoMigrate(&ratelimit.Counter{})
+	})
+}
+
+func downCreateRateLimitCountersTable(ctx context.Context, tx *sql.DB) error {
+	gdb, err := initDB(tx)
+	if err != nil {
+		return err
+	}
+
+	return gdb.Transaction(func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&ratelimit.Counter{})
+	})
+}
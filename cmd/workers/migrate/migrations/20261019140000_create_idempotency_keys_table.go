@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+	"gorm.io/gorm"
+
+	"github.com/cirius-go/portfolio-server/pkg/idempotency"
+)
+
+func init() {
+	goose.AddMigrationNoTxContext(upCreateIdempotencyKeysTable, downCreateIdempotencyKeysTable)
+}
+
+func upCreateIdempotencyKeysTable(ctx context.Context, tx *sql.DB) error {
+	gdb, err := initDB(tx)
+	if err != nil {
+		return err
+	}
+
+	return gdb.Transaction(func(tx *gorm.DB) error {
+		return tx.Migrator().AutoMigrate(&idempotency.Record{})
+	})
+}
+
+func downCreateIdempotencyKeysTable(ctx context.Context, tx *sql.DB) error {
+	gdb, err := initDB(tx)
+	if err != nil {
+		return err
+	}
+
+	return gdb.Transaction(func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&idempotency.Record{})
+	})
+}
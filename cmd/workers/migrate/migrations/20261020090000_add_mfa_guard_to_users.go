@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+	"gorm.io/gorm"
+
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+)
+
+func init() {
+	goose.AddMigrationNoTxContext(upAddMFAGuardToUsers, downAddMFAGuardToUsers)
+}
+
+func upAddMFAGuardToUsers(ctx context.Context, tx *sql.DB) error {
+	gdb, err := initDB(tx)
+	if err != nil {
+		return err
+	}
+
+	return gdb.Transaction(func(tx *gorm.DB) error {
+		return tx.Migrator().AutoMigrate(&model.User{})
+	})
+}
+
+func downAddMFAGuardToUsers(ctx context.Context, tx *sql.DB) error {
+	gdb, err := initDB(tx)
+	if err != nil {
+		return err
+	}
+
+	return gdb.Transaction(func(tx *gorm.DB) error {
+		for _, col := range []string{"MFALastStep", "MFAFailedAttempts", "MFALockedUntil"} {
+			if err := tx.Migrator().DropColumn(&model.User{}, col); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
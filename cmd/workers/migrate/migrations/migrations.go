@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/database"
+)
+
+// TableName is the table recording the applied migrations.
+const TableName = "migrations"
+
+// FS contains the migration files. The Go migrations register themselves on
+// init and are matched to their file by version.
+//
+//go:embed *.go
+var FS embed.FS
+
+// NewProvider returns the goose provider of the migrations on db.
+func NewProvider(db *sql.DB, opts ...goose.ProviderOption) (*goose.Provider, error) {
+	store, err := database.NewStore(database.DialectPostgres, TableName)
+	if err != nil {
+		return nil, err
+	}
+	return goose.NewProvider("", db, FS, append([]goose.ProviderOption{goose.WithStore(store)}, opts...)...)
+}
+
+// VersionCheck returns a check failing while the database is behind the
+// latest migration of the provider. A database ahead of it passes, so that
+// the previous release keeps serving while a newer one is rolled out.
+func VersionCheck(p *goose.Provider) func(ctx context.Context) error {
+	var latest int64
+	for _, src := range p.ListSources() {
+		latest = max(latest, src.Version)
+	}
+	return func(ctx context.Context) error {
+		v, err := p.GetDBVersion(ctx)
+		if err != nil {
+			return err
+		}
+		if v < latest {
+			return fmt.Errorf("database is at version %d, want at least %d", v, latest)
+		}
+		return nil
+	}
+}
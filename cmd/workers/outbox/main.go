@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	awscfg "github.com/aws/aws-sdk-go-v2/config"
+
+	"github.com/cirius-go/portfolio-server/internal/api/apipublic"
+	"github.com/cirius-go/portfolio-server/internal/config"
+	"github.com/cirius-go/portfolio-server/internal/outbox"
+	"github.com/cirius-go/portfolio-server/internal/repo"
+	"github.com/cirius-go/portfolio-server/internal/service"
+	"github.com/cirius-go/portfolio-server/internal/uow"
+	"github.com/cirius-go/portfolio-server/pkg/awsutil"
+	"github.com/cirius-go/portfolio-server/pkg/db"
+	"github.com/cirius-go/portfolio-server/pkg/lifecycle"
+	"github.com/cirius-go/portfolio-server/pkg/logging"
+	"github.com/cirius-go/portfolio-server/pkg/metrics"
+	"github.com/cirius-go/portfolio-server/pkg/tracing"
+)
+
+var cfgFile = flag.String("cfg", ".env", "the path to the config file")
+
+func main() {
+	flag.Parse()
+
+	cfg, err := config.Load(context.Background(), *cfgFile)
+	panicIf(err)
+	logging.Setup(cfg.Log)
+
+	lc := lifecycle.New(cfg.ShutdownTimeout).WithCloseTimeout(cfg.ShutdownCloseTimeout)
+	defer lc.Shutdown()
+
+	tp, err := tracing.Setup(context.Background(), cfg.Tracing)
+	panicIf(err)
+	lc.Add("tracer", tp.Shutdown)
+
+	pg, err := db.NewPostgres(cfg.PGDB)
+	panicIf(err)
+	lc.AddCloser("database", pg.Close)
+	repo.ConfigureLogger(pg.Logger)
+
+	reg := metrics.NewRegistry(!config.IsInAWSLambda())
+	panicIf(pg.RegisterMetrics(reg))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	awsCfg, err := awscfg.LoadDefaultConfig(ctx)
+	panicIf(err)
+
+	if config.IsLocal() {
+		awsCfg, err = config.GetAWSConfigWithExecRole(ctx)
+		panicIf(err)
+	}
+
+	unitOfWork := uow.New(pg.DB).WithObserver(uow.NewMetricsObserver(reg))
+	d := outbox.NewDispatcher(unitOfWork, cfg.Outbox).WithMetrics(reg)
+
+	// invalidate the cdn copies of the public api on publish, there is nothing
+	// to deliver without cdn.
+	invalidate := func(ctx context.Context, e service.PublishEvent) error { return nil }
+	if id := cfg.PublicCDN.DistributionID; id != "" {
+		invalidate = apipublic.InvalidateCDN(awsutil.NewCloudFront(awsCfg, id))
+	}
+	d.Handle(service.TopicPublished, outbox.HandleJSON(invalidate))
+
+	if config.IsInAWSLambda() {
+		// the worker is invoked on a schedule, it drains the due events.
+		emf := metrics.NewEMF(reg, cfg.Metrics.Namespace, os.Stdout)
+		lambda.StartWithOptions(func(ctx context.Context) error {
+			defer func() {
+				if err := emf.Flush(); err != nil {
+					slog.Error("failed to write metrics", "error", err)
+				}
+				if err := tp.ForceFlush(ctx); err != nil {
+					slog.Error("failed to export spans", "error", err)
+				}
+			}()
+			for {
+				n, err := d.DispatchOnce(ctx)
+				if err != nil || n < cfg.Outbox.BatchSize {
+					return err
+				}
+			}
+		}, lambda.WithEnableSIGTERM(func() {
+			lc.Shutdown()
+		}))
+		return
+	}
+
+	if port := cfg.Metrics.Port; port != 0 {
+		addr := fmt.Sprintf("%s:%d", cfg.HTTPServer.Host, port)
+		lc.Go("metrics", func(ctx context.Context) error {
+			return metrics.Serve(ctx, addr, reg, cfg.Metrics.Token)
+		})
+	}
+
+	slog.Info("dispatching outbox events")
+	lc.Go("dispatcher", d.Run)
+	panicIf(lc.Run())
+}
+
+func panicIf(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
@@ -17,6 +17,10 @@ type API struct{}
 // APIOptions contains the options for the handler function.
 type APIOptions struct {
 	successStatusCode int
+	cache             *CachePolicy
+	rateLimit         *RateLimitPolicy
+	accountRateLimit  *RateLimitPolicy
+	idempotent        bool
 }
 
 // O creates a new API options.
@@ -30,6 +34,36 @@ func (o *APIOptions) SuccessStatus(code int) *APIOptions {
 	return o
 }
 
+// Cache sets the caching policy of the success response. The response gets
+// ETag, Last-Modified and Cache-Control headers and conditional requests are
+// answered with 304 Not Modified.
+func (o *APIOptions) Cache(p CachePolicy) *APIOptions {
+	o.cache = &p
+	return o
+}
+
+// RateLimit sets the rate limit policy of the route, on top of the one of its
+// group if any.
+func (o *APIOptions) RateLimit(p RateLimitPolicy) *APIOptions {
+	o.rateLimit = &p
+	return o
+}
+
+// AccountRateLimit sets the rate limit policy of the accounts targeted by the
+// requests of the route, see AccountRequest. It is counted once the request
+// is bound, on top of the one of RateLimit.
+func (o *APIOptions) AccountRateLimit(p RateLimitPolicy) *APIOptions {
+	o.accountRateLimit = &p
+	return o
+}
+
+// Idempotent makes the route honour the Idempotency-Key header: a request
+// retried with the same key is answered with the recorded response.
+func (o *APIOptions) Idempotent() *APIOptions {
+	o.idempotent = true
+	return o
+}
+
 // ServiceHandlerFunc represents the service handler function with request and response models.
 type ServiceHandlerFunc[Rq, Rp any] func(context.Context, *Rq) (*Rp, error)
 
@@ -41,7 +75,7 @@ type ServiceNoResHandlerFunc[Rq any] func(context.Context, *Rq) error
 
 // MakeJSONHandler trigger resolver with echo context.
 func MakeJSONHandler[Rq, Rp any](c echo.Context, fn ServiceHandlerFunc[Rq, Rp], opts ...*APIOptions) error {
-	return JSONHandlerFunc(fn)(c)
+	return JSONHandlerFunc(fn, opts...)(c)
 }
 
 // JSONHandlerFunc wrap the service method with json parsers.
@@ -52,8 +86,6 @@ func JSONHandlerFunc[Rq, Rp any](fn ServiceHandlerFunc[Rq, Rp], opts ...*APIOpti
 
 			rq  = new(Rq)
 			ctx = c.Request().Context()
-
-			err error
 		)
 
 		if len(opts) > 0 {
@@ -62,17 +94,29 @@ func JSONHandlerFunc[Rq, Rp any](fn ServiceHandlerFunc[Rq, Rp], opts ...*APIOpti
 			opt = O()
 		}
 
-		if err := c.Bind(rq); err != nil {
+		if err := rateLimit(c, opt.rateLimit); err != nil {
 			return err
 		}
 
-		res, err := fn(ctx, rq)
-		if err != nil {
-			return err
-		}
+		return idempotent(c, opt.idempotent, func() error {
+			if err := c.Bind(rq); err != nil {
+				return err
+			}
+			if err := rateLimitAccount(c, opt.accountRateLimit, rq); err != nil {
+				return err
+			}
+
+			res, err := fn(ctx, rq)
+			if err != nil {
+				return err
+			}
 
-		successStatus := util.IfZero(http.StatusOK, opt.successStatusCode)
-		return c.JSON(successStatus, res)
+			successStatus := util.IfZero(http.StatusOK, opt.successStatusCode)
+			if opt.cache != nil {
+				return writeCachedJSON(c, successStatus, res, opt.cache)
+			}
+			return c.JSON(successStatus, res)
+		})
 	}
 }
 
@@ -83,8 +127,6 @@ func NoReqHandlerFunc[Rp any](fn ServiceNoReqHandlerFunc[Rp], opts ...*APIOption
 			opt *APIOptions
 
 			ctx = c.Request().Context()
-
-			err error
 		)
 
 		if len(opts) > 0 {
@@ -93,13 +135,22 @@ func NoReqHandlerFunc[Rp any](fn ServiceNoReqHandlerFunc[Rp], opts ...*APIOption
 			opt = O()
 		}
 
-		res, err := fn(ctx)
-		if err != nil {
+		if err := rateLimit(c, opt.rateLimit); err != nil {
 			return err
 		}
 
-		successStatus := util.IfZero(http.StatusOK, opt.successStatusCode)
-		return c.JSON(successStatus, res)
+		return idempotent(c, opt.idempotent, func() error {
+			res, err := fn(ctx)
+			if err != nil {
+				return err
+			}
+
+			successStatus := util.IfZero(http.StatusOK, opt.successStatusCode)
+			if opt.cache != nil {
+				return writeCachedJSON(c, successStatus, res, opt.cache)
+			}
+			return c.JSON(successStatus, res)
+		})
 	}
 }
 
@@ -121,16 +172,25 @@ func NoResHandlerFunc[Rq any](fn ServiceNoResHandlerFunc[Rq], opts ...*APIOption
 			opt = O()
 		}
 
-		if err := c.Bind(rq); err != nil {
+		if err := rateLimit(c, opt.rateLimit); err != nil {
 			return err
 		}
 
-		if err = fn(ctx, rq); err != nil {
-			return err
-		}
+		return idempotent(c, opt.idempotent, func() error {
+			if err := c.Bind(rq); err != nil {
+				return err
+			}
+			if err := rateLimitAccount(c, opt.accountRateLimit, rq); err != nil {
+				return err
+			}
+
+			if err = fn(ctx, rq); err != nil {
+				return err
+			}
 
-		successStatus := util.IfZero(http.StatusOK, opt.successStatusCode)
-		return c.NoContent(successStatus)
+			successStatus := util.IfZero(http.StatusOK, opt.successStatusCode)
+			return c.NoContent(successStatus)
+		})
 	}
 }
 
@@ -0,0 +1,125 @@
+package apicms
+
+import (
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/cirius-go/portfolio-server/internal/api"
+)
+
+// Admin API controller.
+type Admin struct {
+	svc AdminService
+}
+
+// NewAdmin creates a new Admin controller.
+func NewAdmin(svc AdminService) *Admin {
+	return &Admin{
+		svc: svc,
+	}
+}
+
+// RegisterHTTP register HTTP handlers based on actions for the service.
+func (s *Admin) RegisterHTTP(r *echo.Group) {
+	//+codegen=BindingApiHandler
+	r.GET("/_admin/runtime", s.Runtime)
+	r.GET("/_admin/config", s.Config)
+	r.GET("/_admin/settings", s.Settings)
+	r.PATCH("/_admin/settings", s.UpdateSettings)
+
+	// the pprof index links to the profiles relatively to its own path.
+	pp := r.Group("/_admin/pprof", s.authorizeProfiling)
+	pp.GET("/", echo.WrapHandler(http.HandlerFunc(pprof.Index)))
+	pp.GET("/cmdline", echo.WrapHandler(http.HandlerFunc(pprof.Cmdline)))
+	pp.GET("/profile", echo.WrapHandler(http.HandlerFunc(pprof.Profile)))
+	pp.GET("/symbol", echo.WrapHandler(http.HandlerFunc(pprof.Symbol)))
+	pp.POST("/symbol", echo.WrapHandler(http.HandlerFunc(pprof.Symbol)))
+	pp.GET("/trace", echo.WrapHandler(http.HandlerFunc(pprof.Trace)))
+	pp.GET("/:name", func(c echo.Context) error {
+		pprof.Handler(c.Param("name")).ServeHTTP(c.Response(), c.Request())
+		return nil
+	})
+}
+
+// authorizeProfiling restricts the pprof handlers to the sessions allowed to
+// profile the server.
+func (s *Admin) authorizeProfiling(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if err := s.svc.AuthorizeProfiling(c.Request().Context()); err != nil {
+			return err
+		}
+		return next(c)
+	}
+}
+
+// Runtime
+//
+//	@id cms-admin-runtime
+//	@Summary Runtime
+//	@Description Get the runtime statistics and the build of the server.
+//	@Tags cms/admin
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Success 200 {object} dtocms.RuntimeAdminRes "JSON Response Payload"
+//	@Failure 403 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /cms/_admin/runtime [GET]
+func (s *Admin) Runtime(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.Runtime)
+}
+
+// Config
+//
+//	@id cms-admin-config
+//	@Summary Config
+//	@Description Get the current config of the server by environment variable, with the secrets redacted.
+//	@Tags cms/admin
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Success 200 {object} dtocms.ConfigAdminRes "JSON Response Payload"
+//	@Failure 403 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /cms/_admin/config [GET]
+func (s *Admin) Config(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.Config)
+}
+
+// Settings
+//
+//	@id cms-admin-settings
+//	@Summary Settings
+//	@Description Get the log level and the request debug flag of the server.
+//	@Tags cms/admin
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Success 200 {object} dtocms.SettingsAdminRes "JSON Response Payload"
+//	@Failure 403 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /cms/_admin/settings [GET]
+func (s *Admin) Settings(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.Settings)
+}
+
+// UpdateSettings
+//
+//	@id cms-admin-update-settings
+//	@Summary UpdateSettings
+//	@Description Change the log level or turn on the logging of the queries of every request, until the next restart.
+//	@Tags cms/admin
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Param Payload body dtocms.UpdateSettingsAdminReq true "JSON Request Payload"
+//	@Success 200 {object} dtocms.UpdateSettingsAdminRes "JSON Response Payload"
+//	@Failure 400 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 403 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /cms/_admin/settings [PATCH]
+func (s *Admin) UpdateSettings(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.UpdateSettings)
+}
@@ -1,6 +1,10 @@
 package apicms
 
-import "github.com/labstack/echo/v4"
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/cirius-go/portfolio-server/internal/api"
+)
 
 // Article API controller.
 type Article struct {
@@ -17,4 +21,50 @@ func NewArticle(svc ArticleService) *Article {
 // RegisterHTTP register HTTP handlers based on actions for the service.
 func (s *Article) RegisterHTTP(r *echo.Group) {
 	//+codegen=BindingApiHandler
+	r.POST("/articles/:id/publish", s.Publish)
+	r.POST("/articles/:id/unpublish", s.Unpublish)
+}
+
+// Publish
+//
+//	@id cms-articles-publish
+//	@Summary Publish
+//	@Description Publish the article on the public site. The first publish date is kept when it is published again.
+//	@Tags cms/articles
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Param ID path string true "ID"
+//	@Param Idempotency-Key header string false "Key making retries of the request safe"
+//	@Success 200 {object} dtocms.PublishArticleRes "JSON Response Payload"
+//	@Failure 400 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 403 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 404 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 409 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /cms/articles/{id}/publish [POST]
+func (s *Article) Publish(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.Publish, api.O().Idempotent())
+}
+
+// Unpublish
+//
+//	@id cms-articles-unpublish
+//	@Summary Unpublish
+//	@Description Take the article down from the public site.
+//	@Tags cms/articles
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Param ID path string true "ID"
+//	@Param Idempotency-Key header string false "Key making retries of the request safe"
+//	@Success 200 {object} dtocms.UnpublishArticleRes "JSON Response Payload"
+//	@Failure 400 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 403 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 404 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 409 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /cms/articles/{id}/unpublish [POST]
+func (s *Article) Unpublish(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.Unpublish, api.O().Idempotent())
 }
@@ -0,0 +1,192 @@
+package apicms
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/cirius-go/portfolio-server/internal/api"
+)
+
+// AuthPublicRoutes are the auth routes reachable without a session.
+var AuthPublicRoutes = []string{
+	"/cms/auth/login",
+	"/cms/auth/refresh",
+	"/cms/auth/challenge/*",
+	"/cms/auth/invitations/accept",
+	"/cms/auth/password/*",
+}
+
+// Auth API controller.
+type Auth struct {
+	svc AuthService
+}
+
+// NewAuth creates a new Auth controller.
+func NewAuth(svc AuthService) *Auth {
+	return &Auth{
+		svc: svc,
+	}
+}
+
+// RegisterHTTP register HTTP handlers based on actions for the service.
+func (s *Auth) RegisterHTTP(r *echo.Group) {
+	//+codegen=BindingApiHandler
+	r.POST("/auth/login", s.Login)
+	r.POST("/auth/refresh", s.Refresh)
+	r.POST("/auth/challenge/enroll", s.EnrollChallenge)
+	r.POST("/auth/challenge/verify", s.VerifyChallenge)
+	r.POST("/auth/mfa", s.EnrollMFA)
+	r.POST("/auth/mfa/activate", s.ActivateMFA)
+	r.POST("/auth/mfa/recovery-codes", s.RegenerateRecoveryCodes)
+	r.DELETE("/auth/mfa", s.DisableMFA)
+}
+
+// Login
+//
+//	@id cms-auth-login
+//	@Summary Login
+//	@Description Login with email and password. If two-factor authentication is enabled or required, a challenge token is returned instead of the session.
+//	@Tags cms/auth
+//	@Accept json
+//	@Produce json
+//	@Param Payload body dtocms.LoginAuthReq true "JSON Request Payload"
+//	@Success 200 {object} dtocms.LoginAuthRes "JSON Response Payload"
+//	@Failure 400 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 401 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 429 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /cms/auth/login [POST]
+func (s *Auth) Login(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.Login, api.O().RateLimit(LoginRateLimit).AccountRateLimit(AccountLoginRateLimit))
+}
+
+// Refresh
+//
+//	@id cms-auth-refresh
+//	@Summary Refresh
+//	@Description Issue a new session from a refresh token.
+//	@Tags cms/auth
+//	@Accept json
+//	@Produce json
+//	@Param Payload body dtocms.RefreshAuthReq true "JSON Request Payload"
+//	@Success 200 {object} dtocms.RefreshAuthRes "JSON Response Payload"
+//	@Failure 400 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 401 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /cms/auth/refresh [POST]
+func (s *Auth) Refresh(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.Refresh)
+}
+
+// EnrollChallenge
+//
+//	@id cms-auth-enroll-challenge
+//	@Summary EnrollChallenge
+//	@Description Start the mandatory two-factor enrollment with the challenge token returned by login.
+//	@Tags cms/auth
+//	@Accept json
+//	@Produce json
+//	@Param Payload body dtocms.EnrollChallengeAuthReq true "JSON Request Payload"
+//	@Success 200 {object} dtocms.EnrollChallengeAuthRes "JSON Response Payload"
+//	@Failure 400 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 401 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 429 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /cms/auth/challenge/enroll [POST]
+func (s *Auth) EnrollChallenge(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.EnrollChallenge, api.O().RateLimit(LoginRateLimit).AccountRateLimit(AccountLoginRateLimit))
+}
+
+// VerifyChallenge
+//
+//	@id cms-auth-verify-challenge
+//	@Summary VerifyChallenge
+//	@Description Exchange the challenge token and a TOTP or recovery code for the session.
+//	@Tags cms/auth
+//	@Accept json
+//	@Produce json
+//	@Param Payload body dtocms.VerifyChallengeAuthReq true "JSON Request Payload"
+//	@Success 200 {object} dtocms.VerifyChallengeAuthRes "JSON Response Payload"
+//	@Failure 400 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 401 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 429 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /cms/auth/challenge/verify [POST]
+func (s *Auth) VerifyChallenge(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.VerifyChallenge, api.O().RateLimit(LoginRateLimit).AccountRateLimit(AccountLoginRateLimit))
+}
+
+// EnrollMFA
+//
+//	@id cms-auth-enroll-mfa
+//	@Summary EnrollMFA
+//	@Description Generate a new TOTP secret for the current user.
+//	@Tags cms/auth
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Success 200 {object} dtocms.EnrollMFAAuthRes "JSON Response Payload"
+//	@Failure 400 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 409 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /cms/auth/mfa [POST]
+func (s *Auth) EnrollMFA(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.EnrollMFA)
+}
+
+// ActivateMFA
+//
+//	@id cms-auth-activate-mfa
+//	@Summary ActivateMFA
+//	@Description Enable two-factor authentication by verifying a code of the enrolled secret.
+//	@Tags cms/auth
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Param Payload body dtocms.ActivateMFAAuthReq true "JSON Request Payload"
+//	@Success 200 {object} dtocms.ActivateMFAAuthRes "JSON Response Payload"
+//	@Failure 400 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 401 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /cms/auth/mfa/activate [POST]
+func (s *Auth) ActivateMFA(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.ActivateMFA)
+}
+
+// RegenerateRecoveryCodes
+//
+//	@id cms-auth-regenerate-recovery-codes
+//	@Summary RegenerateRecoveryCodes
+//	@Description Replace the recovery codes of the current user.
+//	@Tags cms/auth
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Param Payload body dtocms.RegenerateRecoveryCodesAuthReq true "JSON Request Payload"
+//	@Success 200 {object} dtocms.RegenerateRecoveryCodesAuthRes "JSON Response Payload"
+//	@Failure 400 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 401 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /cms/auth/mfa/recovery-codes [POST]
+func (s *Auth) RegenerateRecoveryCodes(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.RegenerateRecoveryCodes)
+}
+
+// DisableMFA
+//
+//	@id cms-auth-disable-mfa
+//	@Summary DisableMFA
+//	@Description Disable two-factor authentication of the current user.
+//	@Tags cms/auth
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Param Payload body dtocms.DisableMFAAuthReq true "JSON Request Payload"
+//	@Success 200 {object} dtocms.DisableMFAAuthRes "JSON Response Payload"
+//	@Failure 400 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 401 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 403 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /cms/auth/mfa [DELETE]
+func (s *Auth) DisableMFA(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.DisableMFA)
+}
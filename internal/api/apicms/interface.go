@@ -1,16 +1,67 @@
 package apicms
 
+import (
+	"context"
+
+	"github.com/cirius-go/portfolio-server/internal/dto/dtocms"
+)
+
 // UserService represents the service handler for User.
 type UserService interface {
 	//+codegen=UserServiceHandler
+	RequireMFA(ctx context.Context, req *dtocms.RequireMFAUserReq) (res *dtocms.RequireMFAUserRes, err error)
+	ResetMFA(ctx context.Context, req *dtocms.ResetMFAUserReq) (res *dtocms.ResetMFAUserRes, err error)
 }
 
 // ProjectService represents the service handler for Project.
 type ProjectService interface {
 	//+codegen=ProjectServiceHandler
+	Publish(ctx context.Context, req *dtocms.PublishProjectReq) (res *dtocms.PublishProjectRes, err error)
+	Unpublish(ctx context.Context, req *dtocms.UnpublishProjectReq) (res *dtocms.UnpublishProjectRes, err error)
 }
 
 // ArticleService represents the service handler for Article.
 type ArticleService interface {
 	//+codegen=ArticleServiceHandler
+	Publish(ctx context.Context, req *dtocms.PublishArticleReq) (res *dtocms.PublishArticleRes, err error)
+	Unpublish(ctx context.Context, req *dtocms.UnpublishArticleReq) (res *dtocms.UnpublishArticleRes, err error)
+}
+
+// AuthService represents the service handler for Auth.
+type AuthService interface {
+	//+codegen=AuthServiceHandler
+	Login(ctx context.Context, req *dtocms.LoginAuthReq) (res *dtocms.LoginAuthRes, err error)
+	Refresh(ctx context.Context, req *dtocms.RefreshAuthReq) (res *dtocms.RefreshAuthRes, err error)
+	EnrollChallenge(ctx context.Context, req *dtocms.EnrollChallengeAuthReq) (res *dtocms.EnrollChallengeAuthRes, err error)
+	VerifyChallenge(ctx context.Context, req *dtocms.VerifyChallengeAuthReq) (res *dtocms.VerifyChallengeAuthRes, err error)
+	EnrollMFA(ctx context.Context, req *dtocms.EnrollMFAAuthReq) (res *dtocms.EnrollMFAAuthRes, err error)
+	ActivateMFA(ctx context.Context, req *dtocms.ActivateMFAAuthReq) (res *dtocms.ActivateMFAAuthRes, err error)
+	RegenerateRecoveryCodes(ctx context.Context, req *dtocms.RegenerateRecoveryCodesAuthReq) (res *dtocms.RegenerateRecoveryCodesAuthRes, err error)
+	DisableMFA(ctx context.Context, req *dtocms.DisableMFAAuthReq) (res *dtocms.DisableMFAAuthRes, err error)
+}
+
+// InvitationService represents the service handler for Invitation.
+type InvitationService interface {
+	//+codegen=InvitationServiceHandler
+	Create(ctx context.Context, req *dtocms.CreateInvitationReq) (res *dtocms.CreateInvitationRes, err error)
+	Accept(ctx context.Context, req *dtocms.AcceptInvitationReq) (res *dtocms.AcceptInvitationRes, err error)
+}
+
+// PasswordService represents the service handler for Password.
+type PasswordService interface {
+	//+codegen=PasswordServiceHandler
+	Forgot(ctx context.Context, req *dtocms.ForgotPasswordReq) (res *dtocms.ForgotPasswordRes, err error)
+	Reset(ctx context.Context, req *dtocms.ResetPasswordReq) (res *dtocms.ResetPasswordRes, err error)
+}
+
+// AdminService represents the service handler for Admin.
+type AdminService interface {
+	//+codegen=AdminServiceHandler
+	Runtime(ctx context.Context, req *dtocms.RuntimeAdminReq) (res *dtocms.RuntimeAdminRes, err error)
+	Config(ctx context.Context, req *dtocms.ConfigAdminReq) (res *dtocms.ConfigAdminRes, err error)
+	Settings(ctx context.Context, req *dtocms.SettingsAdminReq) (res *dtocms.SettingsAdminRes, err error)
+	UpdateSettings(ctx context.Context, req *dtocms.UpdateSettingsAdminReq) (res *dtocms.UpdateSettingsAdminRes, err error)
+	// AuthorizeProfiling returns an error unless the session may profile the
+	// server.
+	AuthorizeProfiling(ctx context.Context) error
 }
@@ -0,0 +1,66 @@
+package apicms
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/cirius-go/portfolio-server/internal/api"
+)
+
+// Invitation API controller.
+type Invitation struct {
+	svc InvitationService
+}
+
+// NewInvitation creates a new Invitation controller.
+func NewInvitation(svc InvitationService) *Invitation {
+	return &Invitation{
+		svc: svc,
+	}
+}
+
+// RegisterHTTP register HTTP handlers based on actions for the service.
+func (s *Invitation) RegisterHTTP(r *echo.Group) {
+	//+codegen=BindingApiHandler
+	r.POST("/invitations", s.Create)
+	r.POST("/auth/invitations/accept", s.Accept)
+}
+
+// Create
+//
+//	@id cms-invitations-create
+//	@Summary Create
+//	@Description Invite a new user by email. Pending invitations of the same email are replaced.
+//	@Tags cms/invitations
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Param Idempotency-Key header string false "Key making retries of the request safe"
+//	@Param Payload body dtocms.CreateInvitationReq true "JSON Request Payload"
+//	@Success 200 {object} dtocms.CreateInvitationRes "JSON Response Payload"
+//	@Failure 400 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 403 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 409 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /cms/invitations [POST]
+func (s *Invitation) Create(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.Create, api.O().Idempotent())
+}
+
+// Accept
+//
+//	@id cms-invitations-accept
+//	@Summary Accept
+//	@Description Accept an invitation and create the user with the chosen password. An invitation can only be accepted once.
+//	@Tags cms/invitations
+//	@Accept json
+//	@Produce json
+//	@Param Payload body dtocms.AcceptInvitationReq true "JSON Request Payload"
+//	@Success 200 {object} dtocms.AcceptInvitationRes "JSON Response Payload"
+//	@Failure 400 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 409 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 429 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /cms/auth/invitations/accept [POST]
+func (s *Invitation) Accept(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.Accept, api.O().RateLimit(TokenRateLimit).AccountRateLimit(AccountTokenRateLimit))
+}
@@ -0,0 +1,62 @@
+package apicms
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/cirius-go/portfolio-server/internal/api"
+)
+
+// Password API controller.
+type Password struct {
+	svc PasswordService
+}
+
+// NewPassword creates a new Password controller.
+func NewPassword(svc PasswordService) *Password {
+	return &Password{
+		svc: svc,
+	}
+}
+
+// RegisterHTTP register HTTP handlers based on actions for the service.
+func (s *Password) RegisterHTTP(r *echo.Group) {
+	//+codegen=BindingApiHandler
+	r.POST("/auth/password/forgot", s.Forgot)
+	r.POST("/auth/password/reset", s.Reset)
+}
+
+// Forgot
+//
+//	@id cms-password-forgot
+//	@Summary Forgot
+//	@Description Email a password reset link. The response is the same whether or not the email belongs to a user.
+//	@Tags cms/password
+//	@Accept json
+//	@Produce json
+//	@Param Payload body dtocms.ForgotPasswordReq true "JSON Request Payload"
+//	@Success 200 {object} dtocms.ForgotPasswordRes "JSON Response Payload"
+//	@Failure 400 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 429 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /cms/auth/password/forgot [POST]
+func (s *Password) Forgot(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.Forgot, api.O().RateLimit(TokenRateLimit).AccountRateLimit(AccountTokenRateLimit))
+}
+
+// Reset
+//
+//	@id cms-password-reset
+//	@Summary Reset
+//	@Description Set a new password with the token of a reset link. A token can only be used once.
+//	@Tags cms/password
+//	@Accept json
+//	@Produce json
+//	@Param Payload body dtocms.ResetPasswordReq true "JSON Request Payload"
+//	@Success 200 {object} dtocms.ResetPasswordRes "JSON Response Payload"
+//	@Failure 400 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 429 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /cms/auth/password/reset [POST]
+func (s *Password) Reset(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.Reset, api.O().RateLimit(TokenRateLimit).AccountRateLimit(AccountTokenRateLimit))
+}
@@ -1,6 +1,10 @@
 package apicms
 
-import "github.com/labstack/echo/v4"
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/cirius-go/portfolio-server/internal/api"
+)
 
 // Project API controller.
 type Project struct {
@@ -17,4 +21,50 @@ func NewProject(svc ProjectService) *Project {
 // RegisterHTTP register HTTP handlers based on actions for the service.
 func (s *Project) RegisterHTTP(r *echo.Group) {
 	//+codegen=BindingApiHandler
+	r.POST("/projects/:id/publish", s.Publish)
+	r.POST("/projects/:id/unpublish", s.Unpublish)
+}
+
+// Publish
+//
+//	@id cms-projects-publish
+//	@Summary Publish
+//	@Description Publish the project on the public site. The first publish date is kept when it is published again.
+//	@Tags cms/projects
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Param ID path string true "ID"
+//	@Param Idempotency-Key header string false "Key making retries of the request safe"
+//	@Success 200 {object} dtocms.PublishProjectRes "JSON Response Payload"
+//	@Failure 400 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 403 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 404 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 409 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /cms/projects/{id}/publish [POST]
+func (s *Project) Publish(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.Publish, api.O().Idempotent())
+}
+
+// Unpublish
+//
+//	@id cms-projects-unpublish
+//	@Summary Unpublish
+//	@Description Take the project down from the public site.
+//	@Tags cms/projects
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Param ID path string true "ID"
+//	@Param Idempotency-Key header string false "Key making retries of the request safe"
+//	@Success 200 {object} dtocms.UnpublishProjectRes "JSON Response Payload"
+//	@Failure 400 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 403 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 404 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 409 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /cms/projects/{id}/unpublish [POST]
+func (s *Project) Unpublish(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.Unpublish, api.O().Idempotent())
 }
@@ -0,0 +1,34 @@
+package apicms
+
+import (
+	"time"
+
+	"github.com/cirius-go/portfolio-server/internal/api"
+)
+
+// Rate limit policies of the cms routes reachable without a session, they
+// slow down the guessing of passwords, codes and tokens. The client IP and
+// the targeted account are both limited, so that neither many accounts from
+// one client nor one account from many clients can be guessed quickly.
+var (
+	LoginRateLimit = api.RateLimitPolicy{
+		Name:   "cms-login",
+		Limit:  10,
+		Window: time.Minute,
+	}
+	TokenRateLimit = api.RateLimitPolicy{
+		Name:   "cms-token",
+		Limit:  5,
+		Window: 15 * time.Minute,
+	}
+	AccountLoginRateLimit = api.RateLimitPolicy{
+		Name:   "cms-login-account",
+		Limit:  10,
+		Window: 15 * time.Minute,
+	}
+	AccountTokenRateLimit = api.RateLimitPolicy{
+		Name:   "cms-token-account",
+		Limit:  5,
+		Window: 15 * time.Minute,
+	}
+)
@@ -1,6 +1,10 @@
 package apicms
 
-import "github.com/labstack/echo/v4"
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/cirius-go/portfolio-server/internal/api"
+)
 
 // User API controller.
 type User struct {
@@ -17,4 +21,49 @@ func NewUser(svc UserService) *User {
 // RegisterHTTP register HTTP handlers based on actions for the service.
 func (s *User) RegisterHTTP(r *echo.Group) {
 	//+codegen=BindingApiHandler
+	r.PATCH("/users/:id/mfa", s.RequireMFA)
+	r.DELETE("/users/:id/mfa", s.ResetMFA)
+}
+
+// RequireMFA
+//
+//	@id cms-users-require-mfa
+//	@Summary RequireMFA
+//	@Description Force-enable (or stop forcing) two-factor authentication for the user. The user must enroll on the next login.
+//	@Tags cms/users
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Param ID path string true "ID"
+//	@Param Idempotency-Key header string false "Key making retries of the request safe"
+//	@Param Payload body dtocms.RequireMFAUserReq true "JSON Request Payload"
+//	@Success 200 {object} dtocms.RequireMFAUserRes "JSON Response Payload"
+//	@Failure 400 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 403 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 409 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /cms/users/{id}/mfa [PATCH]
+func (s *User) RequireMFA(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.RequireMFA, api.O().Idempotent())
+}
+
+// ResetMFA
+//
+//	@id cms-users-reset-mfa
+//	@Summary ResetMFA
+//	@Description Reset two-factor authentication of the user, removing the secret and recovery codes.
+//	@Tags cms/users
+//	@Accept json
+//	@Produce json
+//	@Security BearerAuth
+//	@Param ID path string true "ID"
+//	@Param Idempotency-Key header string false "Key making retries of the request safe"
+//	@Success 200 {object} dtocms.ResetMFAUserRes "JSON Response Payload"
+//	@Failure 400 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 403 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 409 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /cms/users/{id}/mfa [DELETE]
+func (s *User) ResetMFA(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.ResetMFA, api.O().Idempotent())
 }
@@ -0,0 +1,65 @@
+package apipublic
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/cirius-go/portfolio-server/internal/api"
+)
+
+// Article API controller.
+type Article struct {
+	svc ArticleService
+}
+
+// NewArticle creates a new Article controller.
+func NewArticle(svc ArticleService) *Article {
+	return &Article{
+		svc: svc,
+	}
+}
+
+// RegisterHTTP register HTTP handlers based on actions for the service.
+func (s *Article) RegisterHTTP(r *echo.Group) {
+	//+codegen=BindingApiHandler
+	r.GET("/articles", s.List)
+	r.GET("/articles/:slug", s.Get)
+}
+
+// List
+//
+//	@id public-articles-list
+//	@Summary List
+//	@Description List the published articles, latest first.
+//	@Tags public/articles
+//	@Accept json
+//	@Produce json
+//	@Param p query int false "Page"
+//	@Param pp query int false "Per page"
+//	@Param tag query string false "Tag slug"
+//	@Success 200 {object} dtopublic.ListArticleRes "JSON Response Payload"
+//	@Failure 304 "Not Modified"
+//	@Failure 400 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /public/articles [GET]
+func (s *Article) List(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.List, api.O().Cache(ListCache))
+}
+
+// Get
+//
+//	@id public-articles-get
+//	@Summary Get
+//	@Description Get the published article by slug.
+//	@Tags public/articles
+//	@Accept json
+//	@Produce json
+//	@Param slug path string true "Slug"
+//	@Success 200 {object} dtopublic.GetArticleRes "JSON Response Payload"
+//	@Failure 304 "Not Modified"
+//	@Failure 400 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 404 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /public/articles/{slug} [GET]
+func (s *Article) Get(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.Get, api.O().Cache(DetailCache))
+}
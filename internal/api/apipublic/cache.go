@@ -0,0 +1,24 @@
+package apipublic
+
+import (
+	"time"
+
+	"github.com/cirius-go/portfolio-server/internal/api"
+)
+
+// Cache policies of the public routes. The CDN copies are invalidated when
+// content is published, so shared caches keep responses longer than browsers.
+var (
+	ListCache = api.CachePolicy{
+		MaxAge:               time.Minute,
+		SMaxAge:              time.Hour,
+		StaleWhileRevalidate: 24 * time.Hour,
+		StaleIfError:         24 * time.Hour,
+	}
+	DetailCache = api.CachePolicy{
+		MaxAge:               5 * time.Minute,
+		SMaxAge:              24 * time.Hour,
+		StaleWhileRevalidate: 7 * 24 * time.Hour,
+		StaleIfError:         7 * 24 * time.Hour,
+	}
+)
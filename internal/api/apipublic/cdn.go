@@ -0,0 +1,30 @@
+package apipublic
+
+import (
+	"context"
+
+	"github.com/cirius-go/portfolio-server/internal/service"
+)
+
+// CDNInvalidator removes paths from the edge caches of the CDN.
+type CDNInvalidator interface {
+	Invalidate(ctx context.Context, paths ...string) error
+}
+
+// InvalidationPaths returns the public paths whose responses change with the
+// event. Tags are included because the tag listing depends on what is
+// published.
+func InvalidationPaths(e service.PublishEvent) []string {
+	return []string{
+		"/public/" + e.Entity + "*",
+		"/public/tags",
+	}
+}
+
+// InvalidateCDN returns the outbox handler of the publish events, which
+// invalidates the CDN copies of the changed public paths.
+func InvalidateCDN(inv CDNInvalidator) func(ctx context.Context, e service.PublishEvent) error {
+	return func(ctx context.Context, e service.PublishEvent) error {
+		return inv.Invalidate(ctx, InvalidationPaths(e)...)
+	}
+}
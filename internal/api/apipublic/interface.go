@@ -0,0 +1,33 @@
+package apipublic
+
+import (
+	"context"
+
+	"github.com/cirius-go/portfolio-server/internal/dto/dtopublic"
+)
+
+// ArticleService represents the service handler for Article.
+type ArticleService interface {
+	//+codegen=ArticleServiceHandler
+	List(ctx context.Context, req *dtopublic.ListArticleReq) (res *dtopublic.ListArticleRes, err error)
+	Get(ctx context.Context, req *dtopublic.GetArticleReq) (res *dtopublic.GetArticleRes, err error)
+}
+
+// ProjectService represents the service handler for Project.
+type ProjectService interface {
+	//+codegen=ProjectServiceHandler
+	List(ctx context.Context, req *dtopublic.ListProjectReq) (res *dtopublic.ListProjectRes, err error)
+	Get(ctx context.Context, req *dtopublic.GetProjectReq) (res *dtopublic.GetProjectRes, err error)
+}
+
+// TagService represents the service handler for Tag.
+type TagService interface {
+	//+codegen=TagServiceHandler
+	List(ctx context.Context, req *dtopublic.ListTagReq) (res *dtopublic.ListTagRes, err error)
+}
+
+// ProfileService represents the service handler for Profile.
+type ProfileService interface {
+	//+codegen=ProfileServiceHandler
+	Get(ctx context.Context, req *dtopublic.GetProfileReq) (res *dtopublic.GetProfileRes, err error)
+}
@@ -0,0 +1,42 @@
+package apipublic
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/cirius-go/portfolio-server/internal/api"
+)
+
+// Profile API controller.
+type Profile struct {
+	svc ProfileService
+}
+
+// NewProfile creates a new Profile controller.
+func NewProfile(svc ProfileService) *Profile {
+	return &Profile{
+		svc: svc,
+	}
+}
+
+// RegisterHTTP register HTTP handlers based on actions for the service.
+func (s *Profile) RegisterHTTP(r *echo.Group) {
+	//+codegen=BindingApiHandler
+	r.GET("/profile", s.Get)
+}
+
+// Get
+//
+//	@id public-profile-get
+//	@Summary Get
+//	@Description Get the author profile.
+//	@Tags public/profile
+//	@Accept json
+//	@Produce json
+//	@Success 200 {object} dtopublic.GetProfileRes "JSON Response Payload"
+//	@Failure 304 "Not Modified"
+//	@Failure 404 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /public/profile [GET]
+func (s *Profile) Get(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.Get, api.O().Cache(DetailCache))
+}
@@ -0,0 +1,66 @@
+package apipublic
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/cirius-go/portfolio-server/internal/api"
+)
+
+// Project API controller.
+type Project struct {
+	svc ProjectService
+}
+
+// NewProject creates a new Project controller.
+func NewProject(svc ProjectService) *Project {
+	return &Project{
+		svc: svc,
+	}
+}
+
+// RegisterHTTP register HTTP handlers based on actions for the service.
+func (s *Project) RegisterHTTP(r *echo.Group) {
+	//+codegen=BindingApiHandler
+	r.GET("/projects", s.List)
+	r.GET("/projects/:slug", s.Get)
+}
+
+// List
+//
+//	@id public-projects-list
+//	@Summary List
+//	@Description List the published projects in display order.
+//	@Tags public/projects
+//	@Accept json
+//	@Produce json
+//	@Param p query int false "Page"
+//	@Param pp query int false "Per page"
+//	@Param featured query bool false "Only featured projects"
+//	@Param tag query string false "Tag slug"
+//	@Success 200 {object} dtopublic.ListProjectRes "JSON Response Payload"
+//	@Failure 304 "Not Modified"
+//	@Failure 400 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /public/projects [GET]
+func (s *Project) List(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.List, api.O().Cache(ListCache))
+}
+
+// Get
+//
+//	@id public-projects-get
+//	@Summary Get
+//	@Description Get the published project by slug.
+//	@Tags public/projects
+//	@Accept json
+//	@Produce json
+//	@Param slug path string true "Slug"
+//	@Success 200 {object} dtopublic.GetProjectRes "JSON Response Payload"
+//	@Failure 304 "Not Modified"
+//	@Failure 400 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 404 {object} dto.ErrorRes "JSON Response Payload"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /public/projects/{slug} [GET]
+func (s *Project) Get(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.Get, api.O().Cache(DetailCache))
+}
@@ -0,0 +1,16 @@
+package apipublic
+
+import (
+	"time"
+
+	"github.com/cirius-go/portfolio-server/internal/api"
+)
+
+// RateLimit is the policy of the public routes. Most of their responses are
+// served by the CDN, so it only stops the clients hammering the origin. The
+// CDN ranges must be trusted proxies for the clients to be told apart.
+var RateLimit = api.RateLimitPolicy{
+	Name:   "public",
+	Limit:  300,
+	Window: time.Minute,
+}
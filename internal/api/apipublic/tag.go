@@ -0,0 +1,41 @@
+package apipublic
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/cirius-go/portfolio-server/internal/api"
+)
+
+// Tag API controller.
+type Tag struct {
+	svc TagService
+}
+
+// NewTag creates a new Tag controller.
+func NewTag(svc TagService) *Tag {
+	return &Tag{
+		svc: svc,
+	}
+}
+
+// RegisterHTTP register HTTP handlers based on actions for the service.
+func (s *Tag) RegisterHTTP(r *echo.Group) {
+	//+codegen=BindingApiHandler
+	r.GET("/tags", s.List)
+}
+
+// List
+//
+//	@id public-tags-list
+//	@Summary List
+//	@Description List the tags of the published articles and projects.
+//	@Tags public/tags
+//	@Accept json
+//	@Produce json
+//	@Success 200 {object} dtopublic.ListTagRes "JSON Response Payload"
+//	@Failure 304 "Not Modified"
+//	@Failure 500 {object} dto.ErrorRes "JSON Response Payload"
+//	@Router /public/tags [GET]
+func (s *Tag) List(c echo.Context) error {
+	return api.MakeJSONHandler(c, s.svc.List, api.O().Cache(ListCache))
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"context"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+
+	"github.com/cirius-go/portfolio-server/internal/service"
+	"github.com/cirius-go/portfolio-server/pkg/logging"
+)
+
+// SessionParser parses the bearer token of the request into a session.
+type SessionParser interface {
+	ParseSession(ctx context.Context, token string) (*service.Session, error)
+}
+
+// Authenticate returns a middleware which requires a valid bearer token and
+// stores the parsed session in the request context.
+func Authenticate(p SessionParser, skipper middleware.Skipper) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if skipper != nil && skipper(c) {
+				return next(c)
+			}
+
+			token, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+			if !ok || token == "" {
+				return service.ErrMissingSession
+			}
+
+			ctx := c.Request().Context()
+			sess, err := p.ParseSession(ctx, token)
+			if err != nil {
+				return err
+			}
+
+			ctx = logging.With(service.WithSession(ctx, sess), "user_id", sess.UserID)
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CachePolicy describes how a successful response may be cached by browsers
+// and shared caches such as the CDN.
+type CachePolicy struct {
+	// MaxAge is how long browsers may reuse the response.
+	MaxAge time.Duration
+	// SMaxAge overrides MaxAge for shared caches when non zero.
+	SMaxAge time.Duration
+	// StaleWhileRevalidate is how long a stale response may be served while
+	// it is revalidated in the background.
+	StaleWhileRevalidate time.Duration
+	// StaleIfError is how long a stale response may be served when the
+	// origin fails.
+	StaleIfError time.Duration
+	// Private forbids shared caches to store the response.
+	Private bool
+}
+
+// CacheControl returns the Cache-Control header value of the policy.
+func (p *CachePolicy) CacheControl() string {
+	directives := []string{"public"}
+	if p.Private {
+		directives[0] = "private"
+	}
+
+	directives = append(directives, fmt.Sprintf("max-age=%d", int(p.MaxAge.Seconds())))
+	if p.SMaxAge > 0 && !p.Private {
+		directives = append(directives, fmt.Sprintf("s-maxage=%d", int(p.SMaxAge.Seconds())))
+	}
+	if p.StaleWhileRevalidate > 0 {
+		directives = append(directives, fmt.Sprintf("stale-while-revalidate=%d", int(p.StaleWhileRevalidate.Seconds())))
+	}
+	if p.StaleIfError > 0 {
+		directives = append(directives, fmt.Sprintf("stale-if-error=%d", int(p.StaleIfError.Seconds())))
+	}
+	return strings.Join(directives, ", ")
+}
+
+// LastModifier is implemented by responses which know when their content was
+// last changed. It is sent as Last-Modified and used for If-Modified-Since.
+type LastModifier interface {
+	LastModified() time.Time
+}
+
+// ETag returns the strong entity tag of the response body.
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// writeCachedJSON writes the response with the validators and Cache-Control
+// header of the policy, or 304 Not Modified if the request preconditions
+// show that the client already has it.
+func writeCachedJSON(c echo.Context, status int, res any, policy *CachePolicy) error {
+	body, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+
+	var (
+		h            = c.Response().Header()
+		etag         = ETag(body)
+		lastModified time.Time
+	)
+	if lm, ok := res.(LastModifier); ok {
+		lastModified = lm.LastModified().UTC().Truncate(time.Second)
+	}
+
+	h.Set(echo.HeaderCacheControl, policy.CacheControl())
+	h.Set("ETag", etag)
+	if !lastModified.IsZero() {
+		h.Set(echo.HeaderLastModified, lastModified.Format(http.TimeFormat))
+	}
+
+	if notModified(c.Request(), etag, lastModified) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.JSONBlob(status, body)
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since when the former is
+// absent (RFC 9110 section 13.2.2).
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := r.Header.Get(echo.HeaderIfModifiedSince); ims != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(ims)
+		return err == nil && !lastModified.After(t)
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/cirius-go/portfolio-server/internal/repo"
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+	"github.com/cirius-go/portfolio-server/internal/service"
+	"github.com/cirius-go/portfolio-server/util"
+)
+
+// RequestContext returns a middleware which stores the debug flag in the
+// request context. The flag is set in debug mode, read with
+// serverDebug, or from echo when nil. The debug header is honored by
+// DebugSession once the session is known.
+func RequestContext(serverDebug func() bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var (
+				r     = c.Request()
+				ctx   = r.Context()
+				debug = c.Echo().Debug
+			)
+			if serverDebug != nil {
+				debug = serverDebug()
+			}
+
+			if debug {
+				ctx = repo.WithDebug(ctx, true)
+			}
+
+			c.SetRequest(r.WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+// DebugSession returns a middleware which sets the debug flag of the requests
+// of admin sessions sending a truthy debug header. It must run after
+// Authenticate.
+func DebugSession(debugHeader string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			r := c.Request()
+			if !util.StrBool(r.Header.Get(debugHeader)) {
+				return next(c)
+			}
+			if sess, ok := service.SessionFromContext(r.Context()); ok && sess.Role == model.UserRoleAdmin {
+				c.SetRequest(r.WithContext(repo.WithDebug(r.Context(), true)))
+			}
+			return next(c)
+		}
+	}
+}
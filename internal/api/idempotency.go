@@ -0,0 +1,158 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/cirius-go/portfolio-server/internal/service"
+	"github.com/cirius-go/portfolio-server/pkg/errors"
+	"github.com/cirius-go/portfolio-server/pkg/idempotency"
+	"github.com/cirius-go/portfolio-server/pkg/logging"
+)
+
+const (
+	// HeaderIdempotencyKey is the header of the key chosen by the client.
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed is set on the responses which are replayed.
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	// idempotencyStoreKey is the echo context key of the store.
+	idempotencyStoreKey = "api.idempotency_store"
+	// maxIdempotencyKeyLen is the max length of the keys.
+	maxIdempotencyKeyLen = 255
+)
+
+var (
+	// ErrIdempotencyKeyTooLong is returned when the key is too long.
+	ErrIdempotencyKeyTooLong = errors.NewInvalidRequest(nil, "Idempotency-Key must be at most %d characters", maxIdempotencyKeyLen)
+	// ErrIdempotencyKeyReused is returned when the key was used by another
+	// request.
+	ErrIdempotencyKeyReused = errors.NewConflict(nil, "Idempotency-Key was used with a different request")
+	// ErrIdempotencyKeyInProgress is returned when the request of the key is
+	// still handled.
+	ErrIdempotencyKeyInProgress = errors.NewConflict(nil, "A request with this Idempotency-Key is in progress")
+)
+
+// WithIdempotencyStore returns a middleware which makes the store record the
+// responses of the idempotent routes. The keys are ignored without it.
+func WithIdempotencyStore(s idempotency.Store) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(idempotencyStoreKey, s)
+			return next(c)
+		}
+	}
+}
+
+// idempotent handles the POST, PATCH and DELETE requests sent with an
+// Idempotency-Key once per key. The response is recorded and replayed to the
+// later requests with the same key and payload, those with another payload
+// are rejected.
+//
+// The key is scoped to the session user, and released unless the response is
+// recorded, e.g. when the handler fails or panics, so that the request can be
+// retried. The key of a request which neither completes nor releases it is
+// claimed again once its lease ends.
+func idempotent(c echo.Context, enabled bool, handle func() error) error {
+	var (
+		req      = c.Request()
+		key      = req.Header.Get(HeaderIdempotencyKey)
+		store, _ = c.Get(idempotencyStoreKey).(idempotency.Store)
+	)
+	if !enabled || store == nil || key == "" {
+		return handle()
+	}
+	switch req.Method {
+	case http.MethodPost, http.MethodPatch, http.MethodDelete:
+	default:
+		return handle()
+	}
+	if len(key) > maxIdempotencyKeyLen {
+		return ErrIdempotencyKeyTooLong
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	var (
+		ctx = req.Context()
+		h   = sha256.New()
+	)
+	h.Write([]byte(req.Method + " " + req.URL.RequestURI() + "\n"))
+	h.Write(body)
+	fingerprint := hex.EncodeToString(h.Sum(nil))
+
+	scope := "anon:" + c.RealIP()
+	if sess, ok := service.SessionFromContext(ctx); ok {
+		scope = "user:" + sess.UserID
+	}
+	key = scope + ":" + key
+
+	claim, rec, err := store.Begin(ctx, key, fingerprint)
+	if err != nil {
+		return errors.NewInternal(err, "Failed to claim the Idempotency-Key")
+	}
+	if rec != nil {
+		switch {
+		case rec.Fingerprint != fingerprint:
+			return ErrIdempotencyKeyReused
+		case !rec.Completed():
+			return ErrIdempotencyKeyInProgress
+		}
+		c.Response().Header().Set(HeaderIdempotentReplayed, "true")
+		if len(rec.Body) == 0 {
+			return c.NoContent(rec.Status)
+		}
+		return c.Blob(rec.Status, rec.ContentType, rec.Body)
+	}
+
+	// the key is settled even when the client went away.
+	var (
+		storeCtx = context.WithoutCancel(ctx)
+		recorded bool
+	)
+	defer func() {
+		if recorded {
+			return
+		}
+		if err := store.Release(storeCtx, claim); err != nil {
+			logging.FromContext(ctx).Error("failed to release the idempotency key", "error", err)
+		}
+	}()
+
+	res := c.Response()
+	recorder := &responseRecorder{ResponseWriter: res.Writer}
+	res.Writer = recorder
+	defer func() { res.Writer = recorder.ResponseWriter }()
+
+	if err := handle(); err != nil || !res.Committed {
+		return err
+	}
+	if err := store.Complete(storeCtx, claim, res.Status, res.Header().Get(echo.HeaderContentType), recorder.body.Bytes()); err != nil {
+		logging.FromContext(ctx).Error("failed to record the idempotent response, the key is released", "error", err)
+		return nil
+	}
+	recorded = true
+	return nil
+}
+
+// responseRecorder copies the body written to the response.
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write implements http.ResponseWriter.
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,238 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+
+	"github.com/cirius-go/portfolio-server/pkg/idempotency"
+)
+
+// memoryStore is an idempotency.Store in memory, whose Complete fails with
+// completeErr when set. Like the Postgres store, its calls fail once their
+// context is done and only settle the key of a claim still holding it.
+type memoryStore struct {
+	mu   sync.Mutex
+	recs map[string]*idempotency.Record
+
+	completeErr error
+}
+
+func (s *memoryStore) Begin(ctx context.Context, key, fingerprint string) (*idempotency.Claim, *idempotency.Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec, ok := s.recs[key]; ok {
+		return nil, rec, nil
+	}
+	rec := &idempotency.Record{Key: key, Fingerprint: fingerprint, CreatedAt: time.Now()}
+	s.recs[key] = rec
+	return &idempotency.Claim{Key: key, Fingerprint: fingerprint, CreatedAt: rec.CreatedAt}, nil, nil
+}
+
+// held returns the record of the key while the claim holds it.
+func (s *memoryStore) held(c *idempotency.Claim) (*idempotency.Record, bool) {
+	rec, ok := s.recs[c.Key]
+	if !ok || rec.Completed() || rec.Fingerprint != c.Fingerprint || !rec.CreatedAt.Equal(c.CreatedAt) {
+		return nil, false
+	}
+	return rec, true
+}
+
+func (s *memoryStore) Complete(ctx context.Context, claim *idempotency.Claim, status int, contentType string, body []byte) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if s.completeErr != nil {
+		return s.completeErr
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec, ok := s.held(claim); ok {
+		rec.Status, rec.ContentType, rec.Body = status, contentType, body
+	}
+	return nil
+}
+
+func (s *memoryStore) Release(ctx context.Context, claim *idempotency.Claim) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.held(claim); ok {
+		delete(s.recs, claim.Key)
+	}
+	return nil
+}
+
+func (s *memoryStore) has(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.recs[key]
+	return ok
+}
+
+func TestIdempotent(t *testing.T) {
+	const key = "anon:192.0.2.1:k1"
+
+	type createReq struct {
+		Name string `json:"name"`
+	}
+	type createRes struct {
+		N int `json:"n"`
+	}
+
+	tests := []struct {
+		name string
+		// handle is the handler of the first request.
+		handle      func(ctx context.Context) (*createRes, error)
+		completeErr error
+		cancel      bool
+		// wantHeld is whether the key is held once the first request is
+		// answered.
+		wantHeld bool
+		// wantRetry is the status of the retry with the same key.
+		wantRetry    int
+		wantReplayed bool
+	}{
+		{
+			name:         "recorded and replayed",
+			handle:       func(context.Context) (*createRes, error) { return &createRes{N: 1}, nil },
+			wantHeld:     true,
+			wantRetry:    http.StatusOK,
+			wantReplayed: true,
+		},
+		{
+			name:      "released on error",
+			handle:    func(context.Context) (*createRes, error) { return nil, errors.New("failed") },
+			wantRetry: http.StatusOK,
+		},
+		{
+			name:      "released on panic",
+			handle:    func(context.Context) (*createRes, error) { panic("boom") },
+			wantRetry: http.StatusOK,
+		},
+		{
+			name:        "released when the response cannot be recorded",
+			handle:      func(context.Context) (*createRes, error) { return &createRes{N: 1}, nil },
+			completeErr: errors.New("database down"),
+			wantRetry:   http.StatusOK,
+		},
+		{
+			name:         "recorded when the client went away",
+			handle:       func(context.Context) (*createRes, error) { return &createRes{N: 1}, nil },
+			cancel:       true,
+			wantHeld:     true,
+			wantRetry:    http.StatusOK,
+			wantReplayed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				store = &memoryStore{recs: map[string]*idempotency.Record{}, completeErr: tt.completeErr}
+				calls int
+			)
+			e := echo.New()
+			e.IPExtractor = echo.ExtractIPDirect()
+			e.Use(middleware.Recover(), WithIdempotencyStore(store))
+			e.POST("/items", JSONHandlerFunc(func(ctx context.Context, _ *createReq) (*createRes, error) {
+				calls++
+				if calls == 1 {
+					return tt.handle(ctx)
+				}
+				return &createRes{N: calls}, nil
+			}, O().Idempotent()))
+
+			send := func(ctx context.Context, body string) *httptest.ResponseRecorder {
+				req := httptest.NewRequestWithContext(ctx, http.MethodPost, "/items", strings.NewReader(body))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+				req.Header.Set(HeaderIdempotencyKey, "k1")
+				req.RemoteAddr = "192.0.2.1:1234"
+				rec := httptest.NewRecorder()
+				e.ServeHTTP(rec, req)
+				return rec
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				// the context ends while the request is handled.
+				handle := tt.handle
+				tt.handle = func(ctx context.Context) (*createRes, error) {
+					cancel()
+					return handle(ctx)
+				}
+			}
+			send(ctx, `{"name":"a"}`)
+			if held := store.has(key); held != tt.wantHeld {
+				t.Fatalf("key held = %v, want %v", held, tt.wantHeld)
+			}
+
+			rec := send(context.Background(), `{"name":"a"}`)
+			if rec.Code != tt.wantRetry {
+				t.Fatalf("retry status = %d, want %d, body %s", rec.Code, tt.wantRetry, rec.Body)
+			}
+			if replayed := rec.Header().Get(HeaderIdempotentReplayed) == "true"; replayed != tt.wantReplayed {
+				t.Errorf("retry replayed = %v, want %v", replayed, tt.wantReplayed)
+			}
+		})
+	}
+}
+
+func TestIdempotentConflicts(t *testing.T) {
+	const key = "anon:192.0.2.1:k1"
+
+	sum := sha256.Sum256([]byte("POST /items\n{}"))
+	fingerprint := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name    string
+		rec     *idempotency.Record
+		wantErr error
+	}{
+		{
+			name:    "in progress",
+			rec:     &idempotency.Record{Key: key, Fingerprint: fingerprint},
+			wantErr: ErrIdempotencyKeyInProgress,
+		},
+		{
+			name:    "used with another payload",
+			rec:     &idempotency.Record{Key: key, Fingerprint: "other", Status: http.StatusOK},
+			wantErr: ErrIdempotencyKeyReused,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{}`))
+			req.Header.Set(HeaderIdempotencyKey, "k1")
+			req.RemoteAddr = "192.0.2.1:1234"
+			e := echo.New()
+			e.IPExtractor = echo.ExtractIPDirect()
+			c := e.NewContext(req, httptest.NewRecorder())
+
+			store := &memoryStore{recs: map[string]*idempotency.Record{key: tt.rec}}
+			c.Set(idempotencyStoreKey, store)
+			err := idempotent(c, true, func() error {
+				t.Fatal("the handler ran")
+				return nil
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("idempotent() error = %v, want %v", err, tt.wantErr)
+			}
+			if !store.has(key) {
+				t.Errorf("key released, want it kept")
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+
+	"github.com/cirius-go/portfolio-server/pkg/db"
+)
+
+// ReadPrimary returns a middleware which sends the reads of the request to
+// the primary database instead of the replicas, so that the caller sees its
+// own writes at once.
+func ReadPrimary(skipper middleware.Skipper) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if skipper != nil && skipper(c) {
+				return next(c)
+			}
+
+			r := c.Request()
+			c.SetRequest(r.WithContext(db.WithPrimary(r.Context())))
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/cirius-go/portfolio-server/internal/service"
+	"github.com/cirius-go/portfolio-server/pkg/errors"
+	"github.com/cirius-go/portfolio-server/pkg/logging"
+	"github.com/cirius-go/portfolio-server/pkg/ratelimit"
+)
+
+// rateLimiterKey is the echo context key of the limiter.
+const rateLimiterKey = "api.rate_limiter"
+
+// RateLimitKey returns the key whose hits are counted for the request.
+type RateLimitKey func(c echo.Context) string
+
+// ByIP counts the hits by client IP.
+func ByIP(c echo.Context) string {
+	return "ip:" + c.RealIP()
+}
+
+// ByUser counts the hits by session user, and by client IP for the requests
+// without a session.
+func ByUser(c echo.Context) string {
+	if sess, ok := service.SessionFromContext(c.Request().Context()); ok {
+		return "user:" + sess.UserID
+	}
+	return ByIP(c)
+}
+
+// ByToken counts the hits by bearer token, and by client IP for the requests
+// without one.
+func ByToken(c echo.Context) string {
+	token, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+	if !ok || token == "" {
+		return ByIP(c)
+	}
+	sum := sha256.Sum256([]byte(token))
+	return "token:" + hex.EncodeToString(sum[:16])
+}
+
+// ByAccount counts the hits by the account the request targets, see
+// AccountRequest, so that guesses spread over many client IPs are limited
+// too. It is empty for the other requests, which are not counted.
+func ByAccount(rq any) string {
+	a, ok := rq.(AccountRequest)
+	if !ok {
+		return ""
+	}
+	account := a.RateLimitAccount()
+	if account == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(account))
+	return "account:" + hex.EncodeToString(sum[:16])
+}
+
+// AccountRequest is a request naming the account it targets, e.g. by email or
+// token.
+type AccountRequest interface {
+	RateLimitAccount() string
+}
+
+// RateLimitPolicy describes how many requests a key may send per window.
+type RateLimitPolicy struct {
+	// Name namespaces the counters, the routes of the same policy share
+	// their limit. The limiter may override Limit and Window by name.
+	Name   string
+	Limit  int
+	Window time.Duration
+	// Key is ByIP when nil.
+	Key RateLimitKey
+}
+
+// WithRateLimiter returns a middleware which makes the limiter count the
+// hits of the rate limited routes. They are not limited without it.
+func WithRateLimiter(l *ratelimit.Limiter) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(rateLimiterKey, l)
+			return next(c)
+		}
+	}
+}
+
+// RateLimit returns a middleware which limits the routes of a group with the
+// policy.
+func RateLimit(p RateLimitPolicy) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if err := rateLimit(c, &p); err != nil {
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+// rateLimit counts the hit of the request with the key of the policy.
+func rateLimit(c echo.Context, p *RateLimitPolicy) error {
+	if p == nil {
+		return nil
+	}
+	keyFn := p.Key
+	if keyFn == nil {
+		keyFn = ByIP
+	}
+	return allow(c, p, keyFn(c))
+}
+
+// rateLimitAccount counts the hit of the bound request by the account it
+// targets, see ByAccount.
+func rateLimitAccount(c echo.Context, p *RateLimitPolicy, rq any) error {
+	if p == nil {
+		return nil
+	}
+	key := ByAccount(rq)
+	if key == "" {
+		return nil
+	}
+	return allow(c, p, key)
+}
+
+// allow counts the hit of the key and sets the RateLimit headers, it fails
+// with a too many requests error once the limit is reached.
+//
+// The request is let through when the counters cannot be reached.
+func allow(c echo.Context, p *RateLimitPolicy, key string) error {
+	l, _ := c.Get(rateLimiterKey).(*ratelimit.Limiter)
+	if l == nil {
+		return nil
+	}
+
+	limit, window := p.Limit, p.Window
+	if r, ok := l.Override(p.Name); ok {
+		limit, window = r.Limit, r.Window
+	}
+
+	ctx := c.Request().Context()
+	res, err := l.Allow(ctx, p.Name+":"+key, limit, window)
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to rate limit", "policy", p.Name, "error", err)
+		return nil
+	}
+
+	reset := strconv.Itoa(int(math.Ceil(res.Reset.Seconds())))
+	h := c.Response().Header()
+	h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	h.Set("RateLimit-Reset", reset)
+	h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit, int(window.Seconds())))
+	if !res.Allowed {
+		h.Set(echo.HeaderRetryAfter, reset)
+		return errors.NewTooManyRequests(nil, "Too many requests, retry in %s seconds", reset)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/cirius-go/portfolio-server/pkg/errors"
+	"github.com/cirius-go/portfolio-server/pkg/ratelimit"
+)
+
+type loginReq struct {
+	Email string `json:"email"`
+}
+
+func (r *loginReq) RateLimitAccount() string {
+	return r.Email
+}
+
+func TestAccountRateLimit(t *testing.T) {
+	var (
+		ipPolicy      = RateLimitPolicy{Name: "ip", Limit: 2, Window: time.Minute}
+		accountPolicy = RateLimitPolicy{Name: "account", Limit: 2, Window: time.Minute}
+	)
+	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+	e.HTTPErrorHandler = errors.CreateEchoErrorHandler()
+	e.Use(WithRateLimiter(ratelimit.NewWithStore(ratelimit.NewMemory(), "")))
+	e.POST("/login", JSONHandlerFunc(func(context.Context, *loginReq) (*struct{}, error) {
+		return &struct{}{}, nil
+	}, O().RateLimit(ipPolicy).AccountRateLimit(accountPolicy)))
+
+	// the hits are sent in order.
+	hits := []struct {
+		name       string
+		ip         string
+		email      string
+		wantStatus int
+	}{
+		{name: "first guess", ip: "198.51.100.1", email: "a@example.com", wantStatus: http.StatusOK},
+		{name: "same account from another ip", ip: "198.51.100.2", email: "a@example.com", wantStatus: http.StatusOK},
+		{name: "account over its limit", ip: "198.51.100.3", email: "a@example.com", wantStatus: http.StatusTooManyRequests},
+		{name: "other account", ip: "198.51.100.1", email: "b@example.com", wantStatus: http.StatusOK},
+		{name: "ip over its limit", ip: "198.51.100.1", email: "c@example.com", wantStatus: http.StatusTooManyRequests},
+	}
+	for _, h := range hits {
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"email":"`+h.email+`"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.RemoteAddr = h.ip + ":1234"
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		if rec.Code != h.wantStatus {
+			t.Errorf("%s: status = %d, want %d, body %s", h.name, rec.Code, h.wantStatus, rec.Body)
+		}
+	}
+}
+
+func TestByAccount(t *testing.T) {
+	tests := []struct {
+		name string
+		rq   any
+		want bool
+	}{
+		{name: "account request", rq: &loginReq{Email: "a@example.com"}, want: true},
+		{name: "empty account", rq: &loginReq{}},
+		{name: "other request", rq: &struct{}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := ByAccount(tt.rq)
+			if (key != "") != tt.want {
+				t.Fatalf("ByAccount() = %q, want a key: %v", key, tt.want)
+			}
+			if strings.Contains(key, "example.com") {
+				t.Errorf("ByAccount() = %q, want the account hashed", key)
+			}
+		})
+	}
+}
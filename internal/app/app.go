@@ -0,0 +1,238 @@
+// Package app wires the services and controllers of the api into an echo
+// server, so that the api can be booted from cmd/api and from the tests.
+package app
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/casbin/casbin"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	echoswag "github.com/swaggo/echo-swagger"
+
+	"github.com/cirius-go/portfolio-server/docs/swagger"
+	"github.com/cirius-go/portfolio-server/internal/api"
+	"github.com/cirius-go/portfolio-server/internal/api/apicms"
+	"github.com/cirius-go/portfolio-server/internal/api/apipublic"
+	"github.com/cirius-go/portfolio-server/internal/config"
+	"github.com/cirius-go/portfolio-server/internal/service"
+	"github.com/cirius-go/portfolio-server/internal/service/servicecms"
+	"github.com/cirius-go/portfolio-server/internal/service/servicepublic"
+	"github.com/cirius-go/portfolio-server/internal/uow"
+	"github.com/cirius-go/portfolio-server/pkg/cache"
+	"github.com/cirius-go/portfolio-server/pkg/errors"
+	"github.com/cirius-go/portfolio-server/pkg/health"
+	"github.com/cirius-go/portfolio-server/pkg/idempotency"
+	"github.com/cirius-go/portfolio-server/pkg/logging"
+	"github.com/cirius-go/portfolio-server/pkg/mailer"
+	"github.com/cirius-go/portfolio-server/pkg/metrics"
+	"github.com/cirius-go/portfolio-server/pkg/ratelimit"
+	"github.com/cirius-go/portfolio-server/pkg/server"
+	"github.com/cirius-go/portfolio-server/util"
+)
+
+// Deps contains the dependencies of the api.
+type Deps struct {
+	Config *config.Config
+	// ConfigStore reloads the config, the reloaded session keys, CORS
+	// origins, debug mode, log level and rate limits are applied without a
+	// restart. A store of Config which is never reloaded is used when nil.
+	ConfigStore *config.Store
+	UOW         uow.UnitOfWork
+	Cache       *cache.Cache
+	Mailer      mailer.Mailer
+	Enforcer    servicecms.RBACEnforcer
+	// Auth parses the sessions of the cms routes. The auth service is used
+	// when nil, tests set it to skip the token signatures.
+	Auth api.SessionParser
+	// Metrics is the registry of the api metrics, a new one is created when
+	// nil.
+	Metrics *prometheus.Registry
+	// Health runs the readiness checks, none is run when nil.
+	Health *health.Checker
+	// RateLimiter counts the hits of the rate limited routes, they are not
+	// limited when nil.
+	RateLimiter *ratelimit.Limiter
+	// Idempotency records the responses of the idempotent routes, the
+	// Idempotency-Key header is ignored when nil.
+	Idempotency idempotency.Store
+}
+
+// App is the api wired with its dependencies.
+type App struct {
+	HTTP    *server.HTTP
+	Metrics *prometheus.Registry
+	Health  *health.Checker
+
+	Invitations *servicecms.Invitation
+}
+
+// NewEnforcer creates the rbac enforcer with the default cms policies.
+func NewEnforcer(debug bool) *casbin.Enforcer {
+	enf := casbin.NewEnforcer(util.NewRBACModel(), debug)
+	for _, p := range servicecms.DefaultPolicies() {
+		enf.AddPolicy(p...)
+	}
+	return enf
+}
+
+// HTTPRegistrar is a handler that registers HTTP handlers.
+type HTTPRegistrar interface {
+	RegisterHTTP(g *echo.Group)
+}
+
+// New wires the api.
+func New(d Deps) *App {
+	var (
+		cfg        = d.Config
+		unitOfWork = d.UOW
+		enf        = d.Enforcer
+		appCache   = d.Cache
+		mail       = d.Mailer
+		reg        = d.Metrics
+		checker    = d.Health
+		store      = d.ConfigStore
+	)
+	if store == nil {
+		store = config.NewStore(cfg, nil)
+	}
+	if reg == nil {
+		reg = prometheus.NewRegistry()
+	}
+	if checker == nil {
+		checker = health.NewChecker(0)
+	}
+	svcMetrics := service.NewMetrics(reg)
+
+	// create services
+	var (
+		//+codegen=DefineCmsServices
+		userSvc       = servicecms.NewUser(unitOfWork, enf)
+		projectSvc    = servicecms.NewProject(unitOfWork, enf)
+		articleSvc    = servicecms.NewArticle(unitOfWork, enf)
+		authSvc       = servicecms.NewAuth(unitOfWork, enf, cfg.CMSSession, cfg.CMSMFA)
+		invitationSvc = servicecms.NewInvitation(unitOfWork, enf, mail, cfg.CMSOnboarding)
+		passwordSvc   = servicecms.NewPassword(unitOfWork, enf, mail, cfg.CMSOnboarding)
+	)
+	articleSvc.OnPublish(svcMetrics.OnPublish)
+	projectSvc.OnPublish(svcMetrics.OnPublish)
+
+	var auth api.SessionParser = authSvc
+	if d.Auth != nil {
+		auth = d.Auth
+	}
+
+	// new http server with config
+	var debug atomic.Bool
+	hs := cfg.HTTPServer
+	debug.Store(hs.Debug)
+	srvCfg := server.C().
+		SetAddress(hs.Host, hs.Port).
+		SetDebug(hs.Debug).
+		SetReadTimeout(hs.ReadTimeout).
+		SetWriteTimeout(hs.WriteTimeout).
+		SetCORS(hs.CORS).
+		SetSecureHeaders(hs.SecureHeaders).
+		SetBodyLimit(hs.BodyLimit).
+		SetRequestTimeout(hs.RequestTimeout).
+		SetTrustedProxies(hs.TrustedProxies...).
+		SetTLS(hs.TLSCertFile, hs.TLSKeyFile).
+		SetHTTP2(hs.HTTP2).
+		SetShutdownTimeout(cfg.DrainTimeout()).
+		SetDrainDelay(hs.DrainDelay).
+		SetCustomErrorHandler(errors.CreateEchoErrorHandler(errors.NewErrorHandlerConfig().WithServerDebug(debug.Load))).
+		SetMetrics(reg).
+		OnShutdown(checker.Drain)
+	srv := server.NewHTTPWithConfig(srvCfg)
+	router := srv.Echo
+	router.Use(api.RequestContext(debug.Load))
+	if d.RateLimiter != nil {
+		router.Use(api.WithRateLimiter(d.RateLimiter))
+	}
+	if d.Idempotency != nil {
+		router.Use(api.WithIdempotencyStore(d.Idempotency))
+	}
+	router.GET("/", checker.Liveness())
+	router.GET("/healthz", checker.Liveness())
+	router.GET("/readyz", checker.Readiness())
+	router.GET("/version", health.Version(config.GetStage()))
+
+	{
+		swagger.SwaggerInfo.Host = fmt.Sprintf("%s:%d", cfg.HTTPServer.Host, cfg.HTTPServer.Port)
+		router.GET("/swagger/*", echoswag.WrapHandler)
+	}
+	// metrics served on their own port are not exposed on the api.
+	if m := cfg.Metrics; m.Port == 0 && (m.Token != "" || config.IsLocal()) {
+		router.GET("/metrics", echo.WrapHandler(metrics.Handler(reg, m.Token)))
+	}
+	// bind services to the http server, the cms reads from the primary so that
+	// editors see their changes before they reach the replicas.
+	cmsRouter := router.Group("/cms",
+		api.Authenticate(auth, api.PathSkipper(apicms.AuthPublicRoutes...)),
+		api.DebugSession("X-Debug"),
+		api.ReadPrimary(nil),
+	)
+	for _, registrar := range []HTTPRegistrar{
+		//+codegen=DefineCmsAPIs
+		apicms.NewUser(userSvc),
+		apicms.NewProject(projectSvc),
+		apicms.NewArticle(articleSvc),
+		apicms.NewAuth(authSvc),
+		apicms.NewInvitation(invitationSvc),
+		apicms.NewPassword(passwordSvc),
+	} {
+		registrar.RegisterHTTP(cmsRouter)
+	}
+	if cfg.Admin.Enabled {
+		apicms.NewAdmin(servicecms.NewAdmin(enf, store)).RegisterHTTP(cmsRouter)
+	}
+
+	// bind public services to the http server, they require no session.
+	{
+		var (
+			//+codegen=DefinePublicServices
+			articleSvc = servicepublic.NewArticle(unitOfWork).WithCache(appCache)
+			projectSvc = servicepublic.NewProject(unitOfWork).WithCache(appCache)
+			tagSvc     = servicepublic.NewTag(unitOfWork).WithCache(appCache)
+			profileSvc = servicepublic.NewProfile(unitOfWork).WithCache(appCache)
+		)
+
+		publicRouter := router.Group("/public", api.RateLimit(apipublic.RateLimit))
+		for _, registrar := range []HTTPRegistrar{
+			//+codegen=DefinePublicAPIs
+			apipublic.NewArticle(articleSvc),
+			apipublic.NewProject(projectSvc),
+			apipublic.NewTag(tagSvc),
+			apipublic.NewProfile(profileSvc),
+		} {
+			registrar.RegisterHTTP(publicRouter)
+		}
+	}
+
+	// apply the reloaded config, the other changes require a restart.
+	store.Subscribe(func(s *config.Snapshot) {
+		authSvc.SetConfig(s.Config.CMSSession, s.Config.CMSMFA)
+	}, "CMS_SESSION_", "CMS_MFA_")
+	store.Subscribe(func(s *config.Snapshot) {
+		srv.SetAllowOrigins(s.Config.HTTPServer.CORS.AllowOrigins...)
+	}, "HTTP_SERVER_CORS_ALLOW_ORIGINS")
+	store.Subscribe(func(s *config.Snapshot) {
+		debug.Store(s.Config.HTTPServer.Debug)
+	}, "HTTP_SERVER_DEBUG")
+	store.Subscribe(func(s *config.Snapshot) {
+		logging.SetLevel(s.Config.Log.Level)
+	}, "LOG_LEVEL")
+	if d.RateLimiter != nil {
+		store.Subscribe(func(s *config.Snapshot) {
+			d.RateLimiter.SetOverrides(s.Config.RateLimit.Policies)
+		}, "RATE_LIMIT_POLICIES")
+	}
+
+	return &App{
+		HTTP:        srv,
+		Metrics:     reg,
+		Health:      checker,
+		Invitations: invitationSvc,
+	}
+}
@@ -0,0 +1,105 @@
+//go:build integration
+
+package app_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	otptotp "github.com/pquerna/otp/totp"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/cirius-go/portfolio-server/internal/app"
+	"github.com/cirius-go/portfolio-server/internal/dto/dtocms"
+	"github.com/cirius-go/portfolio-server/internal/itest"
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+)
+
+func TestAuthLoginMFA(t *testing.T) {
+	const (
+		email    = "editor@example.com"
+		password = "correct horse battery staple"
+	)
+
+	gdb := env.Tx(t)
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gdb.Create(&model.User{Email: email, PasswordHash: string(hash), Role: model.UserRoleEditor}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// the sessions are issued and verified by the auth service.
+	e := itest.NewApp(t, gdb, func(d *app.Deps) { d.Auth = nil }).HTTP.Echo
+	login := func(t *testing.T, password string) (int, *dtocms.LoginAuthRes) {
+		t.Helper()
+		rec := itest.Do(t, e, http.MethodPost, "/cms/auth/login", dtocms.LoginAuthReq{Email: email, Password: password}, "")
+		if rec.Code != http.StatusOK {
+			return rec.Code, nil
+		}
+		return rec.Code, itest.Decode[dtocms.LoginAuthRes](t, rec)
+	}
+	code := func(t *testing.T, secret string, at time.Time) string {
+		t.Helper()
+		c, err := otptotp.GenerateCode(secret, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	if status, _ := login(t, "wrong"); status != http.StatusUnauthorized {
+		t.Fatalf("login with a wrong password status = %d, want %d", status, http.StatusUnauthorized)
+	}
+
+	_, res := login(t, password)
+	if res == nil || res.Session == nil || res.MFARequired {
+		t.Fatalf("login = %+v, want a session", res)
+	}
+	token := res.Session.AccessToken
+
+	rec := itest.Do(t, e, http.MethodPost, "/cms/auth/mfa", dtocms.EnrollMFAAuthReq{}, token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("enroll mfa status = %d, body %s", rec.Code, rec.Body)
+	}
+	enrollment := itest.Decode[dtocms.EnrollMFAAuthRes](t, rec)
+
+	now := time.Now()
+	rec = itest.Do(t, e, http.MethodPost, "/cms/auth/mfa/activate", dtocms.ActivateMFAAuthReq{Code: code(t, enrollment.Secret, now)}, token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("activate mfa status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	_, res = login(t, password)
+	if res == nil || !res.MFARequired || res.Session != nil {
+		t.Fatalf("login with mfa = %+v, want a challenge", res)
+	}
+
+	tests := []struct {
+		name       string
+		code       string
+		wantStatus int
+	}{
+		// the code used by the activation cannot be replayed.
+		{name: "replayed code", code: code(t, enrollment.Secret, now), wantStatus: http.StatusUnauthorized},
+		{name: "next code", code: code(t, enrollment.Secret, now.Add(30*time.Second)), wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := dtocms.VerifyChallengeAuthReq{ChallengeToken: res.ChallengeToken, Code: tt.code}
+			rec := itest.Do(t, e, http.MethodPost, "/cms/auth/challenge/verify", req, "")
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("verify challenge status = %d, want %d, body %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			verified := itest.Decode[dtocms.VerifyChallengeAuthRes](t, rec)
+			if verified.Session == nil || verified.Session.AccessToken == "" {
+				t.Fatalf("verify challenge = %+v, want a session", verified)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+//go:build integration
+
+package app_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/cirius-go/portfolio-server/internal/itest"
+)
+
+var env *itest.Env
+
+func TestMain(m *testing.M) {
+	env = itest.MustStart()
+	code := m.Run()
+	env.Close()
+	os.Exit(code)
+}
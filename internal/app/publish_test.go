@@ -0,0 +1,112 @@
+//go:build integration
+
+package app_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/cirius-go/portfolio-server/internal/dto/dtocms"
+	"github.com/cirius-go/portfolio-server/internal/dto/dtopublic"
+	"github.com/cirius-go/portfolio-server/internal/itest"
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+	"github.com/cirius-go/portfolio-server/internal/service"
+)
+
+func createArticle(t *testing.T, gdb *gorm.DB, slug string, published bool) *model.Article {
+	t.Helper()
+
+	m := &model.Article{Title: slug, Slug: slug, Content: "content of " + slug, Status: model.PublishStatusDraft}
+	if published {
+		now := time.Now().Add(-time.Minute)
+		m.Status = model.PublishStatusPublished
+		m.PublishedAt = &now
+	}
+	if err := gdb.Create(m).Error; err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestPublishArticle(t *testing.T) {
+	gdb := env.Tx(t)
+	draft := createArticle(t, gdb, "draft", false)
+	e := itest.NewApp(t, gdb).HTTP.Echo
+
+	path := "/cms/articles/" + draft.ID + "/publish"
+	if rec := itest.Do(t, e, http.MethodPost, path, nil, ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("publish without a session status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	rec := itest.Do(t, e, http.MethodPost, path, nil, itest.Token(model.UserRoleAdmin, "admin"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("publish status = %d, body %s", rec.Code, rec.Body)
+	}
+	if res := itest.Decode[dtocms.PublishArticleRes](t, rec); res.Status != model.PublishStatusPublished || res.PublishedAt == nil {
+		t.Fatalf("publish = %+v, want a published article", res)
+	}
+
+	// the event is enqueued in the transaction of the publish.
+	var events []*model.OutboxEvent
+	if err := gdb.Where("topic = ?", service.TopicPublished).Find(&events).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Status != model.OutboxStatusPending {
+		t.Fatalf("outbox events = %+v, want one pending event", events)
+	}
+	var got service.PublishEvent
+	if err := json.Unmarshal(events[0].Payload, &got); err != nil {
+		t.Fatal(err)
+	}
+	if want := (service.PublishEvent{Entity: "articles", Slug: draft.Slug, Published: true}); got != want {
+		t.Errorf("outbox payload = %+v, want %+v", got, want)
+	}
+
+	rec = itest.Do(t, e, http.MethodGet, "/public/articles/"+draft.Slug, nil, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("get published article status = %d, body %s", rec.Code, rec.Body)
+	}
+}
+
+func TestPublicArticles(t *testing.T) {
+	gdb := env.Tx(t)
+	createArticle(t, gdb, "published", true)
+	createArticle(t, gdb, "hidden", false)
+	e := itest.NewApp(t, gdb).HTTP.Echo
+
+	rec := itest.Do(t, e, http.MethodGet, "/public/articles", nil, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list status = %d, body %s", rec.Code, rec.Body)
+	}
+	list := itest.Decode[dtopublic.ListArticleRes](t, rec)
+	if list.Total != 1 || len(list.Recs) != 1 || list.Recs[0].Slug != "published" {
+		t.Fatalf("list = %+v, want the published article only", list)
+	}
+
+	tests := []struct {
+		slug       string
+		wantStatus int
+	}{
+		{slug: "published", wantStatus: http.StatusOK},
+		{slug: "hidden", wantStatus: http.StatusNotFound},
+		{slug: "missing", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.slug, func(t *testing.T) {
+			rec := itest.Do(t, e, http.MethodGet, "/public/articles/"+tt.slug, nil, "")
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("get status = %d, want %d, body %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			if got := itest.Decode[dtopublic.GetArticleRes](t, rec); got.Slug != tt.slug || got.Content == "" {
+				t.Errorf("get = %+v, want the content of %s", got, tt.slug)
+			}
+		})
+	}
+}
@@ -3,15 +3,12 @@ package config
 import (
 	"context"
 	"errors"
-	"fmt"
 	"os"
-	"strings"
 	"sync"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
-	"github.com/aws/aws-sdk-go-v2/service/ssm"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 
 	"github.com/cirius-go/portfolio-server/pkg/util"
@@ -55,42 +52,3 @@ func GetAWSConfigWithExecRole(ctx context.Context) (aws.Config, error) {
 	cfg.Credentials = creds
 	return cfg, nil
 }
-
-// LoadFromAPS loads the configuration from AWS parameter store.
-// path: app/env
-func LoadFromAPS(path string, vars map[string]string, nextToken *string) error {
-	if path == "" {
-		return fmt.Errorf("path prefix is required")
-	}
-	if !strings.HasPrefix(path, "/") {
-		path = "/" + path
-	}
-	if !strings.HasSuffix(path, "/") {
-		path = path + "/"
-	}
-
-	cfg, err := config.LoadDefaultConfig(context.Background())
-	if err != nil {
-		return err
-	}
-	ssmClient := ssm.NewFromConfig(cfg)
-	resp, err := ssmClient.GetParametersByPath(context.Background(), &ssm.GetParametersByPathInput{
-		NextToken:      nextToken,
-		Path:           aws.String(path),
-		WithDecryption: aws.Bool(true),
-	})
-	if err != nil {
-		return err
-	}
-
-	for _, param := range resp.Parameters {
-		paramName := strings.Replace(*param.Name, path, "", 1)
-		vars[strings.ToUpper(paramName)] = *param.Value
-	}
-
-	if resp.NextToken != nil {
-		return LoadFromAPS(path, vars, resp.NextToken)
-	}
-
-	return nil
-}
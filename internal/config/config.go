@@ -1,34 +1,146 @@
 package config
 
 import (
+	"context"
+	"log/slog"
+	"net/http"
 	"os"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awscfg "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
 	"github.com/cirius-go/generic/slice"
-	"github.com/joho/godotenv"
-	"github.com/kelseyhightower/envconfig"
+	"github.com/labstack/echo/v4"
 
+	"github.com/cirius-go/portfolio-server/internal/config/ssmfake"
+	"github.com/cirius-go/portfolio-server/pkg/cache"
 	"github.com/cirius-go/portfolio-server/pkg/db"
+	"github.com/cirius-go/portfolio-server/pkg/idempotency"
+	"github.com/cirius-go/portfolio-server/pkg/logging"
+	"github.com/cirius-go/portfolio-server/pkg/mailer"
+	"github.com/cirius-go/portfolio-server/pkg/ratelimit"
+	"github.com/cirius-go/portfolio-server/pkg/server"
+	"github.com/cirius-go/portfolio-server/pkg/tracing"
 )
 
-var (
-	stage              = os.Getenv("STAGE")
-	parameterStorePath = os.Getenv("PARAMETER_STORE_PATH")
-)
+var stage = os.Getenv("STAGE")
 
 // HTTPServer represents the HTTP server configuration.
 type HTTPServer struct {
-	Host         string   `envconfig:"HOST"`
-	Port         int      `envconfig:"PORT"`
-	AllowOrigins []string `envconfig:"ALLOW_ORIGINS"`
+	Host         string            `envconfig:"HOST"`
+	Port         int               `envconfig:"PORT" validate:"min=1,max=65535"`
+	Debug        bool              `envconfig:"DEBUG"`
+	ReadTimeout  time.Duration     `envconfig:"READ_TIMEOUT" validate:"gt=0"`
+	WriteTimeout time.Duration     `envconfig:"WRITE_TIMEOUT" validate:"gt=0"`
+	CORS         server.CORSConfig `envconfig:"CORS"`
+	// SecureHeaders, BodyLimit and RequestTimeout harden the server, each of
+	// them can be disabled or skipped by route.
+	SecureHeaders  server.SecureHeadersConfig `envconfig:"SECURE_HEADERS"`
+	BodyLimit      server.BodyLimitConfig     `envconfig:"BODY_LIMIT"`
+	RequestTimeout server.TimeoutConfig       `envconfig:"REQUEST_TIMEOUT"`
+	// TrustedProxies are the CIDR ranges or IPs of the proxies setting
+	// X-Forwarded-For. Without them the client IP is the peer address, e.g.
+	// the cloudfront edge in front of the lambda: the infra sets the
+	// cloudfront origin-facing ranges when a cdn distribution is configured.
+	TrustedProxies []string `envconfig:"TRUSTED_PROXIES" validate:"dive,cidr|ip"`
+	TLSCertFile    string   `envconfig:"TLS_CERT_FILE" validate:"required_with=TLSKeyFile"`
+	TLSKeyFile     string   `envconfig:"TLS_KEY_FILE" validate:"required_with=TLSCertFile"`
+	HTTP2          bool     `envconfig:"HTTP2"` // h2c without TLS.
+	// DrainDelay is how long the server keeps serving once it reports not
+	// ready on shutdown, at least the interval of the readiness probes.
+	DrainDelay time.Duration `envconfig:"DRAIN_DELAY" validate:"min=0"`
 }
 
 // Session config.
+//
+// The keys sign the tokens, the previous keys only verify them until the
+// longest lived token issued before they were set expires, and can be cleared
+// to revoke them at once. Since every instance reloads the config on its own,
+// a key is rotated in two steps:
+//
+//  1. set the new key as the previous key, so that every instance accepts it;
+//  2. once every instance reloaded, at least CONFIG_RELOAD_POLL_INTERVAL
+//     later, swap the key and the previous key.
 type Session struct {
-	TTL        time.Duration `envconfig:"TTL"`
-	Key        []byte        `envconfig:"KEY"`
-	RefreshTTL time.Duration `envconfig:"REFRESH_TTL"`
-	RefreshKey []byte        `envconfig:"REFRESH_KEY"`
+	TTL                time.Duration `envconfig:"TTL" validate:"gt=0"`
+	Key                []byte        `envconfig:"KEY" validate:"min=32"`
+	PreviousKey        []byte        `envconfig:"PREVIOUS_KEY" validate:"omitempty,min=32"`
+	RefreshTTL         time.Duration `envconfig:"REFRESH_TTL" validate:"gt=0"`
+	RefreshKey         []byte        `envconfig:"REFRESH_KEY" validate:"min=32"`
+	PreviousRefreshKey []byte        `envconfig:"PREVIOUS_REFRESH_KEY" validate:"omitempty,min=32"`
+}
+
+// MFA represents the multi-factor authentication configuration.
+type MFA struct {
+	Issuer       string        `envconfig:"ISSUER" validate:"required"`
+	ChallengeTTL time.Duration `envconfig:"CHALLENGE_TTL" validate:"gt=0"`
+	ChallengeKey []byte        `envconfig:"CHALLENGE_KEY" validate:"min=32"`
+	// PreviousChallengeKey is rotated like the keys of Session.
+	PreviousChallengeKey []byte `envconfig:"PREVIOUS_CHALLENGE_KEY" validate:"omitempty,min=32"`
+	RecoveryCodeCount    int    `envconfig:"RECOVERY_CODE_COUNT" validate:"min=1,max=20"`
+	// MaxAttempts wrong codes in a row lock the second factor of the user
+	// for Lockout.
+	MaxAttempts int           `envconfig:"MAX_ATTEMPTS" validate:"min=1"`
+	Lockout     time.Duration `envconfig:"LOCKOUT" validate:"gt=0"`
+}
+
+// Onboarding represents the cms invitation and password reset configuration.
+type Onboarding struct {
+	BaseURL          string        `envconfig:"BASE_URL" validate:"required,url"` // url of the cms frontend, used in the emailed links.
+	InvitationTTL    time.Duration `envconfig:"INVITATION_TTL" validate:"gt=0"`
+	PasswordResetTTL time.Duration `envconfig:"PASSWORD_RESET_TTL" validate:"gt=0"`
+	// BootstrapAdminEmail is invited as admin on startup while it has no user.
+	BootstrapAdminEmail string `envconfig:"BOOTSTRAP_ADMIN_EMAIL" validate:"omitempty,email"`
+}
+
+// CDN represents the CDN configuration of the public api.
+type CDN struct {
+	// DistributionID is the CloudFront distribution in front of the public
+	// api. Invalidation on publish is disabled when empty.
+	DistributionID string `envconfig:"DISTRIBUTION_ID"`
+}
+
+// Outbox represents the outbox dispatcher configuration.
+type Outbox struct {
+	PollInterval time.Duration `envconfig:"POLL_INTERVAL" validate:"gt=0"`
+	BatchSize    int           `envconfig:"BATCH_SIZE" validate:"min=1"`
+	// Lease is how long a claimed event is hidden from other dispatchers.
+	Lease time.Duration `envconfig:"LEASE" validate:"gt=0"`
+	// MaxAttempts is the number of failed deliveries after which an event
+	// is dead-lettered.
+	MaxAttempts int           `envconfig:"MAX_ATTEMPTS" validate:"min=1"`
+	MinBackoff  time.Duration `envconfig:"MIN_BACKOFF" validate:"gt=0"`
+	MaxBackoff  time.Duration `envconfig:"MAX_BACKOFF" validate:"gtefield=MinBackoff"`
+}
+
+// Metrics represents the metrics endpoint configuration.
+type Metrics struct {
+	// Port serves /metrics on its own port instead of the api one.
+	Port int `envconfig:"PORT" validate:"min=0,max=65535"`
+	// Token is the bearer token required by /metrics. Without port nor
+	// token, /metrics is only served locally.
+	Token string `envconfig:"TOKEN"`
+	// Namespace is the CloudWatch namespace of the metrics in Lambda.
+	Namespace string `envconfig:"NAMESPACE"`
+}
+
+// Admin represents the configuration of the cms admin routes.
+type Admin struct {
+	// Enabled mounts pprof, the runtime stats, the config and the runtime
+	// settings under /cms/_admin. It is only enabled locally by default.
+	Enabled bool `envconfig:"ENABLED"`
+}
+
+// Reload represents the configuration of the hot reload of the config.
+type Reload struct {
+	// FileInterval is the period of the checks of the .env files, which are
+	// reloaded when they change. Zero disables the checks.
+	FileInterval time.Duration `envconfig:"FILE_INTERVAL" validate:"min=0"`
+	// PollInterval is the period of the reloads of every source, Parameter
+	// Store included. Zero disables the reloads.
+	PollInterval time.Duration `envconfig:"POLL_INTERVAL" validate:"min=0"`
 }
 
 // AssetBucket represents the asset bucket configuration.
@@ -41,19 +153,63 @@ type AssetBucket struct {
 
 // Config application.
 type Config struct {
-	HTTPServer   HTTPServer        `envconfig:"HTTP_SERVER"`
-	PGDB         db.PostgresConfig `envconfig:"PGDB"`
-	CMSSession   Session           `envconfig:"CMS_SESSION"`
-	AssetsBucket AssetBucket       `envconfig:"ASSETS_BUCKET"`
+	HTTPServer    HTTPServer         `envconfig:"HTTP_SERVER" desc:"HTTP server of the api"`
+	PGDB          db.PostgresConfig  `envconfig:"PGDB" desc:"Postgres database, the DSN takes precedence over the other fields"`
+	CMSSession    Session            `envconfig:"CMS_SESSION" desc:"Sessions of the cms, the keys sign the tokens"`
+	CMSMFA        MFA                `envconfig:"CMS_MFA" desc:"Two-factor authentication of the cms"`
+	CMSOnboarding Onboarding         `envconfig:"CMS_ONBOARDING" desc:"Invitations and password resets of the cms"`
+	Mailer        mailer.Config      `envconfig:"MAILER" desc:"Outgoing emails"`
+	PublicCDN     CDN                `envconfig:"PUBLIC_CDN" desc:"CDN in front of the public api"`
+	Cache         cache.Config       `envconfig:"CACHE" desc:"Cache of the public api"`
+	Outbox        Outbox             `envconfig:"OUTBOX" desc:"Outbox dispatcher"`
+	AssetsBucket  AssetBucket        `envconfig:"ASSETS_BUCKET" desc:"S3 bucket of the uploaded assets"`
+	Log           logging.Config     `envconfig:"LOG" desc:"Logs"`
+	Metrics       Metrics            `envconfig:"METRICS" desc:"Prometheus metrics, CloudWatch in Lambda"`
+	Tracing       tracing.Config     `envconfig:"TRACING" desc:"OpenTelemetry tracing"`
+	RateLimit     ratelimit.Config   `envconfig:"RATE_LIMIT" desc:"Rate limits of the auth and public routes"`
+	Idempotency   idempotency.Config `envconfig:"IDEMPOTENCY" desc:"Idempotency-Key replays"`
+	Admin         Admin              `envconfig:"ADMIN" desc:"Admin routes under /cms/_admin"`
+	ConfigReload  Reload             `envconfig:"CONFIG_RELOAD" desc:"Hot reload of the config, see Store"`
+	// ShutdownTimeout bounds the whole shutdown, it must be below the grace
+	// period of the orchestrator. ShutdownCloseTimeout of it is left to the
+	// release of the resources, e.g. the database, the rest bounds the drain
+	// of the in-flight requests and work.
+	ShutdownTimeout      time.Duration `envconfig:"SHUTDOWN_TIMEOUT" validate:"gt=0" desc:"Graceful shutdown"`
+	ShutdownCloseTimeout time.Duration `envconfig:"SHUTDOWN_CLOSE_TIMEOUT" validate:"gt=0,ltfield=ShutdownTimeout"`
+}
+
+// DrainTimeout is the part of the shutdown timeout bounding the drain of the
+// in-flight requests and work.
+func (c *Config) DrainTimeout() time.Duration {
+	return c.ShutdownTimeout - c.ShutdownCloseTimeout
 }
 
 // C creates a new default config.
 func C() *Config {
-	return &Config{
+	c := &Config{
 		HTTPServer: HTTPServer{
 			Host:         "localhost",
 			Port:         3000,
-			AllowOrigins: []string{"http://localhost:4000"},
+			ReadTimeout:  60 * time.Second,
+			WriteTimeout: 60 * time.Second,
+			CORS: server.CORSConfig{
+				AllowOrigins: []string{"http://localhost:4000"},
+				AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodPatch, http.MethodDelete, http.MethodOptions},
+				AllowHeaders: []string{"Authorization", "Content-Type", "X-Debug", "Idempotency-Key"},
+				ExposeHeaders: []string{
+					echo.HeaderXRequestID, echo.HeaderRetryAfter, "Idempotent-Replayed",
+					"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy",
+				},
+				MaxAge: 600,
+			},
+			SecureHeaders: server.DefaultSecureHeaders(),
+			BodyLimit:     server.DefaultBodyLimit(),
+			RequestTimeout: server.TimeoutConfig{
+				Duration: 30 * time.Second,
+				// profiles and traces last as long as requested.
+				Skip: []string{"/cms/_admin/pprof/*"},
+			},
+			DrainDelay: 5 * time.Second,
 		},
 		PGDB: db.PostgresConfig{
 			Host:     "localhost",
@@ -66,6 +222,11 @@ func C() *Config {
 				"timezone":        "UTC",
 				"connect_timeout": "10",
 			},
+			SlowThreshold:   200 * time.Millisecond,
+			MaxOpenConns:    20,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
 		},
 		CMSSession: Session{
 			TTL:        12 * time.Hour,
@@ -73,7 +234,82 @@ func C() *Config {
 			RefreshTTL: 7 * 24 * time.Hour,
 			RefreshKey: []byte("WN*@?5{9wltC)?!^/}mVv2UM?KExuBQ6"),
 		},
+		CMSMFA: MFA{
+			Issuer:            "portfolio-server",
+			ChallengeTTL:      5 * time.Minute,
+			ChallengeKey:      []byte("p3;Xk!9vR#tq_Lw2@Zc8^Hn$Ue6*Jm0F"),
+			RecoveryCodeCount: 10,
+			MaxAttempts:       5,
+			Lockout:           15 * time.Minute,
+		},
+		CMSOnboarding: Onboarding{
+			BaseURL:          "http://localhost:4000",
+			InvitationTTL:    72 * time.Hour,
+			PasswordResetTTL: time.Hour,
+		},
+		Mailer: mailer.Config{
+			Driver: mailer.DriverFile,
+			From:   "Portfolio CMS <no-reply@localhost>",
+		},
+		Cache: cache.Config{
+			Driver:      cache.DriverMemory,
+			Prefix:      "portfolio:",
+			DefaultTTL:  5 * time.Minute,
+			LRUSize:     1024,
+			FreshWindow: 10 * time.Second,
+		},
+		Outbox: Outbox{
+			PollInterval: 5 * time.Second,
+			BatchSize:    50,
+			Lease:        time.Minute,
+			MaxAttempts:  10,
+			MinBackoff:   time.Second,
+			MaxBackoff:   time.Hour,
+		},
+		Log: logging.Config{
+			Level:  slog.LevelInfo,
+			Format: logging.FormatPretty,
+		},
+		Metrics: Metrics{
+			Namespace: "portfolio-server",
+		},
+		Tracing: tracing.Config{
+			Exporter:    tracing.ExporterNone,
+			ServiceName: "portfolio-server",
+			SampleRatio: 1,
+		},
+		RateLimit: ratelimit.Config{
+			Driver: ratelimit.DriverMemory,
+			Prefix: "ratelimit:",
+		},
+		Idempotency: idempotency.Config{
+			TTL:   24 * time.Hour,
+			Lease: time.Minute,
+		},
+		ShutdownTimeout:      25 * time.Second,
+		ShutdownCloseTimeout: 5 * time.Second,
+		Admin: Admin{
+			Enabled: IsLocal(),
+		},
+		ConfigReload: Reload{
+			FileInterval: 5 * time.Second,
+			PollInterval: 5 * time.Minute,
+		},
 	}
+
+	if IsInAWSLambda() {
+		// the logs are read by cloudwatch.
+		c.Log.Format = logging.FormatJson
+
+		// a function instance serves one request at a time, a tiny pool keeps
+		// the connections of the concurrent instances within the server limit.
+		c.PGDB.MaxOpenConns = 2
+		c.PGDB.MaxIdleConns = 2
+
+		// the limits hold across the function instances.
+		c.RateLimit.Driver = ratelimit.DriverPostgres
+	}
+	return c
 }
 
 // IsLocal indicates if the server is running locally.
@@ -89,45 +325,82 @@ func GetStage() string {
 	return stage
 }
 
-// Load loads the configuration from environment variables and
-// parameter store.
-func Load(envFiles ...string) (*Config, error) {
-	c := C()
+// Load loads the configuration from the default sources and validates it.
+func Load(ctx context.Context, envFiles ...string) (*Config, error) {
+	c, _, err := Read(ctx, envFiles...)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
 
-	if IsLocal() {
-		if err := LoadLocalConfig(c, envFiles...); err != nil {
-			return nil, err
+// Read loads the configuration from the default sources without validating
+// it, along with the source of each value.
+func Read(ctx context.Context, envFiles ...string) (*Config, Provenance, error) {
+	sources, err := Sources(ctx, envFiles...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ReadFrom(ctx, sources...)
+}
+
+// Sources returns the default sources by increasing precedence:
+//   - the .env files, which must exist locally and are skipped otherwise
+//     when missing.
+//   - the environment.
+//   - Parameter Store under PARAMETER_STORE_PATH when set. The parameters
+//     are read from the JSON file PARAMETER_STORE_FILE instead of AWS when
+//     set, see ssmfake.
+//   - the Secrets Manager secret SECRETS_MANAGER_SECRET_ID when set.
+func Sources(ctx context.Context, envFiles ...string) ([]Source, error) {
+	if len(envFiles) == 0 {
+		envFiles = []string{".env"}
+	}
+
+	var sources []Source
+	for _, f := range envFiles {
+		if _, err := os.Stat(f); err != nil && !IsLocal() {
+			continue
 		}
+		sources = append(sources, File(f))
+	}
+	sources = append(sources, Env())
 
-		return c, nil
+	var (
+		paramPath = os.Getenv("PARAMETER_STORE_PATH")
+		paramFile = os.Getenv("PARAMETER_STORE_FILE")
+		secretID  = os.Getenv("SECRETS_MANAGER_SECRET_ID")
+	)
+	if paramPath == "" && secretID == "" {
+		return sources, nil
 	}
 
-	if parameterStorePath != "" {
-		secrets := map[string]string{}
-		if err := LoadFromAPS(os.Getenv("PARAMETER_STORE_PATH"), secrets, nil); err != nil {
+	var awsCfg aws.Config
+	if (paramPath != "" && paramFile == "") || secretID != "" {
+		var err error
+		if awsCfg, err = awscfg.LoadDefaultConfig(ctx); err != nil {
 			return nil, err
 		}
+	}
 
-		for k, v := range secrets {
-			if err := os.Setenv(k, v); err != nil {
+	if paramPath != "" {
+		var client ssm.GetParametersByPathAPIClient
+		if paramFile != "" {
+			store, err := ssmfake.Open(paramFile)
+			if err != nil {
 				return nil, err
 			}
+			client = store
+		} else {
+			client = ssm.NewFromConfig(awsCfg)
 		}
+		sources = append(sources, ParameterStore(client, paramPath))
 	}
-
-	if err := envconfig.Process("", c); err != nil {
-		return nil, err
-	}
-
-	return c, nil
-}
-
-// LoadLocalConfig loads the configuration from environment variables.
-func LoadLocalConfig(cfg *Config, customPaths ...string) error {
-	if err := godotenv.Load(customPaths...); err != nil {
-		return err
+	if secretID != "" {
+		sources = append(sources, SecretsManager(secretsmanager.NewFromConfig(awsCfg), secretID))
 	}
-
-	// load from environment variables.
-	return envconfig.Process("", cfg)
+	return sources, nil
 }
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WriteExample writes a .env template of every environment variable of the
+// config, with its type and default value, grouped by section. The secrets
+// are left empty.
+func WriteExample(w io.Writer) error {
+	var (
+		bw      = bufio.NewWriter(w)
+		section string
+	)
+
+	fmt.Fprintln(bw, "# Generated by `api -example`, the values are the built-in defaults.")
+	C().walk(func(v envVar) {
+		if v.section.Name != section {
+			section = v.section.Name
+			fmt.Fprintln(bw)
+			if desc := v.section.Tag.Get("desc"); desc != "" {
+				fmt.Fprintf(bw, "# %s.\n", desc)
+			}
+		}
+
+		value := formatVar(v.value)
+		notes := []string{typeName(v.field.Type)}
+		if strings.Contains(v.field.Tag.Get("validate"), "required") {
+			notes = append(notes, "required")
+		}
+		if IsSecret(v.name) {
+			notes = append(notes, "secret")
+			value = ""
+		}
+		fmt.Fprintf(bw, "# %s\n%s=%s\n", strings.Join(notes, ", "), v.name, quoteVar(value))
+	})
+	return bw.Flush()
+}
+
+// WriteDump writes the values of the config as a .env file, with the secrets
+// redacted. The values are grouped by the source which set them, defaults
+// first, and sorted by environment variable.
+func (c *Config) WriteDump(w io.Writer, origin Provenance) error {
+	var (
+		bw       = bufio.NewWriter(w)
+		vars     = c.Redacted()
+		bySource = map[string][]string{}
+		sources  []string
+	)
+	for name := range vars {
+		src := origin.Of(name)
+		if _, ok := bySource[src]; !ok {
+			sources = append(sources, src)
+		}
+		bySource[src] = append(bySource[src], name)
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		if (sources[i] == SourceDefault) != (sources[j] == SourceDefault) {
+			return sources[i] == SourceDefault
+		}
+		return sources[i] < sources[j]
+	})
+
+	for i, src := range sources {
+		if i > 0 {
+			fmt.Fprintln(bw)
+		}
+		fmt.Fprintf(bw, "# %s\n", src)
+
+		names := bySource[src]
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(bw, "%s=%s\n", name, quoteVar(vars[name]))
+		}
+	}
+	return bw.Flush()
+}
+
+// quoteVar quotes the values which godotenv would not read back as they are.
+func quoteVar(v string) string {
+	if strings.ContainsAny(v, " #'\"\\") {
+		return strconv.Quote(v)
+	}
+	return v
+}
+
+// typeName describes the type of a variable as envconfig parses it.
+func typeName(t reflect.Type) string {
+	switch {
+	case t == durationType:
+		return "duration"
+	case isScalar(t), reflect.PointerTo(t).Implements(decoderType):
+		return "string"
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "int"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "string"
+		}
+		return "list of " + typeName(t.Elem())
+	case reflect.Map:
+		return "list of key:value"
+	}
+	return "string"
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/joho/godotenv"
+)
+
+// Source provides values of the environment variables of the config.
+type Source interface {
+	// Name identifies the source in the provenance of the values.
+	Name() string
+	Values(ctx context.Context) (map[string]string, error)
+}
+
+// SourceDefault is the provenance of the values which no source set.
+const SourceDefault = "default"
+
+// Provenance maps the environment variables of the config to the name of the
+// source which set them.
+type Provenance map[string]string
+
+// Of returns the name of the source which set the variable.
+func (p Provenance) Of(name string) string {
+	if s, ok := p[name]; ok {
+		return s
+	}
+	return SourceDefault
+}
+
+// sourceFunc is a source reading its values with a func.
+type sourceFunc struct {
+	name   string
+	values func(ctx context.Context) (map[string]string, error)
+}
+
+func (s *sourceFunc) Name() string {
+	return s.name
+}
+
+func (s *sourceFunc) Values(ctx context.Context) (map[string]string, error) {
+	return s.values(ctx)
+}
+
+// fileSource is a source reading a .env file.
+type fileSource struct {
+	path string
+}
+
+// File returns the source reading the .env file.
+func File(path string) Source {
+	return &fileSource{path: path}
+}
+
+func (s *fileSource) Name() string {
+	return "file:" + s.path
+}
+
+func (s *fileSource) Values(context.Context) (map[string]string, error) {
+	return godotenv.Read(s.path)
+}
+
+// modTime returns the time of the last change of the file, zero when it is
+// missing.
+func (s *fileSource) modTime() time.Time {
+	fi, err := os.Stat(s.path)
+	if err != nil {
+		return time.Time{}
+	}
+	return fi.ModTime()
+}
+
+// Env returns the source reading the environment of the process.
+func Env() Source {
+	return &sourceFunc{
+		name: "env",
+		values: func(context.Context) (map[string]string, error) {
+			vars := map[string]string{}
+			for _, kv := range os.Environ() {
+				if k, v, ok := strings.Cut(kv, "="); ok {
+					vars[k] = v
+				}
+			}
+			return vars, nil
+		},
+	}
+}
+
+// ParameterStore returns the source reading the parameters under path, named
+// after the variables they set, e.g. /portfolio-server/dev/PGDB_PASSWORD.
+func ParameterStore(client ssm.GetParametersByPathAPIClient, path string) Source {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if !strings.HasSuffix(path, "/") {
+		path = path + "/"
+	}
+
+	return &sourceFunc{
+		name: "parameter-store:" + path,
+		values: func(ctx context.Context) (map[string]string, error) {
+			vars := map[string]string{}
+			p := ssm.NewGetParametersByPathPaginator(client, &ssm.GetParametersByPathInput{
+				Path:           aws.String(path),
+				WithDecryption: aws.Bool(true),
+			})
+			for p.HasMorePages() {
+				res, err := p.NextPage(ctx)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read parameters of %s: %w", path, err)
+				}
+				for _, param := range res.Parameters {
+					name := strings.TrimPrefix(aws.ToString(param.Name), path)
+					vars[strings.ToUpper(name)] = aws.ToString(param.Value)
+				}
+			}
+			return vars, nil
+		},
+	}
+}
+
+// SecretsManagerAPI is the part of the Secrets Manager client read by the
+// SecretsManager source.
+type SecretsManagerAPI interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// SecretsManager returns the source reading the secret, a JSON object of the
+// variables it sets.
+func SecretsManager(client SecretsManagerAPI, secretID string) Source {
+	return &sourceFunc{
+		name: "secrets-manager:" + secretID,
+		values: func(ctx context.Context) (map[string]string, error) {
+			res, err := client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+				SecretId: aws.String(secretID),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to read secret %s: %w", secretID, err)
+			}
+
+			secret := map[string]string{}
+			if err := json.Unmarshal([]byte(aws.ToString(res.SecretString)), &secret); err != nil {
+				return nil, fmt.Errorf("secret %s is not a JSON object of strings: %w", secretID, err)
+			}
+
+			vars := make(map[string]string, len(secret))
+			for k, v := range secret {
+				vars[strings.ToUpper(k)] = v
+			}
+			return vars, nil
+		},
+	}
+}
+
+// ReadFrom loads the configuration from the sources, each of them taking
+// precedence over the previous ones and all of them over the defaults. Only
+// the variables of the config are read, and the environment of the process is
+// left untouched so that the secrets do not leak to it.
+func ReadFrom(ctx context.Context, sources ...Source) (*Config, Provenance, error) {
+	var (
+		c      = C()
+		vars   = c.Vars()
+		origin = Provenance{}
+		errs   []error
+	)
+
+	for _, s := range sources {
+		values, err := s.Values(ctx)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for k, v := range values {
+			if _, ok := vars[k]; ok {
+				vars[k] = v
+				origin[k] = s.Name()
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, nil, errors.Join(errs...)
+	}
+
+	if err := c.setVars(vars); err != nil {
+		return nil, nil, err
+	}
+	return c, origin, nil
+}
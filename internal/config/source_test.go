@@ -0,0 +1,144 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+)
+
+// staticSource is a source of fixed values.
+func staticSource(name string, values map[string]string) Source {
+	return &sourceFunc{
+		name: name,
+		values: func(context.Context) (map[string]string, error) {
+			return values, nil
+		},
+	}
+}
+
+func TestReadFrom(t *testing.T) {
+	failing := &sourceFunc{
+		name: "failing",
+		values: func(context.Context) (map[string]string, error) {
+			return nil, errors.New("access denied")
+		},
+	}
+
+	tests := []struct {
+		name    string
+		sources []Source
+		// want maps the variables to their value and provenance.
+		want    map[string][2]string
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			want: map[string][2]string{
+				"HTTP_SERVER_PORT": {"3000", SourceDefault},
+				"LOG_LEVEL":        {C().Vars()["LOG_LEVEL"], SourceDefault},
+			},
+		},
+		{
+			name: "later sources take precedence",
+			sources: []Source{
+				staticSource("file", map[string]string{"HTTP_SERVER_PORT": "4000", "PGDB_HOST": "db"}),
+				staticSource("env", map[string]string{"HTTP_SERVER_PORT": "5000"}),
+			},
+			want: map[string][2]string{
+				"HTTP_SERVER_PORT": {"5000", "env"},
+				"PGDB_HOST":        {"db", "file"},
+				"PGDB_PORT":        {C().Vars()["PGDB_PORT"], SourceDefault},
+			},
+		},
+		{
+			name: "unknown variables are ignored",
+			sources: []Source{
+				staticSource("env", map[string]string{"PATH": "/bin", "HTTP_SERVER_NOPE": "1"}),
+			},
+			want: map[string][2]string{
+				"HTTP_SERVER_PORT": {"3000", SourceDefault},
+			},
+		},
+		{
+			name: "nested variables do not fall back to the unprefixed ones",
+			sources: []Source{
+				staticSource("env", map[string]string{"LOG_LEVEL": "WARN"}),
+			},
+			want: map[string][2]string{
+				"LOG_LEVEL":      {"WARN", "env"},
+				"PGDB_LOG_LEVEL": {C().Vars()["PGDB_LOG_LEVEL"], SourceDefault},
+			},
+		},
+		{
+			name: "lists, maps and durations",
+			sources: []Source{
+				staticSource("secrets", map[string]string{
+					"HTTP_SERVER_TRUSTED_PROXIES": "10.0.0.0/8,192.0.2.1",
+					"RATE_LIMIT_POLICIES":         "public:100/1m,login:5/15m",
+					"SHUTDOWN_TIMEOUT":            "40s",
+				}),
+			},
+			want: map[string][2]string{
+				"HTTP_SERVER_TRUSTED_PROXIES": {"10.0.0.0/8,192.0.2.1", "secrets"},
+				"RATE_LIMIT_POLICIES":         {"login:5/15m0s,public:100/1m0s", "secrets"},
+				"SHUTDOWN_TIMEOUT":            {"40s", "secrets"},
+			},
+		},
+		{
+			name: "invalid value",
+			sources: []Source{
+				staticSource("env", map[string]string{"HTTP_SERVER_PORT": "http"}),
+			},
+			wantErr: true,
+		},
+		{
+			name:    "failing source",
+			sources: []Source{staticSource("env", nil), failing},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, origin, err := ReadFrom(context.Background(), tt.sources...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadFrom() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			vars := c.Vars()
+			if len(tt.sources) == 0 {
+				// the defaults are parsed back as they are formatted.
+				for name, want := range C().Vars() {
+					if got := vars[name]; got != want {
+						t.Errorf("%s = %q, want the default %q", name, got, want)
+					}
+				}
+			}
+			for name, want := range tt.want {
+				if got := vars[name]; got != want[0] {
+					t.Errorf("%s = %q, want %q", name, got, want[0])
+				}
+				if got := origin.Of(name); got != want[1] {
+					t.Errorf("%s set by %q, want %q", name, got, want[1])
+				}
+			}
+		})
+	}
+}
+
+func TestReadFromLeavesTheEnvironment(t *testing.T) {
+	const name = "PGDB_PASSWORD"
+	t.Setenv(name, "")
+	os.Unsetenv(name)
+
+	_, _, err := ReadFrom(context.Background(), staticSource("secrets", map[string]string{name: "s3cret"}))
+	if err != nil {
+		t.Fatalf("ReadFrom() error = %v", err)
+	}
+	if v, ok := os.LookupEnv(name); ok {
+		t.Errorf("%s exported as %q, want it unset", name, v)
+	}
+}
@@ -0,0 +1,102 @@
+// Package ssmfake is a file-backed stand-in of Parameter Store, so that the
+// config of the deployed stages can be loaded locally and in CI without AWS.
+package ssmfake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// maxResults is the default and maximum page size of Parameter Store.
+const maxResults = 10
+
+// ParameterStore serves parameters by path like Parameter Store. It
+// implements ssm.GetParametersByPathAPIClient.
+type ParameterStore struct {
+	params map[string]string
+	names  []string
+}
+
+// New creates the store of the parameters, by name.
+func New(params map[string]string) *ParameterStore {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return &ParameterStore{
+		params: params,
+		names:  names,
+	}
+}
+
+// Open creates the store of the parameters of the JSON file, an object
+// mapping their names to their values, e.g.
+//
+//	{"/portfolio-server/dev/PGDB_PASSWORD": "secret"}
+func Open(path string) (*ParameterStore, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	params := map[string]string{}
+	if err := json.Unmarshal(b, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse parameters of %s: %w", path, err)
+	}
+	return New(params), nil
+}
+
+// GetParametersByPath returns the parameters under the path, its direct
+// children only unless the request is recursive. Pages have up to 10
+// parameters.
+func (s *ParameterStore) GetParametersByPath(_ context.Context, in *ssm.GetParametersByPathInput, _ ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error) {
+	path := aws.ToString(in.Path)
+	if !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+
+	size := int(aws.ToInt32(in.MaxResults))
+	if size <= 0 || size > maxResults {
+		size = maxResults
+	}
+	start := 0
+	if t := aws.ToString(in.NextToken); t != "" {
+		var err error
+		if start, err = strconv.Atoi(t); err != nil {
+			return nil, fmt.Errorf("invalid next token %q", t)
+		}
+	}
+
+	var matched []string
+	for _, name := range s.names {
+		rest, ok := strings.CutPrefix(name, path)
+		if !ok || (!aws.ToBool(in.Recursive) && strings.Contains(rest, "/")) {
+			continue
+		}
+		matched = append(matched, name)
+	}
+
+	out := &ssm.GetParametersByPathOutput{}
+	for i := start; i < len(matched) && i < start+size; i++ {
+		out.Parameters = append(out.Parameters, types.Parameter{
+			Name:  aws.String(matched[i]),
+			Value: aws.String(s.params[matched[i]]),
+			Type:  types.ParameterTypeSecureString,
+		})
+	}
+	if next := start + size; next < len(matched) {
+		out.NextToken = aws.String(strconv.Itoa(next))
+	}
+	return out, nil
+}
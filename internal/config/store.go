@@ -0,0 +1,191 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Snapshot is a version of the config.
+type Snapshot struct {
+	// Version starts at 1 and increases with every applied reload.
+	Version  uint64
+	Config   *Config
+	Origin   Provenance
+	LoadedAt time.Time
+}
+
+// subscription is a func notified of the snapshots which change a variable
+// starting with one of its prefixes.
+type subscription struct {
+	prefixes []string
+	fn       func(s *Snapshot)
+}
+
+func (s *subscription) matches(name string) bool {
+	for _, p := range s.prefixes {
+		if strings.HasPrefix(name, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Store holds the current snapshot of the config and replaces it when the
+// sources change. The snapshots are never modified, the components which can
+// apply a change without a restart subscribe to the variables they read.
+type Store struct {
+	current atomic.Pointer[Snapshot]
+	sources []Source
+
+	mu   sync.Mutex
+	subs []subscription
+}
+
+// NewStore creates the store with the loaded config, which is reloaded from
+// the sources.
+func NewStore(c *Config, origin Provenance, sources ...Source) *Store {
+	s := &Store{sources: sources}
+	s.current.Store(&Snapshot{
+		Version:  1,
+		Config:   c,
+		Origin:   origin,
+		LoadedAt: time.Now(),
+	})
+	return s
+}
+
+// Current returns the current snapshot.
+func (s *Store) Current() *Snapshot {
+	return s.current.Load()
+}
+
+// Subscribe calls fn with the new snapshot whenever a reload changes a
+// variable starting with one of the prefixes, e.g. CMS_SESSION_. The changes
+// no subscriber applies are logged as requiring a restart.
+func (s *Store) Subscribe(fn func(s *Snapshot), prefixes ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subs = append(s.subs, subscription{prefixes: prefixes, fn: fn})
+}
+
+// Reload reads the sources again and swaps the snapshot when the config
+// changed. An invalid config is rejected and the current snapshot is kept. It
+// reports whether a new snapshot was applied.
+func (s *Store) Reload(ctx context.Context) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, origin, err := ReadFrom(ctx, s.sources...)
+	if err == nil {
+		err = c.Validate()
+	}
+	cur := s.current.Load()
+	if err != nil {
+		slog.Error("rejected config reload, the current config is kept", "version", cur.Version, "error", err)
+		return false, err
+	}
+
+	var (
+		prev    = cur.Config.Vars()
+		next    = c.Vars()
+		changed []string
+	)
+	for name, v := range next {
+		if prev[name] != v {
+			changed = append(changed, name)
+		}
+	}
+	if len(changed) == 0 {
+		return false, nil
+	}
+	slices.Sort(changed)
+
+	snap := &Snapshot{
+		Version:  cur.Version + 1,
+		Config:   c,
+		Origin:   origin,
+		LoadedAt: time.Now(),
+	}
+	s.current.Store(snap)
+	slog.Info("reloaded config", "version", snap.Version, "changed", changed)
+
+	applied := map[string]bool{}
+	for _, sub := range s.subs {
+		notify := false
+		for _, name := range changed {
+			if sub.matches(name) {
+				applied[name] = true
+				notify = true
+			}
+		}
+		if notify {
+			sub.fn(snap)
+		}
+	}
+
+	var restart []string
+	for _, name := range changed {
+		if !applied[name] {
+			restart = append(restart, name)
+		}
+	}
+	if len(restart) > 0 {
+		slog.Warn("config changes require a restart", "version", snap.Version, "vars", restart)
+	}
+	return true, nil
+}
+
+// Watch reloads the config when one of its .env files changes, checked every
+// FileInterval, and every PollInterval, until ctx is done.
+func (s *Store) Watch(ctx context.Context, cfg Reload) error {
+	var (
+		files    []*fileSource
+		modTimes = map[*fileSource]time.Time{}
+	)
+	for _, src := range s.sources {
+		if f, ok := src.(*fileSource); ok {
+			files = append(files, f)
+			modTimes[f] = f.modTime()
+		}
+	}
+
+	fileTick := ticker(cfg.FileInterval)
+	if len(files) == 0 {
+		fileTick = nil
+	}
+	pollTick := ticker(cfg.PollInterval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-fileTick:
+			changed := false
+			for _, f := range files {
+				if t := f.modTime(); !t.Equal(modTimes[f]) {
+					modTimes[f] = t
+					changed = true
+				}
+			}
+			if !changed {
+				continue
+			}
+		case <-pollTick:
+		}
+		// the failures are logged by Reload.
+		_, _ = s.Reload(ctx)
+	}
+}
+
+// ticker ticks every d, or never when d is not positive.
+func ticker(d time.Duration) <-chan time.Time {
+	if d <= 0 {
+		return nil
+	}
+	return time.Tick(d)
+}
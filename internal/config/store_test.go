@@ -0,0 +1,88 @@
+package config
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStoreReload(t *testing.T) {
+	tests := []struct {
+		name        string
+		values      map[string]string
+		wantApplied bool
+		wantErr     bool
+		// wantNotified is whether the subscriber of CMS_SESSION_ is notified.
+		wantNotified bool
+	}{
+		{
+			name:   "unchanged",
+			values: map[string]string{},
+		},
+		{
+			name:         "changed",
+			values:       map[string]string{"CMS_SESSION_TTL": "1h0m0s"},
+			wantApplied:  true,
+			wantNotified: true,
+		},
+		{
+			name:        "changed without subscriber",
+			values:      map[string]string{"LOG_FORMAT": "json"},
+			wantApplied: true,
+		},
+		{
+			name:    "unparsable value",
+			values:  map[string]string{"CMS_SESSION_TTL": "soon"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid config",
+			values:  map[string]string{"CMS_SESSION_KEY": "too-short"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid previous key",
+			values:  map[string]string{"CMS_SESSION_PREVIOUS_KEY": "too-short"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, origin, err := ReadFrom(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := NewStore(c, origin, staticSource("env", tt.values))
+			cur := s.Current()
+
+			notified := false
+			s.Subscribe(func(*Snapshot) { notified = true }, "CMS_SESSION_")
+
+			applied, err := s.Reload(context.Background())
+			if (err != nil) != tt.wantErr || applied != tt.wantApplied {
+				t.Fatalf("Reload() = %v, %v, want %v, error %v", applied, err, tt.wantApplied, tt.wantErr)
+			}
+			if notified != tt.wantNotified {
+				t.Errorf("subscriber notified = %v, want %v", notified, tt.wantNotified)
+			}
+
+			next := s.Current()
+			if !tt.wantApplied {
+				if next != cur {
+					t.Errorf("snapshot replaced by version %d, want version %d kept", next.Version, cur.Version)
+				}
+				return
+			}
+			if next.Version != cur.Version+1 {
+				t.Errorf("version = %d, want %d", next.Version, cur.Version+1)
+			}
+			for name, v := range tt.values {
+				if got := next.Config.Vars()[name]; got != v {
+					t.Errorf("%s = %q, want %q", name, got, v)
+				}
+				if got := next.Origin.Of(name); got != "env" {
+					t.Errorf("%s set by %q, want env", name, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/gommon/bytes"
+)
+
+// validate checks the config against the validate tags of its fields, which
+// are named by environment variable.
+var validate = func() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		return f.Tag.Get("envconfig")
+	})
+	// enum checks the values of the go-enum types.
+	_ = v.RegisterValidation("enum", func(fl validator.FieldLevel) bool {
+		e, ok := fl.Field().Interface().(interface{ IsValid() bool })
+		return !ok || e.IsValid()
+	})
+	// bytesize checks the sizes of the body limits, which panic on an invalid
+	// one.
+	_ = v.RegisterValidation("bytesize", func(fl validator.FieldLevel) bool {
+		_, err := bytes.Parse(fl.Field().String())
+		return err == nil
+	})
+	return v
+}()
+
+// placeholderPrefix starts the placeholders of the keys in .env.example.
+const placeholderPrefix = "change-me"
+
+// Validate returns every problem of the config at once.
+//
+// Outside local, the keys must not keep the built-in defaults nor the
+// placeholders of .env.example since these are public.
+func (c *Config) Validate() error {
+	var errs []error
+
+	var verrs validator.ValidationErrors
+	if err := validate.Struct(c); errors.As(err, &verrs) {
+		for _, fe := range verrs {
+			errs = append(errs, fmt.Errorf("%s %s", varName(fe.Namespace()), describe(fe)))
+		}
+	} else if err != nil {
+		return err
+	}
+
+	if d := c.HTTPServer.DrainDelay; c.ShutdownTimeout > c.ShutdownCloseTimeout && d >= c.DrainTimeout() {
+		errs = append(errs, fmt.Errorf("HTTP_SERVER_DRAIN_DELAY must be below SHUTDOWN_TIMEOUT minus SHUTDOWN_CLOSE_TIMEOUT, %s", c.DrainTimeout()))
+	}
+
+	if !IsLocal() {
+		var (
+			defaults = C().Vars()
+			vars     = c.Vars()
+			names    []string
+		)
+		for name, v := range vars {
+			if strings.HasSuffix(name, "_KEY") && v != "" && (v == defaults[name] || strings.HasPrefix(v, placeholderPrefix)) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			errs = append(errs, fmt.Errorf("%s must be set, the built-in default and the example placeholder are only meant for local", name))
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid config: %w", errors.Join(errs...))
+}
+
+// varName returns the environment variable of the namespace of a field, e.g.
+// Config.HTTP_SERVER.PORT is HTTP_SERVER_PORT.
+func varName(namespace string) string {
+	_, name, _ := strings.Cut(namespace, ".")
+	return strings.ReplaceAll(name, ".", "_")
+}
+
+// siblingVar returns the environment variable of the field named by the
+// param of a cross-field validation.
+func siblingVar(fe validator.FieldError) string {
+	var (
+		t     = reflect.TypeFor[Config]()
+		path  = strings.Split(fe.StructNamespace(), ".")
+		names = strings.Split(fe.Namespace(), ".")
+	)
+	for _, name := range path[1 : len(path)-1] {
+		f, _ := t.FieldByName(name)
+		t = f.Type
+	}
+
+	f, ok := t.FieldByName(fe.Param())
+	if !ok {
+		return fe.Param()
+	}
+	names[len(names)-1] = f.Tag.Get("envconfig")
+	return varName(strings.Join(names, "."))
+}
+
+func describe(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required", "required_without":
+		return "is required"
+	case "required_with":
+		return "is required with " + siblingVar(fe)
+	case "enum":
+		return fmt.Sprintf("has an unsupported value %q", fe.Value())
+	case "min", "gte":
+		if k := fe.Kind(); k == reflect.Slice || k == reflect.String {
+			return fmt.Sprintf("must be at least %s long", fe.Param())
+		}
+		return "must be at least " + fe.Param()
+	case "max", "lte":
+		return "must be at most " + fe.Param()
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "gtefield":
+		return "must not be less than " + siblingVar(fe)
+	case "ltfield":
+		return "must be less than " + siblingVar(fe)
+	case "bytesize":
+		return fmt.Sprintf("has an invalid size %q, e.g. 1M", fe.Value())
+	case "url":
+		return "must be a URL"
+	case "email":
+		return "must be an email"
+	case "cidr|ip":
+		return fmt.Sprintf("has an invalid CIDR range %q", fe.Value())
+	}
+	if p := fe.Param(); p != "" {
+		return fmt.Sprintf("fails the %s=%s check", fe.Tag(), p)
+	}
+	return fmt.Sprintf("fails the %s check", fe.Tag())
+}
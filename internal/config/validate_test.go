@@ -0,0 +1,79 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		stage   string
+		mutate  func(c *Config)
+		wantErr string
+	}{
+		{
+			name:   "defaults",
+			mutate: func(*Config) {},
+		},
+		{
+			name:   "body limits",
+			mutate: func(c *Config) { c.HTTPServer.BodyLimit.Routes = map[string]string{"/cms/assets": "20MB"} },
+		},
+		{
+			name:    "invalid default body limit",
+			mutate:  func(c *Config) { c.HTTPServer.BodyLimit.Default = "1 megabyte" },
+			wantErr: "HTTP_SERVER_BODY_LIMIT_DEFAULT has an invalid size",
+		},
+		{
+			name:    "invalid route body limit",
+			mutate:  func(c *Config) { c.HTTPServer.BodyLimit.Routes = map[string]string{"/cms/assets": "20MiBs"} },
+			wantErr: "HTTP_SERVER_BODY_LIMIT_ROUTES[/cms/assets] has an invalid size",
+		},
+		{
+			name:    "default key outside local",
+			stage:   "prod",
+			mutate:  func(c *Config) { c.CMSSession.RefreshKey = []byte("a-real-random-32-byte-refresh-key!") },
+			wantErr: "CMS_SESSION_KEY must be set",
+		},
+		{
+			name:  "example placeholder outside local",
+			stage: "prod",
+			mutate: func(c *Config) {
+				c.CMSSession.Key = []byte("change-me-to-a-random-32-byte-session-key")
+			},
+			wantErr: "CMS_SESSION_KEY must be set",
+		},
+		{
+			name: "example placeholder locally",
+			mutate: func(c *Config) {
+				c.CMSSession.Key = []byte("change-me-to-a-random-32-byte-session-key")
+			},
+		},
+		{
+			name:    "drain delay over the drain budget",
+			mutate:  func(c *Config) { c.HTTPServer.DrainDelay = c.DrainTimeout() + time.Second },
+			wantErr: "HTTP_SERVER_DRAIN_DELAY must be below",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(s string) { stage = s }(stage)
+			stage = tt.stage
+
+			c := C()
+			tt.mutate(c)
+			err := c.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
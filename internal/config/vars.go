@@ -0,0 +1,276 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// redacted replaces the values of the secrets.
+const redacted = "[REDACTED]"
+
+// secretVar matches the environment variables holding secrets.
+var secretVar = regexp.MustCompile(`(PASSWORD|SECRET|TOKEN|KEY|DSNS?)$`)
+
+// IsSecret reports whether the environment variable holds a secret.
+func IsSecret(name string) bool {
+	return secretVar.MatchString(name)
+}
+
+// Redacted returns the values of the config by environment variable, with the
+// secrets replaced.
+func (c *Config) Redacted() map[string]string {
+	vars := c.Vars()
+	for k, v := range vars {
+		if v != "" && IsSecret(k) {
+			vars[k] = redacted
+		}
+	}
+	return vars
+}
+
+// Vars returns the values of the config by environment variable, formatted as
+// envconfig parses them.
+func (c *Config) Vars() map[string]string {
+	vars := map[string]string{}
+	c.walk(func(v envVar) {
+		vars[v.name] = formatVar(v.value)
+	})
+	return vars
+}
+
+// setVars sets the fields of the config from the values by environment
+// variable, parsed the way envconfig parses them. The fields without a value
+// are left as they are.
+func (c *Config) setVars(vars map[string]string) error {
+	var errs []error
+	c.walk(func(v envVar) {
+		s, ok := vars[v.name]
+		if !ok {
+			return
+		}
+		if err := parseVar(v.value, s); err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse %s: %w", v.name, err))
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// envVar is a field of the config set by an environment variable.
+type envVar struct {
+	name    string
+	field   reflect.StructField
+	value   reflect.Value
+	section reflect.StructField // the field of Config holding it.
+}
+
+// walk calls fn with the environment variables of the config in the order of
+// the fields.
+func (c *Config) walk(fn func(v envVar)) {
+	walkVars("", reflect.ValueOf(c).Elem(), nil, fn)
+}
+
+var (
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	stringerType      = reflect.TypeFor[fmt.Stringer]()
+	durationType      = reflect.TypeFor[time.Duration]()
+	decoderType       = reflect.TypeFor[envconfig.Decoder]()
+)
+
+func walkVars(prefix string, v reflect.Value, section *reflect.StructField, fn func(v envVar)) {
+	t := v.Type()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("envconfig")
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+		if tag == "" {
+			tag = strings.ToUpper(f.Name)
+		}
+
+		name := tag
+		if prefix != "" {
+			name = prefix + "_" + tag
+		}
+
+		sec := section
+		if sec == nil {
+			sec = &f
+		}
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct && !isScalar(fv.Type()) {
+			walkVars(name, fv, sec, fn)
+			continue
+		}
+		fn(envVar{name: name, field: f, value: fv, section: *sec})
+	}
+}
+
+func isScalar(t reflect.Type) bool {
+	return t == durationType ||
+		t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) ||
+		t.Implements(stringerType)
+}
+
+// formatVar formats v the way envconfig parses it.
+func formatVar(v reflect.Value) string {
+	if v.Type() == durationType {
+		return v.Interface().(time.Duration).String()
+	}
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		if err == nil {
+			return string(b)
+		}
+	}
+	if v.CanAddr() {
+		if m, ok := v.Addr().Interface().(encoding.TextMarshaler); ok {
+			if b, err := m.MarshalText(); err == nil {
+				return string(b)
+			}
+		}
+		// the custom decoders format their values with String.
+		if s, ok := v.Interface().(fmt.Stringer); ok && v.Addr().Type().Implements(decoderType) {
+			return s.String()
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes())
+		}
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = formatVar(v.Index(i))
+		}
+		return strings.Join(items, ",")
+	case reflect.Map:
+		items := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			items = append(items, formatVar(k)+":"+formatVar(v.MapIndex(k)))
+		}
+		sort.Strings(items)
+		return strings.Join(items, ",")
+	case reflect.Pointer:
+		if v.IsNil() {
+			return ""
+		}
+		return formatVar(v.Elem())
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// parseVar sets v from s the way envconfig does.
+func parseVar(v reflect.Value, s string) error {
+	if v.CanAddr() {
+		switch u := v.Addr().Interface().(type) {
+		case envconfig.Decoder:
+			return u.Decode(s)
+		case envconfig.Setter:
+			return u.Set(s)
+		case encoding.TextUnmarshaler:
+			return u.UnmarshalText([]byte(s))
+		case encoding.BinaryUnmarshaler:
+			return u.UnmarshalBinary([]byte(s))
+		}
+	}
+
+	t := v.Type()
+	if t.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		return parseVar(v.Elem(), s)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if t == durationType {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return err
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(s, 0, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 0, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// an empty value is unset for the omitempty validations.
+			var b []byte
+			if s != "" {
+				b = []byte(s)
+			}
+			v.SetBytes(b)
+			return nil
+		}
+		sl := reflect.MakeSlice(t, 0, 0)
+		if strings.TrimSpace(s) != "" {
+			items := strings.Split(s, ",")
+			sl = reflect.MakeSlice(t, len(items), len(items))
+			for i, item := range items {
+				if err := parseVar(sl.Index(i), item); err != nil {
+					return err
+				}
+			}
+		}
+		v.Set(sl)
+	case reflect.Map:
+		m := reflect.MakeMap(t)
+		if strings.TrimSpace(s) != "" {
+			for _, item := range strings.Split(s, ",") {
+				ks, vs, ok := strings.Cut(item, ":")
+				if !ok || strings.Contains(vs, ":") {
+					return fmt.Errorf("invalid map item: %q", item)
+				}
+				k, e := reflect.New(t.Key()).Elem(), reflect.New(t.Elem()).Elem()
+				if err := parseVar(k, ks); err != nil {
+					return err
+				}
+				if err := parseVar(e, vs); err != nil {
+					return err
+				}
+				m.SetMapIndex(k, e)
+			}
+		}
+		v.Set(m)
+	default:
+		return fmt.Errorf("unsupported type %s", t)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package dtocms
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/cirius-go/portfolio-server/pkg/health"
+)
+
+// RuntimeMemory represents the memory statistics of the runtime.
+type RuntimeMemory struct {
+	Alloc       uint64 `json:"alloc"`
+	TotalAlloc  uint64 `json:"total_alloc"`
+	Sys         uint64 `json:"sys"`
+	HeapAlloc   uint64 `json:"heap_alloc"`
+	HeapInuse   uint64 `json:"heap_inuse"`
+	HeapObjects uint64 `json:"heap_objects"`
+	StackInuse  uint64 `json:"stack_inuse"`
+}
+
+// RuntimeGC represents the garbage collector statistics of the runtime.
+type RuntimeGC struct {
+	NumGC      uint32        `json:"num_gc"`
+	PauseTotal time.Duration `json:"pause_total" swaggertype:"integer"`
+	LastGC     *time.Time    `json:"last_gc,omitempty"`
+}
+
+type (
+	// RuntimeAdminReq is the request data of Admin.Runtime.
+	RuntimeAdminReq struct{}
+
+	// RuntimeAdminRes is the response data of Admin.Runtime.
+	RuntimeAdminRes struct {
+		StartedAt  time.Time         `json:"started_at"`
+		Uptime     string            `json:"uptime"`
+		Goroutines int               `json:"goroutines"`
+		CPUs       int               `json:"cpus"`
+		Memory     RuntimeMemory     `json:"memory"`
+		GC         RuntimeGC         `json:"gc"`
+		Build      *health.BuildInfo `json:"build"`
+	}
+)
+
+type (
+	// ConfigAdminReq is the request data of Admin.Config.
+	ConfigAdminReq struct{}
+
+	// ConfigAdminRes is the response data of Admin.Config.
+	ConfigAdminRes struct {
+		Stage string `json:"stage"`
+		// Version is the version of the config, increased by every reload.
+		Version  uint64    `json:"version"`
+		LoadedAt time.Time `json:"loaded_at"`
+		// Vars are the values of the config by environment variable, with
+		// the secrets redacted.
+		Vars map[string]string `json:"vars"`
+	}
+)
+
+// AdminSettings represents the settings of the server which can be changed
+// at runtime.
+type AdminSettings struct {
+	LogLevel slog.Level `json:"log_level" swaggertype:"string" example:"INFO"`
+	// Debug logs the queries of every request.
+	Debug bool `json:"debug"`
+}
+
+type (
+	// SettingsAdminReq is the request data of Admin.Settings.
+	SettingsAdminReq struct{}
+
+	// SettingsAdminRes is the response data of Admin.Settings.
+	SettingsAdminRes = AdminSettings
+)
+
+type (
+	// UpdateSettingsAdminReq is the request data of Admin.UpdateSettings.
+	UpdateSettingsAdminReq struct {
+		LogLevel *slog.Level `json:"log_level,omitempty" swaggertype:"string" example:"DEBUG"`
+		Debug    *bool       `json:"debug,omitempty"`
+	}
+
+	// UpdateSettingsAdminRes is the response data of Admin.UpdateSettings.
+	UpdateSettingsAdminRes = AdminSettings
+)
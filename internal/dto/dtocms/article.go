@@ -1 +1,33 @@
 package dtocms
+
+import "github.com/cirius-go/portfolio-server/internal/repo/model"
+
+// NewArticlePublication maps the article model to the dto.
+func NewArticlePublication(m *model.Article) *Publication {
+	return &Publication{
+		ID:          m.ID,
+		Slug:        m.Slug,
+		Status:      m.Status,
+		PublishedAt: m.PublishedAt,
+	}
+}
+
+type (
+	// PublishArticleReq is the request data of Article.Publish.
+	PublishArticleReq struct {
+		ID string `param:"id" json:"-" validate:"required"`
+	}
+
+	// PublishArticleRes is the response data of Article.Publish.
+	PublishArticleRes = Publication
+)
+
+type (
+	// UnpublishArticleReq is the request data of Article.Unpublish.
+	UnpublishArticleReq struct {
+		ID string `param:"id" json:"-" validate:"required"`
+	}
+
+	// UnpublishArticleRes is the response data of Article.Unpublish.
+	UnpublishArticleRes = Publication
+)
@@ -0,0 +1,132 @@
+package dtocms
+
+import (
+	"strings"
+	"time"
+)
+
+type (
+	// SessionToken represents the issued session tokens.
+	SessionToken struct {
+		AccessToken  string    `json:"access_token"`
+		RefreshToken string    `json:"refresh_token"`
+		ExpiresAt    time.Time `json:"expires_at"`
+	}
+
+	// MFAEnrollment represents a pending TOTP enrollment.
+	MFAEnrollment struct {
+		Secret string `json:"secret"`
+		URI    string `json:"uri"`
+		QRCode []byte `json:"qr_code" swaggertype:"string" format:"base64"` // PNG image.
+	}
+)
+
+type (
+	// LoginAuthReq is the request data of Auth.Login.
+	LoginAuthReq struct {
+		Email    string `json:"email" validate:"required,email"`
+		Password string `json:"password" validate:"required"`
+	}
+
+	// LoginAuthRes is the response data of Auth.Login.
+	//
+	// When MFARequired is set, the session is not issued yet and the challenge
+	// token must be exchanged through Auth.VerifyChallenge.
+	LoginAuthRes struct {
+		MFARequired           bool          `json:"mfa_required"`
+		MFAEnrollmentRequired bool          `json:"mfa_enrollment_required"`
+		ChallengeToken        string        `json:"challenge_token,omitempty"`
+		ChallengeExpiresAt    *time.Time    `json:"challenge_expires_at,omitempty"`
+		Session               *SessionToken `json:"session,omitempty"`
+	}
+)
+
+type (
+	// RefreshAuthReq is the request data of Auth.Refresh.
+	RefreshAuthReq struct {
+		RefreshToken string `json:"refresh_token" validate:"required"`
+	}
+
+	// RefreshAuthRes is the response data of Auth.Refresh.
+	RefreshAuthRes = SessionToken
+)
+
+type (
+	// EnrollChallengeAuthReq is the request data of Auth.EnrollChallenge.
+	EnrollChallengeAuthReq struct {
+		ChallengeToken string `json:"challenge_token" validate:"required"`
+	}
+
+	// EnrollChallengeAuthRes is the response data of Auth.EnrollChallenge.
+	EnrollChallengeAuthRes = MFAEnrollment
+)
+
+type (
+	// VerifyChallengeAuthReq is the request data of Auth.VerifyChallenge.
+	VerifyChallengeAuthReq struct {
+		ChallengeToken string `json:"challenge_token" validate:"required"`
+		Code           string `json:"code" validate:"required_without=RecoveryCode"`
+		RecoveryCode   string `json:"recovery_code" validate:"required_without=Code"`
+	}
+
+	// VerifyChallengeAuthRes is the response data of Auth.VerifyChallenge.
+	VerifyChallengeAuthRes struct {
+		Session       *SessionToken `json:"session"`
+		RecoveryCodes []string      `json:"recovery_codes,omitempty"`
+	}
+)
+
+type (
+	// EnrollMFAAuthReq is the request data of Auth.EnrollMFA.
+	EnrollMFAAuthReq struct{}
+
+	// EnrollMFAAuthRes is the response data of Auth.EnrollMFA.
+	EnrollMFAAuthRes = MFAEnrollment
+)
+
+type (
+	// ActivateMFAAuthReq is the request data of Auth.ActivateMFA.
+	ActivateMFAAuthReq struct {
+		Code string `json:"code" validate:"required"`
+	}
+
+	// ActivateMFAAuthRes is the response data of Auth.ActivateMFA.
+	ActivateMFAAuthRes struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+)
+
+type (
+	// RegenerateRecoveryCodesAuthReq is the request data of Auth.RegenerateRecoveryCodes.
+	RegenerateRecoveryCodesAuthReq struct {
+		Code string `json:"code" validate:"required"`
+	}
+
+	// RegenerateRecoveryCodesAuthRes is the response data of Auth.RegenerateRecoveryCodes.
+	RegenerateRecoveryCodesAuthRes = ActivateMFAAuthRes
+)
+
+type (
+	// DisableMFAAuthReq is the request data of Auth.DisableMFA.
+	DisableMFAAuthReq struct {
+		Code string `json:"code" validate:"required"`
+	}
+
+	// DisableMFAAuthRes is the response data of Auth.DisableMFA.
+	DisableMFAAuthRes struct{}
+)
+
+// RateLimitAccount implements api.AccountRequest.
+func (r *LoginAuthReq) RateLimitAccount() string {
+	return strings.ToLower(strings.TrimSpace(r.Email))
+}
+
+// RateLimitAccount implements api.AccountRequest.
+func (r *EnrollChallengeAuthReq) RateLimitAccount() string {
+	return r.ChallengeToken
+}
+
+// RateLimitAccount implements api.AccountRequest.
+func (r *VerifyChallengeAuthReq) RateLimitAccount() string {
+	return r.ChallengeToken
+}
@@ -0,0 +1,56 @@
+package dtocms
+
+import (
+	"time"
+
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+)
+
+// Invitation represents the invitation data exposed to the cms.
+type Invitation struct {
+	ID        string         `json:"id"`
+	Email     string         `json:"email"`
+	Name      string         `json:"name"`
+	Role      model.UserRole `json:"role"`
+	ExpiresAt time.Time      `json:"expires_at"`
+}
+
+// NewInvitation maps the invitation model to the dto.
+func NewInvitation(m *model.Invitation) *Invitation {
+	return &Invitation{
+		ID:        m.ID,
+		Email:     m.Email,
+		Name:      m.Name,
+		Role:      m.Role,
+		ExpiresAt: m.ExpiresAt,
+	}
+}
+
+type (
+	// CreateInvitationReq is the request data of Invitation.Create.
+	CreateInvitationReq struct {
+		Email string         `json:"email" validate:"required,email"`
+		Name  string         `json:"name" validate:"max=255"`
+		Role  model.UserRole `json:"role" validate:"required,oneof=admin editor"`
+	}
+
+	// CreateInvitationRes is the response data of Invitation.Create.
+	CreateInvitationRes = Invitation
+)
+
+type (
+	// AcceptInvitationReq is the request data of Invitation.Accept.
+	AcceptInvitationReq struct {
+		Token    string `json:"token" validate:"required"`
+		Name     string `json:"name" validate:"max=255"`
+		Password string `json:"password" validate:"required,min=10,max=72"`
+	}
+
+	// AcceptInvitationRes is the response data of Invitation.Accept.
+	AcceptInvitationRes = User
+)
+
+// RateLimitAccount implements api.AccountRequest.
+func (r *AcceptInvitationReq) RateLimitAccount() string {
+	return r.Token
+}
@@ -0,0 +1,34 @@
+package dtocms
+
+import "strings"
+
+type (
+	// ForgotPasswordReq is the request data of Password.Forgot.
+	ForgotPasswordReq struct {
+		Email string `json:"email" validate:"required,email"`
+	}
+
+	// ForgotPasswordRes is the response data of Password.Forgot.
+	ForgotPasswordRes struct{}
+)
+
+type (
+	// ResetPasswordReq is the request data of Password.Reset.
+	ResetPasswordReq struct {
+		Token    string `json:"token" validate:"required"`
+		Password string `json:"password" validate:"required,min=10,max=72"`
+	}
+
+	// ResetPasswordRes is the response data of Password.Reset.
+	ResetPasswordRes struct{}
+)
+
+// RateLimitAccount implements api.AccountRequest.
+func (r *ForgotPasswordReq) RateLimitAccount() string {
+	return strings.ToLower(strings.TrimSpace(r.Email))
+}
+
+// RateLimitAccount implements api.AccountRequest.
+func (r *ResetPasswordReq) RateLimitAccount() string {
+	return r.Token
+}
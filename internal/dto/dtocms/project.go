@@ -1 +1,33 @@
 package dtocms
+
+import "github.com/cirius-go/portfolio-server/internal/repo/model"
+
+// NewProjectPublication maps the project model to the dto.
+func NewProjectPublication(m *model.Project) *Publication {
+	return &Publication{
+		ID:          m.ID,
+		Slug:        m.Slug,
+		Status:      m.Status,
+		PublishedAt: m.PublishedAt,
+	}
+}
+
+type (
+	// PublishProjectReq is the request data of Project.Publish.
+	PublishProjectReq struct {
+		ID string `param:"id" json:"-" validate:"required"`
+	}
+
+	// PublishProjectRes is the response data of Project.Publish.
+	PublishProjectRes = Publication
+)
+
+type (
+	// UnpublishProjectReq is the request data of Project.Unpublish.
+	UnpublishProjectReq struct {
+		ID string `param:"id" json:"-" validate:"required"`
+	}
+
+	// UnpublishProjectRes is the response data of Project.Unpublish.
+	UnpublishProjectRes = Publication
+)
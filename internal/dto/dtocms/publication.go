@@ -0,0 +1,15 @@
+package dtocms
+
+import (
+	"time"
+
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+)
+
+// Publication represents the publication state of a content.
+type Publication struct {
+	ID          string              `json:"id"`
+	Slug        string              `json:"slug"`
+	Status      model.PublishStatus `json:"status"`
+	PublishedAt *time.Time          `json:"published_at,omitempty"`
+}
@@ -1 +1,52 @@
 package dtocms
+
+import (
+	"time"
+
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+)
+
+// User represents the user data exposed to the cms.
+type User struct {
+	ID           string         `json:"id"`
+	Email        string         `json:"email"`
+	Name         string         `json:"name"`
+	Role         model.UserRole `json:"role"`
+	MFAEnabled   bool           `json:"mfa_enabled"`
+	MFARequired  bool           `json:"mfa_required"`
+	MFAEnabledAt *time.Time     `json:"mfa_enabled_at,omitempty"`
+}
+
+// NewUser maps the user model to the dto.
+func NewUser(m *model.User) *User {
+	return &User{
+		ID:           m.ID,
+		Email:        m.Email,
+		Name:         m.Name,
+		Role:         m.Role,
+		MFAEnabled:   m.MFAEnabled,
+		MFARequired:  m.MFARequired,
+		MFAEnabledAt: m.MFAEnabledAt,
+	}
+}
+
+type (
+	// RequireMFAUserReq is the request data of User.RequireMFA.
+	RequireMFAUserReq struct {
+		ID       string `param:"id" json:"-" validate:"required"`
+		Required bool   `json:"required"`
+	}
+
+	// RequireMFAUserRes is the response data of User.RequireMFA.
+	RequireMFAUserRes = User
+)
+
+type (
+	// ResetMFAUserReq is the request data of User.ResetMFA.
+	ResetMFAUserReq struct {
+		ID string `param:"id" json:"-" validate:"required"`
+	}
+
+	// ResetMFAUserRes is the response data of User.ResetMFA.
+	ResetMFAUserRes = User
+)
@@ -0,0 +1,77 @@
+package dtopublic
+
+import (
+	"time"
+
+	"github.com/cirius-go/portfolio-server/internal/dto"
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+)
+
+// ArticleSummary represents the article data exposed to the public in
+// listings.
+type ArticleSummary struct {
+	Title       string    `json:"title"`
+	Slug        string    `json:"slug"`
+	Summary     string    `json:"summary"`
+	CoverURL    string    `json:"cover_url"`
+	PublishedAt time.Time `json:"published_at"`
+	Tags        []*Tag    `json:"tags"`
+}
+
+// NewArticleSummary maps the article model to the dto.
+func NewArticleSummary(m *model.Article) *ArticleSummary {
+	res := &ArticleSummary{
+		Title:    m.Title,
+		Slug:     m.Slug,
+		Summary:  m.Summary,
+		CoverURL: m.CoverURL,
+		Tags:     NewTags(m.Tags),
+	}
+	if m.PublishedAt != nil {
+		res.PublishedAt = *m.PublishedAt
+	}
+	return res
+}
+
+// Article represents the full article data exposed to the public.
+type Article struct {
+	ArticleSummary
+	Content   string    `json:"content"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewArticle maps the article model to the dto.
+func NewArticle(m *model.Article) *Article {
+	return &Article{
+		ArticleSummary: *NewArticleSummary(m),
+		Content:        m.Content,
+		UpdatedAt:      m.UpdatedAt,
+	}
+}
+
+// LastModified implements api.LastModifier.
+func (a *Article) LastModified() time.Time {
+	return a.UpdatedAt
+}
+
+type (
+	// ListArticleReq is the request data of Article.List.
+	ListArticleReq struct {
+		Page    int    `json:"p" query:"p" validate:"omitempty,min=1"`
+		PerPage int    `json:"pp" query:"pp" validate:"omitempty,min=1,max=100"`
+		Tag     string `json:"tag" query:"tag"`
+	}
+
+	// ListArticleRes is the response data of Article.List.
+	ListArticleRes = dto.ListingRes[ArticleSummary]
+)
+
+type (
+	// GetArticleReq is the request data of Article.Get.
+	GetArticleReq struct {
+		Slug string `param:"slug" json:"-" validate:"required"`
+	}
+
+	// GetArticleRes is the response data of Article.Get.
+	GetArticleRes = Article
+)
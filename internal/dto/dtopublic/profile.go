@@ -0,0 +1,56 @@
+package dtopublic
+
+import (
+	"time"
+
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+)
+
+// Profile represents the author profile exposed to the public.
+type Profile struct {
+	Name      string         `json:"name"`
+	Headline  string         `json:"headline"`
+	Bio       string         `json:"bio"`
+	AvatarURL string         `json:"avatar_url"`
+	Location  string         `json:"location"`
+	Email     string         `json:"email"`
+	Links     []*ProfileLink `json:"links"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// ProfileLink represents a link of the author profile.
+type ProfileLink struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+// NewProfile maps the profile model to the dto.
+func NewProfile(m *model.Profile) *Profile {
+	res := &Profile{
+		Name:      m.Name,
+		Headline:  m.Headline,
+		Bio:       m.Bio,
+		AvatarURL: m.AvatarURL,
+		Location:  m.Location,
+		Email:     m.Email,
+		Links:     make([]*ProfileLink, 0, len(m.Links)),
+		UpdatedAt: m.UpdatedAt,
+	}
+	for _, l := range m.Links {
+		res.Links = append(res.Links, &ProfileLink{Label: l.Label, URL: l.URL})
+	}
+	return res
+}
+
+// LastModified implements api.LastModifier.
+func (p *Profile) LastModified() time.Time {
+	return p.UpdatedAt
+}
+
+type (
+	// GetProfileReq is the request data of Profile.Get.
+	GetProfileReq struct{}
+
+	// GetProfileRes is the response data of Profile.Get.
+	GetProfileRes = Profile
+)
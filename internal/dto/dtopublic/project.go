@@ -0,0 +1,71 @@
+package dtopublic
+
+import (
+	"time"
+
+	"github.com/cirius-go/portfolio-server/internal/dto"
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+)
+
+// Project represents the project data exposed to the public.
+type Project struct {
+	Title       string    `json:"title"`
+	Slug        string    `json:"slug"`
+	Summary     string    `json:"summary"`
+	Description string    `json:"description"`
+	CoverURL    string    `json:"cover_url"`
+	URL         string    `json:"url"`
+	RepoURL     string    `json:"repo_url"`
+	Featured    bool      `json:"featured"`
+	PublishedAt time.Time `json:"published_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Tags        []*Tag    `json:"tags"`
+}
+
+// NewProject maps the project model to the dto.
+func NewProject(m *model.Project) *Project {
+	res := &Project{
+		Title:       m.Title,
+		Slug:        m.Slug,
+		Summary:     m.Summary,
+		Description: m.Description,
+		CoverURL:    m.CoverURL,
+		URL:         m.URL,
+		RepoURL:     m.RepoURL,
+		Featured:    m.Featured,
+		UpdatedAt:   m.UpdatedAt,
+		Tags:        NewTags(m.Tags),
+	}
+	if m.PublishedAt != nil {
+		res.PublishedAt = *m.PublishedAt
+	}
+	return res
+}
+
+// LastModified implements api.LastModifier.
+func (p *Project) LastModified() time.Time {
+	return p.UpdatedAt
+}
+
+type (
+	// ListProjectReq is the request data of Project.List.
+	ListProjectReq struct {
+		Page     int    `json:"p" query:"p" validate:"omitempty,min=1"`
+		PerPage  int    `json:"pp" query:"pp" validate:"omitempty,min=1,max=100"`
+		Featured bool   `json:"featured" query:"featured"`
+		Tag      string `json:"tag" query:"tag"`
+	}
+
+	// ListProjectRes is the response data of Project.List.
+	ListProjectRes = dto.ListingRes[Project]
+)
+
+type (
+	// GetProjectReq is the request data of Project.Get.
+	GetProjectReq struct {
+		Slug string `param:"slug" json:"-" validate:"required"`
+	}
+
+	// GetProjectRes is the response data of Project.Get.
+	GetProjectRes = Project
+)
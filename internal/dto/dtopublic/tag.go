@@ -0,0 +1,36 @@
+package dtopublic
+
+import "github.com/cirius-go/portfolio-server/internal/repo/model"
+
+// Tag represents the tag data exposed to the public.
+type Tag struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// NewTag maps the tag model to the dto.
+func NewTag(m *model.Tag) *Tag {
+	return &Tag{
+		Name: m.Name,
+		Slug: m.Slug,
+	}
+}
+
+// NewTags maps the tag models to the dto.
+func NewTags(ms []*model.Tag) []*Tag {
+	res := make([]*Tag, 0, len(ms))
+	for _, m := range ms {
+		res = append(res, NewTag(m))
+	}
+	return res
+}
+
+type (
+	// ListTagReq is the request data of Tag.List.
+	ListTagReq struct{}
+
+	// ListTagRes is the response data of Tag.List.
+	ListTagRes struct {
+		Recs []*Tag `json:"recs"`
+	}
+)
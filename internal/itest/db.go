@@ -0,0 +1,87 @@
+package itest
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/cirius-go/portfolio-server/pkg/db"
+)
+
+// schemaSeq numbers the schemas of the tests.
+var schemaSeq atomic.Int64
+
+// Tx begins a transaction on the migrated database which is rolled back when
+// the test ends. The transactions of the units created on it are savepoints,
+// so the test sees their work and leaves nothing behind.
+//
+// The transaction holds a single connection: it must not be used by
+// concurrent requests.
+func (e *Env) Tx(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	tx := e.pg.DB.WithContext(t.Context()).Begin()
+	if tx.Error != nil {
+		t.Fatalf("itest: failed to begin the test transaction: %v", tx.Error)
+	}
+	t.Cleanup(func() { tx.Rollback() })
+	return tx
+}
+
+// Schema creates a schema migrated from scratch for the test and connects to
+// it. Unlike Tx, transactions are really committed, e.g. for tests of
+// concurrent requests. The schema is dropped when the test ends.
+func (e *Env) Schema(t testing.TB) *db.Postgres {
+	t.Helper()
+
+	name := fmt.Sprintf("itest_%d", schemaSeq.Add(1))
+	if err := e.pg.DB.Exec("CREATE SCHEMA " + name).Error; err != nil {
+		t.Fatalf("itest: failed to create schema %s: %v", name, err)
+	}
+	t.Cleanup(func() {
+		if err := e.pg.DB.Exec("DROP SCHEMA " + name + " CASCADE").Error; err != nil {
+			t.Errorf("itest: failed to drop schema %s: %v", name, err)
+		}
+	})
+
+	cfg, err := withSearchPath(e.cfg, name+",public")
+	if err != nil {
+		t.Fatalf("itest: %v", err)
+	}
+	pg, err := db.NewPostgres(cfg)
+	if err != nil {
+		t.Fatalf("itest: failed to connect to schema %s: %v", name, err)
+	}
+	t.Cleanup(func() { pg.Close() })
+
+	if err := migrate(t.Context(), pg); err != nil {
+		t.Fatalf("itest: failed to migrate schema %s: %v", name, err)
+	}
+	return pg
+}
+
+// withSearchPath returns a copy of cfg whose connections use the search path.
+func withSearchPath(cfg db.PostgresConfig, path string) (db.PostgresConfig, error) {
+	if cfg.DSN == "" {
+		cfg.Args = maps.Clone(cfg.Args)
+		if cfg.Args == nil {
+			cfg.Args = map[string]string{}
+		}
+		cfg.Args["search_path"] = path
+		return cfg, nil
+	}
+
+	u, err := url.Parse(cfg.DSN)
+	if err != nil {
+		return cfg, fmt.Errorf("invalid %s_DSN: %w", EnvPrefix, err)
+	}
+	q := u.Query()
+	q.Set("search_path", path)
+	u.RawQuery = q.Encode()
+	cfg.DSN = u.String()
+	return cfg, nil
+}
@@ -0,0 +1,106 @@
+package itest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"github.com/cirius-go/portfolio-server/internal/app"
+	"github.com/cirius-go/portfolio-server/internal/config"
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+	"github.com/cirius-go/portfolio-server/internal/service"
+	"github.com/cirius-go/portfolio-server/internal/uow"
+	"github.com/cirius-go/portfolio-server/pkg/mailer"
+)
+
+// FakeAuth accepts the tokens made by Token without verifying them.
+type FakeAuth struct{}
+
+// ParseSession implements api.SessionParser.
+func (FakeAuth) ParseSession(ctx context.Context, token string) (*service.Session, error) {
+	role, userID, ok := strings.Cut(token, ":")
+	if !ok || userID == "" {
+		return nil, service.ErrMissingSession
+	}
+	r, err := model.ParseUserRole(role)
+	if err != nil {
+		return nil, service.ErrMissingSession
+	}
+	return &service.Session{UserID: userID, Role: r}, nil
+}
+
+// Token returns the bearer token of the session for FakeAuth.
+func Token(role model.UserRole, userID string) string {
+	return string(role) + ":" + userID
+}
+
+// NewApp boots the api of cmd/api on the database, with the default config,
+// no cache, a mailer writing to a temporary directory and FakeAuth. The deps
+// can be changed by the options before the api is wired.
+func NewApp(t testing.TB, gdb *gorm.DB, opts ...func(d *app.Deps)) *app.App {
+	t.Helper()
+
+	cfg := config.C()
+	cfg.Mailer.Driver = mailer.DriverFile
+	cfg.Mailer.FileDir = t.TempDir()
+
+	mail, err := mailer.NewFile(cfg.Mailer.FileDir)
+	if err != nil {
+		t.Fatalf("itest: failed to create the mailer: %v", err)
+	}
+
+	d := app.Deps{
+		Config:   cfg,
+		UOW:      uow.New(gdb),
+		Mailer:   mail,
+		Enforcer: app.NewEnforcer(false),
+		Auth:     FakeAuth{},
+	}
+	for _, opt := range opts {
+		opt(&d)
+	}
+	return app.New(d)
+}
+
+// Do serves the request with the router and returns the recorded response.
+// body is encoded as JSON unless nil, token is sent as bearer token unless
+// empty.
+func Do(t testing.TB, router *echo.Echo, method, path string, body any, token string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatalf("itest: failed to encode the request body: %v", err)
+		}
+	}
+
+	req := httptest.NewRequestWithContext(t.Context(), method, path, &buf)
+	if body != nil {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	if token != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+// Decode decodes the JSON body of the response into T.
+func Decode[T any](t testing.TB, rec *httptest.ResponseRecorder) *T {
+	t.Helper()
+
+	v := new(T)
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("itest: failed to decode the %d response %q: %v", rec.Code, rec.Body.String(), err)
+	}
+	return v
+}
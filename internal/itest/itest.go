@@ -0,0 +1,175 @@
+// Package itest is the harness of the integration tests. It starts a
+// throwaway Postgres with the migrations applied, isolates the tests from
+// each other and boots the api router with fake auth.
+//
+// The integration tests are built with the integration tag, so that go test
+// ./... runs without Postgres. The Postgres server is shared by the tests of a
+// package and is started from TestMain:
+//
+//	//go:build integration
+//
+//	var env *itest.Env
+//
+//	func TestMain(m *testing.M) {
+//		env = itest.MustStart()
+//		code := m.Run()
+//		env.Close()
+//		os.Exit(code)
+//	}
+package itest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+	"github.com/kelseyhightower/envconfig"
+
+	"github.com/cirius-go/portfolio-server/cmd/workers/migrate/migrations"
+	"github.com/cirius-go/portfolio-server/pkg/db"
+)
+
+// EnvPrefix is the prefix of the environment variables of an external
+// Postgres, e.g. ITEST_PGDB_DSN. The embedded Postgres is started when
+// neither ITEST_PGDB_DSN nor ITEST_PGDB_HOST is set.
+const EnvPrefix = "ITEST_PGDB"
+
+// uuidV7SQL provides uuid_generate_v7, the default of the primary keys, on
+// servers without the extension.
+const uuidV7SQL = `DO $$
+BEGIN
+	IF NOT EXISTS (SELECT 1 FROM pg_proc WHERE proname = 'uuid_generate_v7') THEN
+		CREATE FUNCTION public.uuid_generate_v7() RETURNS uuid AS $f$
+			SELECT encode(
+				set_bit(set_bit(overlay(uuid_send(gen_random_uuid())
+					PLACING substring(int8send(floor(extract(epoch FROM clock_timestamp()) * 1000)::bigint) FROM 3)
+					FROM 1 FOR 6), 52, 1), 53, 1),
+				'hex')::uuid
+		$f$ LANGUAGE sql VOLATILE;
+	END IF;
+END $$`
+
+// Env is the Postgres server of the tests, with the migrations applied to
+// its database.
+type Env struct {
+	cfg      db.PostgresConfig
+	pg       *db.Postgres
+	embedded *embeddedpostgres.EmbeddedPostgres
+	tmpDir   string
+}
+
+// Start starts the Postgres server of the tests and migrates its database.
+func Start() (*Env, error) {
+	e := &Env{}
+	if err := envconfig.Process(EnvPrefix, &e.cfg); err != nil {
+		return nil, err
+	}
+
+	if e.cfg.DSN == "" && e.cfg.Host == "" {
+		if err := e.startEmbedded(); err != nil {
+			e.Close()
+			return nil, err
+		}
+	}
+
+	var err error
+	if e.pg, err = db.NewPostgres(e.cfg); err != nil {
+		e.Close()
+		return nil, err
+	}
+	if err := e.pg.DB.Exec(uuidV7SQL).Error; err != nil {
+		e.Close()
+		return nil, err
+	}
+	if err := migrate(context.Background(), e.pg); err != nil {
+		e.Close()
+		return nil, err
+	}
+	return e, nil
+}
+
+// MustStart is Start which panics on failure.
+func MustStart() *Env {
+	e, err := Start()
+	if err != nil {
+		panic(fmt.Sprintf("itest: failed to start postgres: %v", err))
+	}
+	return e
+}
+
+// Close closes the connections and stops the embedded server.
+func (e *Env) Close() error {
+	var err error
+	if e.pg != nil {
+		err = e.pg.Close()
+	}
+	if e.embedded != nil {
+		if stopErr := e.embedded.Stop(); stopErr != nil {
+			err = stopErr
+		}
+	}
+	if e.tmpDir != "" {
+		os.RemoveAll(e.tmpDir)
+	}
+	return err
+}
+
+// startEmbedded starts a Postgres server in a temporary directory on a free
+// port. The binaries are downloaded once into the user cache.
+func (e *Env) startEmbedded() error {
+	port, err := freePort()
+	if err != nil {
+		return err
+	}
+	if e.tmpDir, err = os.MkdirTemp("", "portfolio-itest-"); err != nil {
+		return err
+	}
+
+	e.cfg = db.PostgresConfig{
+		Host:     "localhost",
+		Port:     port,
+		Username: "itest",
+		Password: "itest",
+		Database: "portfolio",
+		Args: map[string]string{
+			"sslmode":  "disable",
+			"timezone": "UTC",
+		},
+		LogLevel: e.cfg.LogLevel,
+	}
+	e.embedded = embeddedpostgres.NewDatabase(embeddedpostgres.DefaultConfig().
+		Version(embeddedpostgres.V15).
+		Port(uint32(port)).
+		Username(e.cfg.Username).
+		Password(e.cfg.Password).
+		Database(e.cfg.Database).
+		RuntimePath(filepath.Join(e.tmpDir, "runtime")).
+		Logger(nil))
+	if err := e.embedded.Start(); err != nil {
+		e.embedded = nil
+		return err
+	}
+	return nil
+}
+
+// migrate applies the migrations to the database of pg.
+func migrate(ctx context.Context, pg *db.Postgres) error {
+	p, err := migrations.NewProvider(pg.Conn)
+	if err != nil {
+		return err
+	}
+	_, err = p.Up(ctx)
+	return err
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
@@ -0,0 +1,155 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/cirius-go/portfolio-server/internal/config"
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+	"github.com/cirius-go/portfolio-server/internal/uow"
+	"github.com/cirius-go/portfolio-server/pkg/logging"
+)
+
+// Dispatcher delivers the outbox events to the handlers of their topic.
+//
+// A failed delivery is retried with an exponential backoff until the max
+// attempts are reached, then the event is dead-lettered. Events of a topic
+// without handler are dead-lettered right away.
+type Dispatcher struct {
+	uow      uow.UnitOfWork
+	cfg      config.Outbox
+	handlers map[string]Handler
+	events   *prometheus.CounterVec
+}
+
+// NewDispatcher creates a new Dispatcher.
+func NewDispatcher(u uow.UnitOfWork, cfg config.Outbox) *Dispatcher {
+	return &Dispatcher{
+		uow:      u,
+		cfg:      cfg,
+		handlers: map[string]Handler{},
+	}
+}
+
+// WithMetrics counts the delivery outcomes in the registry.
+func (d *Dispatcher) WithMetrics(reg prometheus.Registerer) *Dispatcher {
+	d.events = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "outbox_events_total",
+		Help: "Number of outbox event deliveries by topic and outcome.",
+	}, []string{"topic", "outcome"})
+	reg.MustRegister(d.events)
+	return d
+}
+
+// Handle sets the handler of the topic.
+func (d *Dispatcher) Handle(topic string, h Handler) *Dispatcher {
+	d.handlers[topic] = h
+	return d
+}
+
+// Run dispatches the due events until ctx is done. It polls the outbox every
+// poll interval, or right away while batches come back full.
+//
+// The claimed batch is delivered even once ctx is done, so that a shutdown
+// leaves no event leased until the lease expires.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	for {
+		n, err := d.DispatchOnce(context.WithoutCancel(ctx))
+		if err != nil {
+			logging.FromContext(ctx).Error("failed to dispatch outbox events", "error", err)
+		}
+
+		wait := d.cfg.PollInterval
+		if err == nil && n >= d.cfg.BatchSize {
+			wait = 0
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// DispatchOnce claims one batch of due events and delivers them. It returns
+// the number of claimed events.
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	events, err := d.uow.OutboxEvents().ClaimDue(ctx, d.cfg.BatchSize, d.cfg.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, e := range events {
+		if err := d.deliver(ctx, e); err != nil {
+			return len(events), err
+		}
+	}
+	return len(events), nil
+}
+
+// deliver delivers the event and records the outcome. It only fails when the
+// outcome cannot be recorded.
+func (d *Dispatcher) deliver(ctx context.Context, e *model.OutboxEvent) error {
+	repo := d.uow.OutboxEvents()
+	ctx = logging.With(ctx, "event_id", e.ID, "topic", e.Topic)
+
+	h, ok := d.handlers[e.Topic]
+	if !ok {
+		logging.FromContext(ctx).Error("outbox event dead-lettered: no handler for topic")
+		d.count(e.Topic, "dead")
+		return repo.DeadLetter(ctx, e.ID, e.Attempts, fmt.Sprintf("no handler for topic %q", e.Topic))
+	}
+
+	err := call(ctx, h, e)
+	if err == nil {
+		d.count(e.Topic, "delivered")
+		return repo.MarkDelivered(ctx, e.ID)
+	}
+
+	attempts := e.Attempts + 1
+	if attempts >= d.cfg.MaxAttempts {
+		logging.FromContext(ctx).Error("outbox event dead-lettered", "attempts", attempts, "error", err)
+		d.count(e.Topic, "dead")
+		return repo.DeadLetter(ctx, e.ID, attempts, err.Error())
+	}
+	d.count(e.Topic, "retried")
+	return repo.Retry(ctx, e.ID, attempts, time.Now().Add(d.backoff(attempts)), err.Error())
+}
+
+func (d *Dispatcher) count(topic, outcome string) {
+	if d.events != nil {
+		d.events.WithLabelValues(topic, outcome).Inc()
+	}
+}
+
+// backoff returns the delay before the next attempt: an exponential backoff
+// capped to the max backoff, of which the second half is jittered so that
+// failing events do not retry in lockstep.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.MaxBackoff
+	if shift := attempts - 1; shift < 32 {
+		if exp := d.cfg.MinBackoff << shift; exp > 0 && exp < delay {
+			delay = exp
+		}
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// call runs the handler, turning a panic into an error.
+func call(ctx context.Context, h Handler, e *model.OutboxEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return h(ctx, e.Payload)
+}
@@ -0,0 +1,42 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+	"github.com/cirius-go/portfolio-server/internal/uow"
+)
+
+// Enqueue writes an event of the topic to the outbox. It must be called with
+// the context of a transaction so that the event is only delivered if the
+// transaction commits.
+func Enqueue(ctx context.Context, u uow.UnitOfWork, topic string, payload any) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return u.OutboxEvents().Create(ctx, &model.OutboxEvent{
+		Topic:         topic,
+		Payload:       b,
+		Status:        model.OutboxStatusPending,
+		NextAttemptAt: time.Now(),
+	})
+}
+
+// Handler delivers the payload of an event. Events are delivered at least
+// once, so handlers must be idempotent.
+type Handler func(ctx context.Context, payload json.RawMessage) error
+
+// HandleJSON returns a handler which decodes the payload as T.
+func HandleJSON[T any](fn func(ctx context.Context, payload T) error) Handler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		v := new(T)
+		if err := json.Unmarshal(payload, v); err != nil {
+			return err
+		}
+		return fn(ctx, *v)
+	}
+}
@@ -1,6 +1,9 @@
 package repo
 
 import (
+	"context"
+	"time"
+
 	"github.com/cirius-go/portfolio-server/internal/repo/model"
 	"gorm.io/gorm"
 )
@@ -15,3 +18,47 @@ type Articles struct {
 func NewArticles(db *gorm.DB) *Articles {
 	return &Articles{db, NewCommon[model.Article](db)}
 }
+
+// ArticleFilter represents the filter of listing articles.
+type ArticleFilter struct {
+	TagSlug string
+}
+
+// ListPublished lists the published articles, latest first. The total is only
+// counted if req.Count is set.
+func (r *Articles) ListPublished(ctx context.Context, req *ListingRequest[ArticleFilter]) ([]*model.Article, int64, error) {
+	var (
+		res   = make([]*model.Article, 0)
+		total = int64(0)
+	)
+
+	q := r.published(ctx)
+	if req.Filter.TagSlug != "" {
+		q = q.Where("id IN (?)", taggedIDs(r.db, "article_tags", "article_id", req.Filter.TagSlug))
+	}
+
+	if req.Count {
+		if err := q.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, 0, err
+		}
+	}
+
+	err := WithPaging(q, req.Page, req.PerPage).
+		Preload("Tags").
+		Order("published_at DESC").
+		Find(&res).Error
+	return res, total, err
+}
+
+// GetPublishedBySlug gets the published article by slug.
+func (r *Articles) GetPublishedBySlug(ctx context.Context, slug string) (*model.Article, error) {
+	m := new(model.Article)
+	err := r.published(ctx).Preload("Tags").Where("slug = ?", slug).First(m).Error
+	return m, err
+}
+
+func (r *Articles) published(ctx context.Context) *gorm.DB {
+	return r.withCtx(ctx).
+		Model(new(model.Article)).
+		Where("status = ? AND published_at <= ?", model.PublishStatusPublished, time.Now())
+}
@@ -0,0 +1,63 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+	"gorm.io/gorm"
+)
+
+// Invitations Repo.
+type Invitations struct {
+	db *gorm.DB
+	*Common[model.Invitation]
+}
+
+// NewInvitations Repository.
+func NewInvitations(db *gorm.DB) *Invitations {
+	return &Invitations{db, NewCommon[model.Invitation](db)}
+}
+
+// GetPendingByTokenHash gets the invitation which is neither accepted nor
+// expired by its token hash.
+func (r *Invitations) GetPendingByTokenHash(ctx context.Context, hash string) (*model.Invitation, error) {
+	m := new(model.Invitation)
+	err := r.withCtx(ctx).
+		Where("token_hash = ? AND accepted_at IS NULL AND expires_at > ?", hash, time.Now()).
+		First(m).Error
+	return m, err
+}
+
+// Accept marks the pending invitation as accepted. It reports whether the
+// invitation was still pending, so that a token can only be used once.
+func (r *Invitations) Accept(ctx context.Context, id string) (bool, error) {
+	res := r.withCtx(ctx).
+		Model(new(model.Invitation)).
+		Where("id = ? AND accepted_at IS NULL AND expires_at > ?", id, time.Now()).
+		Update("accepted_at", time.Now())
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// CountPendingByEmail counts the invitations of the email which are neither
+// accepted nor expired.
+func (r *Invitations) CountPendingByEmail(ctx context.Context, email string) (int64, error) {
+	count := int64(0)
+	err := r.withCtx(ctx).
+		Model(new(model.Invitation)).
+		Where("LOWER(email) = ? AND accepted_at IS NULL AND expires_at > ?", strings.ToLower(email), time.Now()).
+		Count(&count).Error
+	return count, err
+}
+
+// DeletePendingByEmail removes the invitations of the email which have not
+// been accepted yet.
+func (r *Invitations) DeletePendingByEmail(ctx context.Context, email string) error {
+	return r.withCtx(ctx).
+		Where("LOWER(email) = ? AND accepted_at IS NULL", strings.ToLower(email)).
+		Delete(new(model.Invitation)).Error
+}
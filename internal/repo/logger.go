@@ -0,0 +1,41 @@
+package repo
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+	"github.com/cirius-go/portfolio-server/pkg/db"
+)
+
+// WithDebug returns a copy of ctx whose queries are all logged when debug is
+// set.
+func WithDebug(ctx context.Context, debug bool) context.Context {
+	return context.WithValue(ctx, model.ContextKeyDebug, debug)
+}
+
+// debugAll logs the queries of every context when set.
+var debugAll atomic.Bool
+
+// SetDebugAll turns on or off the logging of every query, whatever the
+// context.
+func SetDebugAll(debug bool) {
+	debugAll.Store(debug)
+}
+
+// DebugAll reports whether every query is logged, whatever the context.
+func DebugAll() bool {
+	return debugAll.Load()
+}
+
+// IsDebug reports whether every query of ctx is logged.
+func IsDebug(ctx context.Context) bool {
+	debug, _ := ctx.Value(model.ContextKeyDebug).(bool)
+	return debug || debugAll.Load()
+}
+
+// ConfigureLogger makes the query logger log every query of the debug
+// contexts.
+func ConfigureLogger(l *db.QueryLogger) *db.QueryLogger {
+	return l.WithVerbose(IsDebug)
+}
@@ -1,6 +1,22 @@
 package model
 
+import "time"
+
 // Article model.
 type Article struct {
-	Model `gorm:"embedded"`
+	Model       `gorm:"embedded"`
+	Title       string        `gorm:"not null" json:"title"`
+	Slug        string        `gorm:"uniqueIndex;not null" json:"slug"`
+	Summary     string        `json:"summary"`
+	Content     string        `json:"content"`
+	CoverURL    string        `json:"cover_url"`
+	Status      PublishStatus `gorm:"type:varchar(32);not null;default:draft;index" json:"status"`
+	PublishedAt *time.Time    `gorm:"index" json:"published_at,omitempty"`
+	AuthorID    *string       `gorm:"type:uuid" json:"author_id,omitempty"`
+	Tags        []*Tag        `gorm:"many2many:article_tags" json:"tags,omitempty"`
+}
+
+// IsPublished reports whether the article is visible to the public.
+func (m *Article) IsPublished() bool {
+	return isPublished(m.Status, m.PublishedAt)
 }
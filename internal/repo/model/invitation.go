@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// Invitation model.
+//
+// Only the hash of the invitation token is stored, the plain token is sent to
+// the invitee by email.
+type Invitation struct {
+	Model       `gorm:"embedded"`
+	Email       string     `gorm:"index;not null" json:"email"`
+	Name        string     `json:"name"`
+	Role        UserRole   `gorm:"type:varchar(32);not null" json:"role"`
+	TokenHash   string     `gorm:"uniqueIndex;not null" json:"-"`
+	InvitedByID *string    `gorm:"type:uuid" json:"invited_by_id,omitempty"`
+	ExpiresAt   time.Time  `gorm:"not null" json:"expires_at"`
+	AcceptedAt  *time.Time `json:"accepted_at,omitempty"`
+}
+
+// PasswordResetToken model.
+type PasswordResetToken struct {
+	Model     `gorm:"embedded"`
+	UserID    string     `gorm:"type:uuid;index;not null" json:"user_id"`
+	TokenHash string     `gorm:"uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+}
@@ -9,7 +9,19 @@ type Model struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
-// ENUM(Debug)
+// ENUM(Debug,Session,Tx)
 //
 //go:generate go-enum --marshal
 type ContextKey string
+
+// PublishStatus represents the publication state of a content.
+// ENUM(draft,published)
+//
+//go:generate go-enum --marshal
+type PublishStatus string
+
+// isPublished reports whether a content with the status and publish time is
+// visible to the public.
+func isPublished(status PublishStatus, publishedAt *time.Time) bool {
+	return status == PublishStatusPublished && publishedAt != nil && !publishedAt.After(time.Now())
+}
@@ -14,6 +14,10 @@ import (
 const (
 	// ContextKeyDebug is a ContextKey of type Debug.
 	ContextKeyDebug ContextKey = "Debug"
+	// ContextKeySession is a ContextKey of type Session.
+	ContextKeySession ContextKey = "Session"
+	// ContextKeyTx is a ContextKey of type Tx.
+	ContextKeyTx ContextKey = "Tx"
 )
 
 var ErrInvalidContextKey = errors.New("not a valid ContextKey")
@@ -31,7 +35,9 @@ func (x ContextKey) IsValid() bool {
 }
 
 var _ContextKeyValue = map[string]ContextKey{
-	"Debug": ContextKeyDebug,
+	"Debug":   ContextKeyDebug,
+	"Session": ContextKeySession,
+	"Tx":      ContextKeyTx,
 }
 
 // ParseContextKey attempts to convert a string to a ContextKey.
@@ -56,3 +62,52 @@ func (x *ContextKey) UnmarshalText(text []byte) error {
 	*x = tmp
 	return nil
 }
+
+const (
+	// PublishStatusDraft is a PublishStatus of type draft.
+	PublishStatusDraft PublishStatus = "draft"
+	// PublishStatusPublished is a PublishStatus of type published.
+	PublishStatusPublished PublishStatus = "published"
+)
+
+var ErrInvalidPublishStatus = errors.New("not a valid PublishStatus")
+
+// String implements the Stringer interface.
+func (x PublishStatus) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x PublishStatus) IsValid() bool {
+	_, err := ParsePublishStatus(string(x))
+	return err == nil
+}
+
+var _PublishStatusValue = map[string]PublishStatus{
+	"draft":     PublishStatusDraft,
+	"published": PublishStatusPublished,
+}
+
+// ParsePublishStatus attempts to convert a string to a PublishStatus.
+func ParsePublishStatus(name string) (PublishStatus, error) {
+	if x, ok := _PublishStatusValue[name]; ok {
+		return x, nil
+	}
+	return PublishStatus(""), fmt.Errorf("%s is %w", name, ErrInvalidPublishStatus)
+}
+
+// MarshalText implements the text marshaller method.
+func (x PublishStatus) MarshalText() ([]byte, error) {
+	return []byte(string(x)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *PublishStatus) UnmarshalText(text []byte) error {
+	tmp, err := ParsePublishStatus(string(text))
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxStatus represents the delivery state of an outbox event.
+// ENUM(pending,delivered,dead)
+//
+//go:generate go-enum --marshal --names --values
+type OutboxStatus string
+
+// OutboxEvent model.
+//
+// An event is written in the transaction of the change it describes and is
+// delivered by the outbox dispatcher once committed. Events which keep
+// failing are dead-lettered and kept for inspection.
+type OutboxEvent struct {
+	Model         `gorm:"embedded"`
+	Topic         string          `gorm:"index;not null" json:"topic"`
+	Payload       json.RawMessage `gorm:"type:jsonb;not null" json:"payload"`
+	Status        OutboxStatus    `gorm:"type:varchar(16);not null;default:pending;index:idx_outbox_events_due,priority:1" json:"status"`
+	Attempts      int             `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time       `gorm:"not null;index:idx_outbox_events_due,priority:2" json:"next_attempt_at"`
+	LastError     string          `json:"last_error,omitempty"`
+	DeliveredAt   *time.Time      `json:"delivered_at,omitempty"`
+}
@@ -0,0 +1,21 @@
+package model
+
+// Profile model.
+//
+// It holds the author information shown on the portfolio website.
+type Profile struct {
+	Model     `gorm:"embedded"`
+	Name      string        `gorm:"not null" json:"name"`
+	Headline  string        `json:"headline"`
+	Bio       string        `json:"bio"`
+	AvatarURL string        `json:"avatar_url"`
+	Location  string        `json:"location"`
+	Email     string        `json:"email"` // public contact address.
+	Links     []ProfileLink `gorm:"serializer:json;type:jsonb" json:"links"`
+}
+
+// ProfileLink is a link to an external profile of the author.
+type ProfileLink struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
@@ -1,6 +1,25 @@
 package model
 
+import "time"
+
 // Project model.
 type Project struct {
-	Model `gorm:"embedded"`
+	Model       `gorm:"embedded"`
+	Title       string        `gorm:"not null" json:"title"`
+	Slug        string        `gorm:"uniqueIndex;not null" json:"slug"`
+	Summary     string        `json:"summary"`
+	Description string        `json:"description"`
+	CoverURL    string        `json:"cover_url"`
+	URL         string        `json:"url"`
+	RepoURL     string        `json:"repo_url"`
+	Featured    bool          `gorm:"not null;default:false" json:"featured"`
+	Position    int           `gorm:"not null;default:0" json:"position"` // display order, ascending.
+	Status      PublishStatus `gorm:"type:varchar(32);not null;default:draft;index" json:"status"`
+	PublishedAt *time.Time    `gorm:"index" json:"published_at,omitempty"`
+	Tags        []*Tag        `gorm:"many2many:project_tags" json:"tags,omitempty"`
+}
+
+// IsPublished reports whether the project is visible to the public.
+func (m *Project) IsPublished() bool {
+	return isPublished(m.Status, m.PublishedAt)
 }
@@ -0,0 +1,8 @@
+package model
+
+// Tag model.
+type Tag struct {
+	Model `gorm:"embedded"`
+	Name  string `gorm:"not null" json:"name"`
+	Slug  string `gorm:"uniqueIndex;not null" json:"slug"`
+}
@@ -1,6 +1,41 @@
 package model
 
+import "time"
+
+// UserRole represents the role of a cms user.
+// ENUM(admin,editor)
+//
+//go:generate go-enum --marshal --names --values
+type UserRole string
+
 // User model.
 type User struct {
-	Model `gorm:"embedded"`
+	Model        `gorm:"embedded"`
+	Email        string     `gorm:"uniqueIndex;not null" json:"email"`
+	Name         string     `json:"name"`
+	PasswordHash string     `json:"-"`
+	Role         UserRole   `gorm:"type:varchar(32);not null;default:editor" json:"role"`
+	MFAEnabled   bool       `gorm:"not null;default:false" json:"mfa_enabled"`
+	MFARequired  bool       `gorm:"not null;default:false" json:"mfa_required"`
+	MFASecret    string     `json:"-"`
+	MFAEnabledAt *time.Time `json:"mfa_enabled_at,omitempty"`
+	// MFALastStep is the time step of the last accepted TOTP code, the codes
+	// up to it are rejected so that they cannot be replayed.
+	MFALastStep int64 `gorm:"not null;default:0" json:"-"`
+	// MFAFailedAttempts counts the wrong codes since the last accepted one,
+	// the second factor is locked until MFALockedUntil once it reaches the
+	// limit.
+	MFAFailedAttempts int        `gorm:"not null;default:0" json:"-"`
+	MFALockedUntil    *time.Time `json:"-"`
+}
+
+// UserRecoveryCode model.
+//
+// Only the hash of a recovery code is stored, the plain code is shown to the
+// user once when the codes are generated.
+type UserRecoveryCode struct {
+	Model    `gorm:"embedded"`
+	UserID   string     `gorm:"type:uuid;index;not null" json:"user_id"`
+	CodeHash string     `gorm:"not null" json:"-"`
+	UsedAt   *time.Time `json:"used_at,omitempty"`
 }
@@ -0,0 +1,80 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+)
+
+// OutboxEvents Repo.
+type OutboxEvents struct {
+	db *gorm.DB
+	*Common[model.OutboxEvent]
+}
+
+// NewOutboxEvents Repository.
+func NewOutboxEvents(db *gorm.DB) *OutboxEvents {
+	return &OutboxEvents{db, NewCommon[model.OutboxEvent](db)}
+}
+
+// ClaimDue claims up to limit pending events whose next attempt is due, oldest
+// first. Their next attempt is pushed back by lease so that concurrent
+// dispatchers skip them while they are delivered.
+func (r *OutboxEvents) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboxEvent, error) {
+	var (
+		res []*model.OutboxEvent
+		now = time.Now()
+	)
+
+	err := r.withCtx(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", model.OutboxStatusPending, now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&res).Error
+		if err != nil || len(res) == 0 {
+			return err
+		}
+
+		ids := make([]string, len(res))
+		for i, e := range res {
+			ids[i] = e.ID
+		}
+		return tx.Model(new(model.OutboxEvent)).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	return res, err
+}
+
+// MarkDelivered marks the event as delivered.
+func (r *OutboxEvents) MarkDelivered(ctx context.Context, id string) error {
+	return r.Update(ctx, id, map[string]any{
+		"status":       model.OutboxStatusDelivered,
+		"delivered_at": time.Now(),
+		"last_error":   "",
+	})
+}
+
+// Retry records a failed attempt and schedules the next one.
+func (r *OutboxEvents) Retry(ctx context.Context, id string, attempts int, next time.Time, lastErr string) error {
+	return r.Update(ctx, id, map[string]any{
+		"attempts":        attempts,
+		"next_attempt_at": next,
+		"last_error":      lastErr,
+	})
+}
+
+// DeadLetter records the last failed attempt and stops the delivery of the
+// event.
+func (r *OutboxEvents) DeadLetter(ctx context.Context, id string, attempts int, lastErr string) error {
+	return r.Update(ctx, id, map[string]any{
+		"status":     model.OutboxStatusDead,
+		"attempts":   attempts,
+		"last_error": lastErr,
+	})
+}
@@ -0,0 +1,48 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+	"gorm.io/gorm"
+)
+
+// PasswordResetTokens Repo.
+type PasswordResetTokens struct {
+	db *gorm.DB
+	*Common[model.PasswordResetToken]
+}
+
+// NewPasswordResetTokens Repository.
+func NewPasswordResetTokens(db *gorm.DB) *PasswordResetTokens {
+	return &PasswordResetTokens{db, NewCommon[model.PasswordResetToken](db)}
+}
+
+// GetActiveByTokenHash gets the token which is neither used nor expired by its
+// hash.
+func (r *PasswordResetTokens) GetActiveByTokenHash(ctx context.Context, hash string) (*model.PasswordResetToken, error) {
+	m := new(model.PasswordResetToken)
+	err := r.withCtx(ctx).
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", hash, time.Now()).
+		First(m).Error
+	return m, err
+}
+
+// Use marks the active token as used. It reports whether the token was still
+// active, so that a token can only be used once.
+func (r *PasswordResetTokens) Use(ctx context.Context, id string) (bool, error) {
+	res := r.withCtx(ctx).
+		Model(new(model.PasswordResetToken)).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", id, time.Now()).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// DeleteByUserID removes all tokens of the user.
+func (r *PasswordResetTokens) DeleteByUserID(ctx context.Context, userID string) error {
+	return r.withCtx(ctx).Where("user_id = ?", userID).Delete(new(model.PasswordResetToken)).Error
+}
@@ -0,0 +1,27 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+	"gorm.io/gorm"
+)
+
+// Profiles Repo.
+type Profiles struct {
+	db *gorm.DB
+	*Common[model.Profile]
+}
+
+// NewProfiles Repository.
+func NewProfiles(db *gorm.DB) *Profiles {
+	return &Profiles{db, NewCommon[model.Profile](db)}
+}
+
+// GetCurrent gets the profile of the portfolio owner, which is the first one
+// created.
+func (r *Profiles) GetCurrent(ctx context.Context) (*model.Profile, error) {
+	m := new(model.Profile)
+	err := r.withCtx(ctx).Order("created_at ASC").First(m).Error
+	return m, err
+}
@@ -1,6 +1,9 @@
 package repo
 
 import (
+	"context"
+	"time"
+
 	"github.com/cirius-go/portfolio-server/internal/repo/model"
 	"gorm.io/gorm"
 )
@@ -15,3 +18,52 @@ type Projects struct {
 func NewProjects(db *gorm.DB) *Projects {
 	return &Projects{db, NewCommon[model.Project](db)}
 }
+
+// ProjectFilter represents the filter of listing projects.
+type ProjectFilter struct {
+	FeaturedOnly bool
+	TagSlug      string
+}
+
+// ListPublished lists the published projects by position, then latest first.
+// The total is only counted if req.Count is set.
+func (r *Projects) ListPublished(ctx context.Context, req *ListingRequest[ProjectFilter]) ([]*model.Project, int64, error) {
+	var (
+		res   = make([]*model.Project, 0)
+		total = int64(0)
+	)
+
+	q := r.published(ctx)
+	if req.Filter.FeaturedOnly {
+		q = q.Where("featured = ?", true)
+	}
+	if req.Filter.TagSlug != "" {
+		q = q.Where("id IN (?)", taggedIDs(r.db, "project_tags", "project_id", req.Filter.TagSlug))
+	}
+
+	if req.Count {
+		if err := q.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, 0, err
+		}
+	}
+
+	err := WithPaging(q, req.Page, req.PerPage).
+		Preload("Tags").
+		Order("position ASC").
+		Order("published_at DESC").
+		Find(&res).Error
+	return res, total, err
+}
+
+// GetPublishedBySlug gets the published project by slug.
+func (r *Projects) GetPublishedBySlug(ctx context.Context, slug string) (*model.Project, error) {
+	m := new(model.Project)
+	err := r.published(ctx).Preload("Tags").Where("slug = ?", slug).First(m).Error
+	return m, err
+}
+
+func (r *Projects) published(ctx context.Context) *gorm.DB {
+	return r.withCtx(ctx).
+		Model(new(model.Project)).
+		Where("status = ? AND published_at <= ?", model.PublishStatusPublished, time.Now())
+}
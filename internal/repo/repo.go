@@ -2,12 +2,8 @@ package repo
 
 import (
 	"context"
-	"log"
-	"os"
-	"time"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 
 	"github.com/cirius-go/portfolio-server/internal/repo/model"
 )
@@ -21,23 +17,15 @@ func (r *Common[Model]) QuoteCol(name string) string {
 	return QuoteCol(r.db, name)
 }
 
-// withCtx sets the context.
+// withCtx sets the context. The transaction carried by ctx is used instead
+// of the repository connection, so that the repository joins it.
 func (r *Common[Model]) withCtx(ctx context.Context) *gorm.DB {
-	debug, ok := ctx.Value(model.ContextKeyDebug).(bool)
-	if !ok || !debug {
-		return r.db.WithContext(ctx)
+	db := r.db
+	if tx, ok := TxFromContext(ctx); ok {
+		db = tx
 	}
 
-	logger := logger.New(log.New(os.Stdout, "\r\n", log.LstdFlags), logger.Config{
-		SlowThreshold:             200 * time.Millisecond,
-		LogLevel:                  logger.Info,
-		IgnoreRecordNotFoundError: false,
-		Colorful:                  true,
-	})
-
-	return r.db.Session(&gorm.Session{
-		Logger: logger,
-	}).WithContext(ctx)
+	return db.WithContext(ctx)
 }
 
 // Create creates a new record.
@@ -61,7 +49,7 @@ func (r *Common[Model]) Get(ctx context.Context, m *Model) error {
 // Example: GetByID(ctx, "foo")
 func (r *Common[Model]) GetByID(ctx context.Context, id string) (*Model, error) {
 	m := new(Model)
-	err := r.withCtx(ctx).First(m, id).Error
+	err := r.withCtx(ctx).First(m, "id = ?", id).Error
 	return m, err
 }
 
@@ -80,7 +68,7 @@ func (r *Common[Model]) Delete(ctx context.Context, m *Model) error {
 // DeleteByID deletes the record by ID.
 func (r *Common[Model]) DeleteByID(ctx context.Context, id string) error {
 	m := new(Model)
-	return r.withCtx(ctx).Delete(m, id).Error
+	return r.withCtx(ctx).Delete(m, "id = ?", id).Error
 }
 
 // HardDelete hard deletes the record.
@@ -91,7 +79,7 @@ func (r *Common[Model]) HardDelete(ctx context.Context, m *Model) error {
 // HardDeleteByID hard deletes the record by ID.
 func (r *Common[Model]) HardDeleteByID(ctx context.Context, id string) error {
 	m := new(Model)
-	return r.withCtx(ctx).Unscoped().Delete(m, id).Error
+	return r.withCtx(ctx).Unscoped().Delete(m, "id = ?", id).Error
 }
 
 // Count counts the records.
@@ -120,6 +108,18 @@ func (r *Common[Model]) AllWith(ctx context.Context, res any) error {
 	return nil
 }
 
+// WithTx returns a copy of ctx carrying the transaction, repositories used
+// with it run their queries in the transaction.
+func WithTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, model.ContextKeyTx, tx)
+}
+
+// TxFromContext returns the transaction carried by ctx.
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	tx, ok := ctx.Value(model.ContextKeyTx).(*gorm.DB)
+	return tx, ok && tx != nil
+}
+
 // NewCommon creates a new repository.
 func NewCommon[Model any](db *gorm.DB) *Common[Model] {
 	return &Common[Model]{db: db}
@@ -0,0 +1,53 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+	"gorm.io/gorm"
+)
+
+// Tags Repo.
+type Tags struct {
+	db *gorm.DB
+	*Common[model.Tag]
+}
+
+// NewTags Repository.
+func NewTags(db *gorm.DB) *Tags {
+	return &Tags{db, NewCommon[model.Tag](db)}
+}
+
+// ListPublished lists the tags used by at least one published article or
+// project, ordered by name. Tags only used by drafts are left out.
+func (r *Tags) ListPublished(ctx context.Context) ([]*model.Tag, error) {
+	var (
+		res = make([]*model.Tag, 0)
+		now = time.Now()
+	)
+
+	articles := r.db.Table("article_tags").
+		Select("article_tags.tag_id").
+		Joins("JOIN articles ON articles.id = article_tags.article_id").
+		Where("articles.status = ? AND articles.published_at <= ?", model.PublishStatusPublished, now)
+	projects := r.db.Table("project_tags").
+		Select("project_tags.tag_id").
+		Joins("JOIN projects ON projects.id = project_tags.project_id").
+		Where("projects.status = ? AND projects.published_at <= ?", model.PublishStatusPublished, now)
+
+	err := r.withCtx(ctx).
+		Where("id IN (?) OR id IN (?)", articles, projects).
+		Order("name ASC").
+		Find(&res).Error
+	return res, err
+}
+
+// taggedIDs returns the subquery selecting the ids of the records, joined to
+// the tags through joinTable, which have the tag.
+func taggedIDs(db *gorm.DB, joinTable, fk, tagSlug string) *gorm.DB {
+	return db.Table(joinTable).
+		Select(joinTable+"."+fk).
+		Joins("JOIN tags ON tags.id = "+joinTable+".tag_id").
+		Where("tags.slug = ?", tagSlug)
+}
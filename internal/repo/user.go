@@ -1,6 +1,10 @@
 package repo
 
 import (
+	"context"
+	"strings"
+	"time"
+
 	"github.com/cirius-go/portfolio-server/internal/repo/model"
 	"gorm.io/gorm"
 )
@@ -15,3 +19,46 @@ type Users struct {
 func NewUsers(db *gorm.DB) *Users {
 	return &Users{db, NewCommon[model.User](db)}
 }
+
+// GetByEmail gets the user by email, the email is compared case-insensitively.
+func (r *Users) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	m := new(model.User)
+	err := r.withCtx(ctx).Where("LOWER(email) = ?", strings.ToLower(email)).First(m).Error
+	return m, err
+}
+
+// UseMFAStep stores the time step of an accepted TOTP code and clears the
+// failed attempts. It reports whether the step is newer than the last one, so
+// that a code is accepted once.
+func (r *Users) UseMFAStep(ctx context.Context, id string, step int64) (bool, error) {
+	res := r.withCtx(ctx).
+		Model(new(model.User)).
+		Where("id = ? AND mfa_last_step < ?", id, step).
+		Updates(map[string]any{"mfa_last_step": step, "mfa_failed_attempts": 0})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// FailMFA counts a wrong second factor of the user. Once maxAttempts are
+// counted, the second factor is locked until the lockout ends and the count
+// restarts. It reports whether the second factor got locked.
+func (r *Users) FailMFA(ctx context.Context, id string, maxAttempts int, lockout time.Duration) (bool, error) {
+	err := r.withCtx(ctx).
+		Model(new(model.User)).
+		Where("id = ?", id).
+		Update("mfa_failed_attempts", gorm.Expr("mfa_failed_attempts + 1")).Error
+	if err != nil {
+		return false, err
+	}
+
+	res := r.withCtx(ctx).
+		Model(new(model.User)).
+		Where("id = ? AND mfa_failed_attempts >= ?", id, maxAttempts).
+		Updates(map[string]any{"mfa_failed_attempts": 0, "mfa_locked_until": time.Now().Add(lockout)})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
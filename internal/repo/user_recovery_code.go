@@ -0,0 +1,54 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+	"gorm.io/gorm"
+)
+
+// UserRecoveryCodes Repo.
+type UserRecoveryCodes struct {
+	db *gorm.DB
+	*Common[model.UserRecoveryCode]
+}
+
+// NewUserRecoveryCodes Repository.
+func NewUserRecoveryCodes(db *gorm.DB) *UserRecoveryCodes {
+	return &UserRecoveryCodes{db, NewCommon[model.UserRecoveryCode](db)}
+}
+
+// Replace removes all recovery codes of the user and stores the given hashes.
+func (r *UserRecoveryCodes) Replace(ctx context.Context, userID string, hashes []string) error {
+	if err := r.DeleteByUserID(ctx, userID); err != nil {
+		return err
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	codes := make([]*model.UserRecoveryCode, 0, len(hashes))
+	for _, h := range hashes {
+		codes = append(codes, &model.UserRecoveryCode{UserID: userID, CodeHash: h})
+	}
+	return r.withCtx(ctx).Create(&codes).Error
+}
+
+// DeleteByUserID removes all recovery codes of the user.
+func (r *UserRecoveryCodes) DeleteByUserID(ctx context.Context, userID string) error {
+	return r.withCtx(ctx).Where("user_id = ?", userID).Delete(new(model.UserRecoveryCode)).Error
+}
+
+// Use marks an unused recovery code as used. It reports whether a matching
+// code was consumed.
+func (r *UserRecoveryCodes) Use(ctx context.Context, userID, hash string) (bool, error) {
+	res := r.withCtx(ctx).
+		Model(new(model.UserRecoveryCode)).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
@@ -0,0 +1,10 @@
+package service
+
+// Cache tags of the public content. Cached reads are tagged with them and
+// writes invalidate them.
+const (
+	CacheTagArticles = "articles"
+	CacheTagProjects = "projects"
+	CacheTagTags     = "tags"
+	CacheTagProfile  = "profile"
+)
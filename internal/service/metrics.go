@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics contains the business counters.
+type Metrics struct {
+	published *prometheus.CounterVec
+}
+
+// NewMetrics creates the counters and registers them.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		published: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "content_published_total",
+			Help: "Number of contents published or taken down, by entity.",
+		}, []string{"entity", "action"}),
+	}
+	reg.MustRegister(m.published)
+	return m
+}
+
+// OnPublish is a PublishHook counting the publications.
+func (m *Metrics) OnPublish(ctx context.Context, e PublishEvent) {
+	action := "published"
+	if !e.Published {
+		action = "unpublished"
+	}
+	m.published.WithLabelValues(e.Entity, action).Inc()
+}
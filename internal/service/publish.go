@@ -0,0 +1,30 @@
+package service
+
+import "context"
+
+// TopicPublished is the outbox topic of the publish events.
+const TopicPublished = "content.published"
+
+// PublishEvent describes a change of the content visible to the public.
+type PublishEvent struct {
+	// Entity is the kind of the content, e.g. "articles".
+	Entity string `json:"entity"`
+	// Slug identifies the content on the public site.
+	Slug string `json:"slug"`
+	// Published is false when the content was taken down.
+	Published bool `json:"published"`
+}
+
+// PublishHook is called after a content is published or unpublished. The
+// change is already committed, so hooks handle their own failures.
+type PublishHook func(ctx context.Context, e PublishEvent)
+
+// PublishHooks is a list of hooks called in order.
+type PublishHooks []PublishHook
+
+// Fire calls every hook with the event.
+func (hs PublishHooks) Fire(ctx context.Context, e PublishEvent) {
+	for _, h := range hs {
+		h(ctx, e)
+	}
+}
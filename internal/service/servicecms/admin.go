@@ -0,0 +1,123 @@
+package servicecms
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/cirius-go/portfolio-server/internal/config"
+	"github.com/cirius-go/portfolio-server/internal/dto/dtocms"
+	"github.com/cirius-go/portfolio-server/internal/repo"
+	"github.com/cirius-go/portfolio-server/internal/service"
+	"github.com/cirius-go/portfolio-server/pkg/health"
+	"github.com/cirius-go/portfolio-server/pkg/logging"
+)
+
+// Admin is a service struct that encapsulates business logic.
+type Admin struct {
+	service.Service
+	enf       RBACEnforcer
+	store     *config.Store
+	startedAt time.Time
+}
+
+// NewAdmin creates a new instance of Admin service.
+func NewAdmin(enf RBACEnforcer, store *config.Store) *Admin {
+	s := &Admin{
+		enf:       enf,
+		store:     store,
+		startedAt: time.Now(),
+	}
+	return s
+}
+
+// Runtime implements apicms.AdminService.
+func (s *Admin) Runtime(ctx context.Context, req *dtocms.RuntimeAdminReq) (*dtocms.RuntimeAdminRes, error) {
+	if _, err := authorize(ctx, s.enf, RBACObjAdmin, RBACActRead); err != nil {
+		return nil, err
+	}
+
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	res := &dtocms.RuntimeAdminRes{
+		StartedAt:  s.startedAt,
+		Uptime:     time.Since(s.startedAt).Round(time.Second).String(),
+		Goroutines: runtime.NumGoroutine(),
+		CPUs:       runtime.NumCPU(),
+		Memory: dtocms.RuntimeMemory{
+			Alloc:       m.Alloc,
+			TotalAlloc:  m.TotalAlloc,
+			Sys:         m.Sys,
+			HeapAlloc:   m.HeapAlloc,
+			HeapInuse:   m.HeapInuse,
+			HeapObjects: m.HeapObjects,
+			StackInuse:  m.StackInuse,
+		},
+		GC: dtocms.RuntimeGC{
+			NumGC:      m.NumGC,
+			PauseTotal: time.Duration(m.PauseTotalNs),
+		},
+		Build: health.Build(config.GetStage()),
+	}
+	if m.LastGC > 0 {
+		t := time.Unix(0, int64(m.LastGC))
+		res.GC.LastGC = &t
+	}
+	return res, nil
+}
+
+// Config implements apicms.AdminService.
+func (s *Admin) Config(ctx context.Context, req *dtocms.ConfigAdminReq) (*dtocms.ConfigAdminRes, error) {
+	if _, err := authorize(ctx, s.enf, RBACObjAdmin, RBACActRead); err != nil {
+		return nil, err
+	}
+
+	snap := s.store.Current()
+	return &dtocms.ConfigAdminRes{
+		Stage:    config.GetStage(),
+		Version:  snap.Version,
+		LoadedAt: snap.LoadedAt,
+		Vars:     snap.Config.Redacted(),
+	}, nil
+}
+
+// Settings implements apicms.AdminService.
+func (s *Admin) Settings(ctx context.Context, req *dtocms.SettingsAdminReq) (*dtocms.SettingsAdminRes, error) {
+	if _, err := authorize(ctx, s.enf, RBACObjAdmin, RBACActRead); err != nil {
+		return nil, err
+	}
+	return settings(), nil
+}
+
+// UpdateSettings implements apicms.AdminService.
+func (s *Admin) UpdateSettings(ctx context.Context, req *dtocms.UpdateSettingsAdminReq) (*dtocms.UpdateSettingsAdminRes, error) {
+	sess, err := authorize(ctx, s.enf, RBACObjAdmin, RBACActManage)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.LogLevel != nil {
+		logging.SetLevel(*req.LogLevel)
+	}
+	if req.Debug != nil {
+		repo.SetDebugAll(*req.Debug)
+	}
+
+	res := settings()
+	logging.FromContext(ctx).Warn("runtime settings changed", "by", sess.UserID, "log_level", res.LogLevel, "debug", res.Debug)
+	return res, nil
+}
+
+// AuthorizeProfiling implements apicms.AdminService.
+func (s *Admin) AuthorizeProfiling(ctx context.Context) error {
+	_, err := authorize(ctx, s.enf, RBACObjAdmin, RBACActManage)
+	return err
+}
+
+func settings() *dtocms.AdminSettings {
+	return &dtocms.AdminSettings{
+		LogLevel: logging.Level(),
+		Debug:    repo.DebugAll(),
+	}
+}
@@ -1,15 +1,22 @@
 package servicecms
 
 import (
+	"context"
+
+	"github.com/cirius-go/portfolio-server/internal/dto/dtocms"
+	"github.com/cirius-go/portfolio-server/internal/outbox"
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
 	"github.com/cirius-go/portfolio-server/internal/service"
 	"github.com/cirius-go/portfolio-server/internal/uow"
+	"github.com/cirius-go/portfolio-server/pkg/errors"
 )
 
 // Article is a service struct that encapsulates business logic.
 type Article struct {
 	service.Service
-	uow uow.UnitOfWork
-	enf RBACEnforcer
+	uow   uow.UnitOfWork
+	enf   RBACEnforcer
+	hooks service.PublishHooks
 }
 
 // NewArticle creates a new instance of Article service.
@@ -20,3 +27,66 @@ func NewArticle(uow uow.UnitOfWork, enf RBACEnforcer) *Article {
 	}
 	return s
 }
+
+// OnPublish adds hooks called after an article is published or unpublished.
+func (s *Article) OnPublish(hooks ...service.PublishHook) *Article {
+	s.hooks = append(s.hooks, hooks...)
+	return s
+}
+
+// Publish implements apicms.ArticleService.
+func (s *Article) Publish(ctx context.Context, req *dtocms.PublishArticleReq) (*dtocms.PublishArticleRes, error) {
+	if err := s.Validate(ctx, req); err != nil {
+		return nil, err
+	}
+	return s.setPublished(ctx, req.ID, true)
+}
+
+// Unpublish implements apicms.ArticleService.
+func (s *Article) Unpublish(ctx context.Context, req *dtocms.UnpublishArticleReq) (*dtocms.UnpublishArticleRes, error) {
+	if err := s.Validate(ctx, req); err != nil {
+		return nil, err
+	}
+	return s.setPublished(ctx, req.ID, false)
+}
+
+func (s *Article) setPublished(ctx context.Context, id string, publish bool) (*dtocms.Publication, error) {
+	if _, err := authorize(ctx, s.enf, RBACObjArticles, RBACActPublish); err != nil {
+		return nil, err
+	}
+
+	m, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	e := service.PublishEvent{Entity: RBACObjArticles, Slug: m.Slug, Published: publish}
+	err = s.uow.Transaction(ctx, func(ctx context.Context, tx uow.UnitOfWork) error {
+		if err := tx.Articles().Update(ctx, id, publishUpdates(publish, m.PublishedAt)); err != nil {
+			return err
+		}
+		if err := outbox.Enqueue(ctx, tx, service.TopicPublished, e); err != nil {
+			return err
+		}
+		tx.AfterCommit(ctx, func(ctx context.Context) { s.hooks.Fire(ctx, e) })
+		return tx.InvalidateCache(ctx, service.CacheTagArticles, service.CacheTagTags)
+	}, uow.TxO().Name("publish_article"))
+	if err != nil {
+		return nil, errors.NewInternal(err, "failed to update article")
+	}
+	if m, err = s.get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return dtocms.NewArticlePublication(m), nil
+}
+
+func (s *Article) get(ctx context.Context, id string) (*model.Article, error) {
+	m, err := s.uow.Articles().GetByID(ctx, id)
+	if errors.Is(err, uow.ErrRecordNotFound) {
+		return nil, errors.NewNotFound(err, "article not found")
+	}
+	if err != nil {
+		return nil, errors.NewInternal(err, "failed to get article")
+	}
+	return m, nil
+}
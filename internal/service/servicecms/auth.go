@@ -0,0 +1,607 @@
+package servicecms
+
+import (
+	"bytes"
+	"context"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/cirius-go/portfolio-server/internal/config"
+	"github.com/cirius-go/portfolio-server/internal/dto/dtocms"
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+	"github.com/cirius-go/portfolio-server/internal/service"
+	"github.com/cirius-go/portfolio-server/internal/uow"
+	"github.com/cirius-go/portfolio-server/pkg/errors"
+	"github.com/cirius-go/portfolio-server/pkg/jwt"
+	"github.com/cirius-go/portfolio-server/pkg/logging"
+	"github.com/cirius-go/portfolio-server/pkg/totp"
+)
+
+// Token audiences.
+const (
+	audienceAccess       = "cms:access"
+	audienceRefresh      = "cms:refresh"
+	audienceMFAChallenge = "cms:mfa-challenge"
+)
+
+// Auth errors.
+var (
+	ErrInvalidCredentials = errors.NewUnauthorized(nil, "Invalid email or password")
+	ErrInvalidMFACode     = errors.NewUnauthorized(nil, "Invalid authentication code")
+	ErrMFAAlreadyEnabled  = errors.NewConflict(nil, "Two-factor authentication is already enabled")
+	ErrMFANotEnabled      = errors.NewInvalidRequest(nil, "Two-factor authentication is not enabled")
+	ErrMFANotEnrolled     = errors.NewInvalidRequest(nil, "Two-factor authentication enrollment has not been started")
+	ErrMFARequired        = errors.NewForbidden(nil, "Two-factor authentication is required for this account")
+	ErrMFALocked          = errors.NewTooManyRequests(nil, "Too many invalid authentication codes, try again later")
+)
+
+// dummyPasswordHash is compared against when the user does not exist, so that
+// the response time does not reveal registered emails.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
+type sessionClaims struct {
+	jwt.RegisteredClaims
+	Role model.UserRole `json:"role"`
+}
+
+type challengeClaims struct {
+	jwt.RegisteredClaims
+	Enroll bool `json:"enroll,omitempty"`
+}
+
+// Auth is a service struct that encapsulates business logic.
+type Auth struct {
+	service.Service
+	uow uow.UnitOfWork
+	enf RBACEnforcer
+
+	keys atomic.Pointer[authKeys]
+	now  func() time.Time
+}
+
+// authKeys issues and verifies the tokens and the codes of Auth.
+type authKeys struct {
+	totp              *totp.TOTP
+	access            *jwt.JWT
+	refresh           *jwt.JWT
+	challenge         *jwt.JWT
+	recoveryCodeCount int
+	maxMFAAttempts    int
+	mfaLockout        time.Duration
+
+	// the previous keys only verify the tokens, until they are retired.
+	previousAccess    *previousKey
+	previousRefresh   *previousKey
+	previousChallenge *previousKey
+}
+
+// previousKey verifies the tokens of a key being rotated out until retireAt,
+// by when the tokens it issued have expired.
+type previousKey struct {
+	secret   []byte
+	jwt      *jwt.JWT
+	retireAt time.Time
+}
+
+// newAuthKeys creates the keys of the config. The previous keys of cur which
+// are still configured are kept as they are, the other ones are retired once
+// the longest lived token issued before now expires.
+func newAuthKeys(sessCfg config.Session, mfaCfg config.MFA, cur *authKeys, now time.Time) *authKeys {
+	var (
+		access = func(secret []byte) *jwt.JWT {
+			return jwt.NewJWTWithConfig(jwt.C().Alg(jwt.HS256).Secret(secret).TTL(sessCfg.TTL).Audience(audienceAccess))
+		}
+		refresh = func(secret []byte) *jwt.JWT {
+			return jwt.NewJWTWithConfig(jwt.C().Alg(jwt.HS256).Secret(secret).TTL(sessCfg.RefreshTTL).Audience(audienceRefresh))
+		}
+		challenge = func(secret []byte) *jwt.JWT {
+			return jwt.NewJWTWithConfig(jwt.C().Alg(jwt.HS256).Secret(secret).TTL(mfaCfg.ChallengeTTL).Audience(audienceMFAChallenge))
+		}
+		retireAt = now.Add(max(sessCfg.TTL, sessCfg.RefreshTTL, mfaCfg.ChallengeTTL))
+	)
+	if cur == nil {
+		cur = &authKeys{}
+	}
+
+	return &authKeys{
+		totp:              totp.New(totp.C().SetIssuer(mfaCfg.Issuer)),
+		access:            access(sessCfg.Key),
+		refresh:           refresh(sessCfg.RefreshKey),
+		challenge:         challenge(mfaCfg.ChallengeKey),
+		recoveryCodeCount: mfaCfg.RecoveryCodeCount,
+		maxMFAAttempts:    mfaCfg.MaxAttempts,
+		mfaLockout:        mfaCfg.Lockout,
+		previousAccess:    newPreviousKey(sessCfg.PreviousKey, access, cur.previousAccess, retireAt),
+		previousRefresh:   newPreviousKey(sessCfg.PreviousRefreshKey, refresh, cur.previousRefresh, retireAt),
+		previousChallenge: newPreviousKey(mfaCfg.PreviousChallengeKey, challenge, cur.previousChallenge, retireAt),
+	}
+}
+
+// newPreviousKey returns cur when it has the secret, so that its retirement
+// is not pushed back, and none without a secret.
+func newPreviousKey(secret []byte, newJWT func(secret []byte) *jwt.JWT, cur *previousKey, retireAt time.Time) *previousKey {
+	if len(secret) == 0 {
+		return nil
+	}
+	if cur != nil && bytes.Equal(cur.secret, secret) {
+		return cur
+	}
+	return &previousKey{
+		secret:   secret,
+		jwt:      newJWT(secret),
+		retireAt: retireAt,
+	}
+}
+
+// parse parses the token with the key picked from k, or with its previous
+// key until it is retired.
+func (k *authKeys) parse(key func(k *authKeys) (*jwt.JWT, *previousKey), token string, claims jwt.Claims, now time.Time) error {
+	cur, prev := key(k)
+	err := cur.ParseToken(token, claims)
+	if err != nil && prev != nil && now.Before(prev.retireAt) && prev.jwt.ParseToken(token, claims) == nil {
+		return nil
+	}
+	return err
+}
+
+// NewAuth creates a new instance of Auth service.
+func NewAuth(uow uow.UnitOfWork, enf RBACEnforcer, sessCfg config.Session, mfaCfg config.MFA) *Auth {
+	s := &Auth{
+		uow: uow,
+		enf: enf,
+		now: time.Now,
+	}
+	s.keys.Store(newAuthKeys(sessCfg, mfaCfg, nil, s.now()))
+	return s
+}
+
+// SetConfig replaces the keys and the settings of the sessions and of the
+// two-factor authentication. A previous key keeps the retirement it got when
+// it was first configured, so that the settings changes do not extend it.
+// See config.Session for the rotation of the keys.
+func (s *Auth) SetConfig(sessCfg config.Session, mfaCfg config.MFA) {
+	s.keys.Store(newAuthKeys(sessCfg, mfaCfg, s.keys.Load(), s.now()))
+}
+
+// ParseSession implements api.SessionParser.
+func (s *Auth) ParseSession(ctx context.Context, token string) (*service.Session, error) {
+	claims := &sessionClaims{}
+	if err := s.parse(accessKey, token, claims); err != nil {
+		return nil, err
+	}
+
+	return &service.Session{
+		UserID: claims.Subject,
+		Role:   claims.Role,
+	}, nil
+}
+
+// Login implements apicms.AuthService.
+func (s *Auth) Login(ctx context.Context, req *dtocms.LoginAuthReq) (*dtocms.LoginAuthRes, error) {
+	if err := s.Validate(ctx, req); err != nil {
+		return nil, err
+	}
+
+	user, err := s.uow.Users().GetByEmail(ctx, req.Email)
+	if errors.Is(err, uow.ErrRecordNotFound) {
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(req.Password))
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, errors.NewInternal(err, "failed to get user")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	if !user.MFAEnabled && !user.MFARequired {
+		sess, err := s.issueSession(user)
+		if err != nil {
+			return nil, err
+		}
+		return &dtocms.LoginAuthRes{Session: sess}, nil
+	}
+
+	// the user must pass the second factor (or enroll one first) before the
+	// session is issued.
+	challenge := s.keys.Load().challenge
+	claims := challengeClaims{
+		RegisteredClaims: challenge.NewClaims(user.ID),
+		Enroll:           !user.MFAEnabled,
+	}
+	token, err := challenge.NewToken(claims)
+	if err != nil {
+		return nil, errors.NewInternal(err, "failed to issue challenge token")
+	}
+
+	expiresAt := time.Now().Add(challenge.TTL())
+	return &dtocms.LoginAuthRes{
+		MFARequired:           true,
+		MFAEnrollmentRequired: claims.Enroll,
+		ChallengeToken:        token,
+		ChallengeExpiresAt:    &expiresAt,
+	}, nil
+}
+
+// Refresh implements apicms.AuthService.
+func (s *Auth) Refresh(ctx context.Context, req *dtocms.RefreshAuthReq) (*dtocms.RefreshAuthRes, error) {
+	if err := s.Validate(ctx, req); err != nil {
+		return nil, err
+	}
+
+	claims := &jwt.RegisteredClaims{}
+	if err := s.parse(refreshKey, req.RefreshToken, claims); err != nil {
+		return nil, err
+	}
+
+	// reload the user so that role changes and deletions take effect.
+	user, err := getUser(ctx, s.uow, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issueSession(user)
+}
+
+// EnrollChallenge implements apicms.AuthService.
+func (s *Auth) EnrollChallenge(ctx context.Context, req *dtocms.EnrollChallengeAuthReq) (*dtocms.EnrollChallengeAuthRes, error) {
+	if err := s.Validate(ctx, req); err != nil {
+		return nil, err
+	}
+
+	claims, err := s.parseChallenge(req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if !claims.Enroll {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	user, err := getUser(ctx, s.uow, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.enroll(ctx, user)
+}
+
+// VerifyChallenge implements apicms.AuthService.
+func (s *Auth) VerifyChallenge(ctx context.Context, req *dtocms.VerifyChallengeAuthReq) (*dtocms.VerifyChallengeAuthRes, error) {
+	if err := s.Validate(ctx, req); err != nil {
+		return nil, err
+	}
+
+	claims, err := s.parseChallenge(req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := getUser(ctx, s.uow, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dtocms.VerifyChallengeAuthRes{}
+	if claims.Enroll {
+		// recovery codes do not exist before the enrollment is completed.
+		if req.Code == "" {
+			return nil, ErrInvalidMFACode
+		}
+		if res.RecoveryCodes, err = s.activate(ctx, user, req.Code); err != nil {
+			return nil, err
+		}
+	} else {
+		if !user.MFAEnabled {
+			// 2FA has been reset since the challenge was issued.
+			return nil, ErrInvalidMFACode
+		}
+		if err := s.verifySecondFactor(ctx, user, req.Code, req.RecoveryCode); err != nil {
+			return nil, err
+		}
+	}
+
+	if res.Session, err = s.issueSession(user); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// EnrollMFA implements apicms.AuthService.
+func (s *Auth) EnrollMFA(ctx context.Context, req *dtocms.EnrollMFAAuthReq) (*dtocms.EnrollMFAAuthRes, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.enroll(ctx, user)
+}
+
+// ActivateMFA implements apicms.AuthService.
+func (s *Auth) ActivateMFA(ctx context.Context, req *dtocms.ActivateMFAAuthReq) (*dtocms.ActivateMFAAuthRes, error) {
+	if err := s.Validate(ctx, req); err != nil {
+		return nil, err
+	}
+
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := s.activate(ctx, user, req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtocms.ActivateMFAAuthRes{RecoveryCodes: codes}, nil
+}
+
+// RegenerateRecoveryCodes implements apicms.AuthService.
+func (s *Auth) RegenerateRecoveryCodes(ctx context.Context, req *dtocms.RegenerateRecoveryCodesAuthReq) (*dtocms.RegenerateRecoveryCodesAuthRes, error) {
+	if err := s.Validate(ctx, req); err != nil {
+		return nil, err
+	}
+
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !user.MFAEnabled {
+		return nil, ErrMFANotEnabled
+	}
+	if err := s.verifyCode(ctx, user, req.Code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := s.newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.uow.UserRecoveryCodes().Replace(ctx, user.ID, hashes); err != nil {
+		return nil, errors.NewInternal(err, "failed to store recovery codes")
+	}
+
+	return &dtocms.RegenerateRecoveryCodesAuthRes{RecoveryCodes: codes}, nil
+}
+
+// DisableMFA implements apicms.AuthService.
+func (s *Auth) DisableMFA(ctx context.Context, req *dtocms.DisableMFAAuthReq) (*dtocms.DisableMFAAuthRes, error) {
+	if err := s.Validate(ctx, req); err != nil {
+		return nil, err
+	}
+
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !user.MFAEnabled {
+		return nil, ErrMFANotEnabled
+	}
+	if user.MFARequired {
+		return nil, ErrMFARequired
+	}
+	if err := s.verifyCode(ctx, user, req.Code); err != nil {
+		return nil, err
+	}
+
+	if err := resetMFA(ctx, s.uow, user.ID); err != nil {
+		return nil, err
+	}
+
+	return &dtocms.DisableMFAAuthRes{}, nil
+}
+
+func (s *Auth) currentUser(ctx context.Context) (*model.User, error) {
+	sess, err := s.Session(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return getUser(ctx, s.uow, sess.UserID)
+}
+
+func (s *Auth) parseChallenge(token string) (*challengeClaims, error) {
+	claims := &challengeClaims{}
+	if err := s.parse(challengeKey, token, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// enroll generates a new pending secret for the user. The secret is only
+// effective after it has been activated with a valid code.
+func (s *Auth) enroll(ctx context.Context, user *model.User) (*dtocms.MFAEnrollment, error) {
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	key, err := s.keys.Load().totp.Generate(user.Email)
+	if err != nil {
+		return nil, errors.NewInternal(err, "failed to generate TOTP secret")
+	}
+
+	if err := s.uow.Users().Update(ctx, user.ID, map[string]any{"mfa_secret": key.Secret}); err != nil {
+		return nil, errors.NewInternal(err, "failed to update user")
+	}
+
+	return &dtocms.MFAEnrollment{
+		Secret: key.Secret,
+		URI:    key.URI,
+		QRCode: key.QRCode,
+	}, nil
+}
+
+// activate enables 2FA for the user once the code matches the pending secret
+// and returns the plain recovery codes.
+func (s *Auth) activate(ctx context.Context, user *model.User, code string) ([]string, error) {
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if user.MFASecret == "" {
+		return nil, ErrMFANotEnrolled
+	}
+	if err := s.verifyCode(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := s.newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.uow.Transaction(ctx, func(ctx context.Context, tx uow.UnitOfWork) error {
+		if err := tx.Users().Update(ctx, user.ID, map[string]any{
+			"mfa_enabled":    true,
+			"mfa_enabled_at": time.Now(),
+		}); err != nil {
+			return err
+		}
+		return tx.UserRecoveryCodes().Replace(ctx, user.ID, hashes)
+	})
+	if err != nil {
+		return nil, errors.NewInternal(err, "failed to enable two-factor authentication")
+	}
+
+	return codes, nil
+}
+
+func (s *Auth) verifySecondFactor(ctx context.Context, user *model.User, code, recoveryCode string) error {
+	if code != "" {
+		return s.verifyCode(ctx, user, code)
+	}
+
+	if err := checkMFALock(user); err != nil {
+		return err
+	}
+	used, err := s.uow.UserRecoveryCodes().Use(ctx, user.ID, totp.HashRecoveryCode(recoveryCode))
+	if err != nil {
+		return errors.NewInternal(err, "failed to use recovery code")
+	}
+	if !used {
+		return s.failMFA(ctx, user)
+	}
+	if err := s.uow.Users().Update(ctx, user.ID, map[string]any{"mfa_failed_attempts": 0}); err != nil {
+		return errors.NewInternal(err, "failed to update user")
+	}
+	return nil
+}
+
+// verifyCode accepts a TOTP code of the user once: the codes of the time
+// step of the last accepted one, or of an earlier step, are rejected.
+func (s *Auth) verifyCode(ctx context.Context, user *model.User, code string) error {
+	if err := checkMFALock(user); err != nil {
+		return err
+	}
+
+	step, ok := s.keys.Load().totp.ValidateStep(code, user.MFASecret, user.MFALastStep)
+	if !ok {
+		return s.failMFA(ctx, user)
+	}
+	// a concurrent request may have used the same code.
+	used, err := s.uow.Users().UseMFAStep(ctx, user.ID, step)
+	if err != nil {
+		return errors.NewInternal(err, "failed to update user")
+	}
+	if !used {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+// failMFA counts a wrong second factor of the user, which gets locked after
+// too many of them.
+func (s *Auth) failMFA(ctx context.Context, user *model.User) error {
+	k := s.keys.Load()
+	locked, err := s.uow.Users().FailMFA(ctx, user.ID, k.maxMFAAttempts, k.mfaLockout)
+	if err != nil {
+		return errors.NewInternal(err, "failed to update user")
+	}
+	if locked {
+		logging.FromContext(ctx).Warn("locked two-factor authentication after too many invalid codes", "user_id", user.ID)
+		return ErrMFALocked
+	}
+	return ErrInvalidMFACode
+}
+
+// checkMFALock fails while the second factor of the user is locked.
+func checkMFALock(user *model.User) error {
+	if user.MFALockedUntil != nil && user.MFALockedUntil.After(time.Now()) {
+		return ErrMFALocked
+	}
+	return nil
+}
+
+func (s *Auth) newRecoveryCodes() (codes, hashes []string, err error) {
+	codes, err = totp.NewRecoveryCodes(s.keys.Load().recoveryCodeCount)
+	if err != nil {
+		return nil, nil, errors.NewInternal(err, "failed to generate recovery codes")
+	}
+
+	hashes = make([]string, 0, len(codes))
+	for _, c := range codes {
+		hashes = append(hashes, totp.HashRecoveryCode(c))
+	}
+	return codes, hashes, nil
+}
+
+func (s *Auth) issueSession(user *model.User) (*dtocms.SessionToken, error) {
+	k := s.keys.Load()
+	access, err := k.access.NewToken(sessionClaims{
+		RegisteredClaims: k.access.NewClaims(user.ID),
+		Role:             user.Role,
+	})
+	if err != nil {
+		return nil, errors.NewInternal(err, "failed to issue access token")
+	}
+
+	refresh, err := k.refresh.NewToken(k.refresh.NewClaims(user.ID))
+	if err != nil {
+		return nil, errors.NewInternal(err, "failed to issue refresh token")
+	}
+
+	return &dtocms.SessionToken{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		ExpiresAt:    time.Now().Add(k.access.TTL()),
+	}, nil
+}
+
+func (s *Auth) parse(key func(k *authKeys) (*jwt.JWT, *previousKey), token string, claims jwt.Claims) error {
+	return s.keys.Load().parse(key, token, claims, s.now())
+}
+
+func accessKey(k *authKeys) (*jwt.JWT, *previousKey)    { return k.access, k.previousAccess }
+func refreshKey(k *authKeys) (*jwt.JWT, *previousKey)   { return k.refresh, k.previousRefresh }
+func challengeKey(k *authKeys) (*jwt.JWT, *previousKey) { return k.challenge, k.previousChallenge }
+
+// getUser gets the user by id and maps a missing record to a not found error.
+func getUser(ctx context.Context, u uow.UnitOfWork, id string) (*model.User, error) {
+	user, err := u.Users().GetByID(ctx, id)
+	if errors.Is(err, uow.ErrRecordNotFound) {
+		return nil, errors.NewNotFound(err, "user not found")
+	}
+	if err != nil {
+		return nil, errors.NewInternal(err, "failed to get user")
+	}
+	return user, nil
+}
+
+// resetMFA disables 2FA for the user and removes its secret and recovery codes.
+func resetMFA(ctx context.Context, u uow.UnitOfWork, userID string) error {
+	err := u.Transaction(ctx, func(ctx context.Context, tx uow.UnitOfWork) error {
+		if err := tx.Users().Update(ctx, userID, map[string]any{
+			"mfa_enabled":    false,
+			"mfa_secret":     "",
+			"mfa_enabled_at": nil,
+		}); err != nil {
+			return err
+		}
+		return tx.UserRecoveryCodes().DeleteByUserID(ctx, userID)
+	})
+	if err != nil {
+		return errors.NewInternal(err, "failed to reset two-factor authentication")
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package servicecms
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cirius-go/portfolio-server/internal/config"
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+	"github.com/cirius-go/portfolio-server/internal/uow/uowfake"
+	"github.com/cirius-go/portfolio-server/pkg/jwt"
+)
+
+func TestAuthKeyRotation(t *testing.T) {
+	var (
+		keyA = []byte("session-key-a-0123456789abcdefghij")
+		keyB = []byte("session-key-b-0123456789abcdefghij")
+		base = config.C()
+		t0   = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+		// the longest lived tokens are the refresh ones.
+		retirement = max(base.CMSSession.TTL, base.CMSSession.RefreshTTL, base.CMSMFA.ChallengeTTL)
+	)
+	session := func(key, previous []byte) config.Session {
+		s := base.CMSSession
+		s.Key, s.PreviousKey = key, previous
+		return s
+	}
+	token := func(key []byte) string {
+		t.Helper()
+		j := jwt.NewJWTWithConfig(jwt.C().Alg(jwt.HS256).Secret(key).TTL(time.Hour).Audience(audienceAccess))
+		tok, err := j.NewToken(sessionClaims{RegisteredClaims: j.NewClaims("u1"), Role: model.UserRoleEditor})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tok
+	}
+	var (
+		tokenA = token(keyA)
+		tokenB = token(keyB)
+	)
+
+	// the steps run in order on the same service.
+	steps := []struct {
+		name    string
+		at      time.Duration
+		session *config.Session
+		mfa     func(m *config.MFA)
+		// wantValid maps the tokens to whether they are accepted.
+		wantValid map[string]bool
+	}{
+		{
+			name:      "signing with a",
+			wantValid: map[string]bool{tokenA: true, tokenB: false},
+		},
+		{
+			name:      "b added as verify-only",
+			session:   &config.Session{Key: keyA, PreviousKey: keyB},
+			wantValid: map[string]bool{tokenA: true, tokenB: true},
+		},
+		{
+			name:      "signing with b, a verify-only",
+			at:        5 * time.Minute,
+			session:   &config.Session{Key: keyB, PreviousKey: keyA},
+			wantValid: map[string]bool{tokenA: true, tokenB: true},
+		},
+		{
+			name:      "a settings change does not extend a",
+			at:        retirement,
+			mfa:       func(m *config.MFA) { m.RecoveryCodeCount++ },
+			wantValid: map[string]bool{tokenA: true, tokenB: true},
+		},
+		{
+			name:      "a retired",
+			at:        5*time.Minute + retirement,
+			wantValid: map[string]bool{tokenA: false, tokenB: true},
+		},
+		{
+			name:      "a cleared",
+			at:        5*time.Minute + retirement,
+			session:   &config.Session{Key: keyB},
+			wantValid: map[string]bool{tokenA: false, tokenB: true},
+		},
+		{
+			name:      "a set again is accepted again",
+			at:        5*time.Minute + retirement,
+			session:   &config.Session{Key: keyB, PreviousKey: keyA},
+			wantValid: map[string]bool{tokenA: true, tokenB: true},
+		},
+		{
+			name:      "a cleared is dropped at once",
+			at:        6*time.Minute + retirement,
+			session:   &config.Session{Key: keyB},
+			wantValid: map[string]bool{tokenA: false, tokenB: true},
+		},
+	}
+
+	var (
+		now  = t0
+		sess = session(keyA, nil)
+		mfa  = base.CMSMFA
+		s    = NewAuth(uowfake.New(), nil, sess, mfa)
+	)
+	s.now = func() time.Time { return now }
+	for _, st := range steps {
+		now = t0.Add(st.at)
+		if st.session != nil || st.mfa != nil {
+			if st.session != nil {
+				sess = session(st.session.Key, st.session.PreviousKey)
+			}
+			if st.mfa != nil {
+				st.mfa(&mfa)
+			}
+			s.SetConfig(sess, mfa)
+		}
+
+		for tok, want := range st.wantValid {
+			_, err := s.ParseSession(context.Background(), tok)
+			if got := err == nil; got != want {
+				name := "a"
+				if tok == tokenB {
+					name = "b"
+				}
+				t.Errorf("%s: token of %s accepted = %v, want %v", st.name, name, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package servicecms_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	otptotp "github.com/pquerna/otp/totp"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/cirius-go/portfolio-server/internal/config"
+	"github.com/cirius-go/portfolio-server/internal/dto/dtocms"
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+	"github.com/cirius-go/portfolio-server/internal/service/servicecms"
+	"github.com/cirius-go/portfolio-server/internal/uow/uowfake"
+)
+
+const password = "correct horse battery staple"
+
+// newAuth returns the auth service on a fake unit of work seeded with the
+// users.
+func newAuth(t *testing.T, users ...*model.User) (*servicecms.Auth, *uowfake.UnitOfWork) {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, u := range users {
+		u.PasswordHash = string(hash)
+	}
+
+	u := uowfake.New()
+	uowfake.Seed(u, users...)
+	cfg := config.C()
+	return servicecms.NewAuth(u, nil, cfg.CMSSession, cfg.CMSMFA), u
+}
+
+func newUser(id string, mfa bool) *model.User {
+	u := &model.User{Email: id + "@example.com", Role: model.UserRoleEditor}
+	u.ID = id
+	if mfa {
+		u.MFAEnabled = true
+		u.MFASecret = "JBSWY3DPEHPK3PXP"
+	}
+	return u
+}
+
+func code(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+	c, err := otptotp.GenerateCode(secret, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestAuthLogin(t *testing.T) {
+	ctx := context.Background()
+	auth, _ := newAuth(t, newUser("plain", false), newUser("mfa", true))
+
+	tests := []struct {
+		name      string
+		req       *dtocms.LoginAuthReq
+		wantErr   error
+		wantMFA   bool
+		wantToken bool
+	}{
+		{name: "unknown email", req: &dtocms.LoginAuthReq{Email: "nobody@example.com", Password: password}, wantErr: servicecms.ErrInvalidCredentials},
+		{name: "wrong password", req: &dtocms.LoginAuthReq{Email: "plain@example.com", Password: "wrong"}, wantErr: servicecms.ErrInvalidCredentials},
+		{name: "session", req: &dtocms.LoginAuthReq{Email: "PLAIN@example.com", Password: password}, wantToken: true},
+		{name: "challenge", req: &dtocms.LoginAuthReq{Email: "mfa@example.com", Password: password}, wantMFA: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := auth.Login(ctx, tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Login() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if res.MFARequired != tt.wantMFA || (res.ChallengeToken != "") != tt.wantMFA {
+				t.Errorf("Login() mfa required = %v, challenge %q", res.MFARequired, res.ChallengeToken)
+			}
+			if (res.Session != nil) != tt.wantToken {
+				t.Fatalf("Login() session = %v, want one: %v", res.Session, tt.wantToken)
+			}
+			if res.Session == nil {
+				return
+			}
+			sess, err := auth.ParseSession(ctx, res.Session.AccessToken)
+			if err != nil || sess.UserID != "plain" {
+				t.Errorf("ParseSession() = %v, %v", sess, err)
+			}
+		})
+	}
+}
+
+func TestAuthVerifyChallenge(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		user = newUser("mfa", true)
+	)
+
+	challenge := func(t *testing.T, auth *servicecms.Auth) string {
+		t.Helper()
+		res, err := auth.Login(ctx, &dtocms.LoginAuthReq{Email: user.Email, Password: password})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.ChallengeToken
+	}
+	verify := func(auth *servicecms.Auth, token, code string) error {
+		_, err := auth.VerifyChallenge(ctx, &dtocms.VerifyChallengeAuthReq{ChallengeToken: token, Code: code})
+		return err
+	}
+
+	t.Run("a code is accepted once", func(t *testing.T) {
+		auth, _ := newAuth(t, newUser("mfa", true))
+		c := code(t, user.MFASecret, time.Now())
+		if err := verify(auth, challenge(t, auth), c); err != nil {
+			t.Fatalf("VerifyChallenge() error = %v", err)
+		}
+		if err := verify(auth, challenge(t, auth), c); !errors.Is(err, servicecms.ErrInvalidMFACode) {
+			t.Fatalf("VerifyChallenge() of a replayed code error = %v, want %v", err, servicecms.ErrInvalidMFACode)
+		}
+		// the code of an earlier step is not accepted either.
+		if err := verify(auth, challenge(t, auth), code(t, user.MFASecret, time.Now().Add(-30*time.Second))); !errors.Is(err, servicecms.ErrInvalidMFACode) {
+			t.Fatalf("VerifyChallenge() of an earlier code error = %v, want %v", err, servicecms.ErrInvalidMFACode)
+		}
+	})
+
+	t.Run("too many invalid codes lock the second factor", func(t *testing.T) {
+		auth, u := newAuth(t, newUser("mfa", true))
+		token := challenge(t, auth)
+		for i := 1; i < config.C().CMSMFA.MaxAttempts; i++ {
+			if err := verify(auth, token, "000000"); !errors.Is(err, servicecms.ErrInvalidMFACode) {
+				t.Fatalf("attempt %d error = %v, want %v", i, err, servicecms.ErrInvalidMFACode)
+			}
+		}
+		if err := verify(auth, token, "000000"); !errors.Is(err, servicecms.ErrMFALocked) {
+			t.Fatalf("last attempt error = %v, want %v", err, servicecms.ErrMFALocked)
+		}
+		if err := verify(auth, token, code(t, user.MFASecret, time.Now())); !errors.Is(err, servicecms.ErrMFALocked) {
+			t.Fatalf("valid code while locked error = %v, want %v", err, servicecms.ErrMFALocked)
+		}
+
+		got, _ := u.Users().GetByID(ctx, "mfa")
+		if got.MFALockedUntil == nil || !got.MFALockedUntil.After(time.Now()) {
+			t.Errorf("locked until = %v, want a time in the future", got.MFALockedUntil)
+		}
+	})
+
+	t.Run("enrollment", func(t *testing.T) {
+		required := newUser("new", false)
+		required.MFARequired = true
+		auth, _ := newAuth(t, required)
+
+		res, err := auth.Login(ctx, &dtocms.LoginAuthReq{Email: required.Email, Password: password})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.MFAEnrollmentRequired {
+			t.Fatalf("Login() did not require the enrollment")
+		}
+		enrollment, err := auth.EnrollChallenge(ctx, &dtocms.EnrollChallengeAuthReq{ChallengeToken: res.ChallengeToken})
+		if err != nil {
+			t.Fatal(err)
+		}
+		verified, err := auth.VerifyChallenge(ctx, &dtocms.VerifyChallengeAuthReq{
+			ChallengeToken: res.ChallengeToken,
+			Code:           code(t, enrollment.Secret, time.Now()),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if verified.Session == nil || len(verified.RecoveryCodes) != config.C().CMSMFA.RecoveryCodeCount {
+			t.Fatalf("VerifyChallenge() = %+v, want a session and the recovery codes", verified)
+		}
+
+		// a recovery code signs in once.
+		recovery := func() error {
+			res, err := auth.Login(ctx, &dtocms.LoginAuthReq{Email: required.Email, Password: password})
+			if err != nil {
+				return err
+			}
+			_, err = auth.VerifyChallenge(ctx, &dtocms.VerifyChallengeAuthReq{
+				ChallengeToken: res.ChallengeToken,
+				RecoveryCode:   verified.RecoveryCodes[0],
+			})
+			return err
+		}
+		if err := recovery(); err != nil {
+			t.Fatalf("VerifyChallenge() with a recovery code error = %v", err)
+		}
+		if err := recovery(); !errors.Is(err, servicecms.ErrInvalidMFACode) {
+			t.Fatalf("VerifyChallenge() with a used recovery code error = %v, want %v", err, servicecms.ErrInvalidMFACode)
+		}
+	})
+}
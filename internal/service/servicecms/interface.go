@@ -1,4 +1,6 @@
 package servicecms
 
 // RBACEnforcer represents the RBAC enforcer interface
-type RBACEnforcer interface{}
+type RBACEnforcer interface {
+	Enforce(rvals ...any) bool
+}
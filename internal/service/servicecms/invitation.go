@@ -0,0 +1,181 @@
+package servicecms
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/cirius-go/portfolio-server/internal/config"
+	"github.com/cirius-go/portfolio-server/internal/dto/dtocms"
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+	"github.com/cirius-go/portfolio-server/internal/service"
+	"github.com/cirius-go/portfolio-server/internal/uow"
+	"github.com/cirius-go/portfolio-server/pkg/errors"
+	"github.com/cirius-go/portfolio-server/pkg/mailer"
+	"github.com/cirius-go/portfolio-server/util"
+)
+
+// Invitation errors.
+var (
+	ErrInvalidInvitation = errors.NewInvalidRequest(nil, "The invitation is invalid or has expired")
+	ErrUserAlreadyExists = errors.NewConflict(nil, "A user with this email already exists")
+)
+
+// Invitation is a service struct that encapsulates business logic.
+type Invitation struct {
+	service.Service
+	uow    uow.UnitOfWork
+	enf    RBACEnforcer
+	mailer mailer.Mailer
+	cfg    config.Onboarding
+}
+
+// NewInvitation creates a new instance of Invitation service.
+func NewInvitation(uow uow.UnitOfWork, enf RBACEnforcer, m mailer.Mailer, cfg config.Onboarding) *Invitation {
+	s := &Invitation{
+		uow:    uow,
+		enf:    enf,
+		mailer: m,
+		cfg:    cfg,
+	}
+	return s
+}
+
+// Create implements apicms.InvitationService.
+func (s *Invitation) Create(ctx context.Context, req *dtocms.CreateInvitationReq) (*dtocms.CreateInvitationRes, error) {
+	if err := s.Validate(ctx, req); err != nil {
+		return nil, err
+	}
+	sess, err := authorize(ctx, s.enf, RBACObjInvitations, RBACActCreate)
+	if err != nil {
+		return nil, err
+	}
+
+	inv, err := s.invite(ctx, req.Email, req.Name, req.Role, &sess.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return dtocms.NewInvitation(inv), nil
+}
+
+// Accept implements apicms.InvitationService.
+func (s *Invitation) Accept(ctx context.Context, req *dtocms.AcceptInvitationReq) (*dtocms.AcceptInvitationRes, error) {
+	if err := s.Validate(ctx, req); err != nil {
+		return nil, err
+	}
+
+	inv, err := s.uow.Invitations().GetPendingByTokenHash(ctx, util.HashToken(req.Token))
+	if errors.Is(err, uow.ErrRecordNotFound) {
+		return nil, ErrInvalidInvitation
+	}
+	if err != nil {
+		return nil, errors.NewInternal(err, "failed to get invitation")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, errors.NewInternal(err, "failed to hash password")
+	}
+
+	name := req.Name
+	if name == "" {
+		name = inv.Name
+	}
+	user := &model.User{
+		Email:        strings.ToLower(inv.Email),
+		Name:         name,
+		Role:         inv.Role,
+		PasswordHash: string(hash),
+	}
+	err = s.uow.Transaction(ctx, func(ctx context.Context, tx uow.UnitOfWork) error {
+		accepted, err := tx.Invitations().Accept(ctx, inv.ID)
+		if err != nil {
+			return err
+		}
+		if !accepted {
+			return ErrInvalidInvitation
+		}
+
+		if _, err := tx.Users().GetByEmail(ctx, inv.Email); err == nil {
+			return ErrUserAlreadyExists
+		} else if !errors.Is(err, uow.ErrRecordNotFound) {
+			return err
+		}
+
+		return tx.Users().Create(ctx, user)
+	})
+	if err != nil {
+		var appErr *errors.AppError
+		if errors.As(err, &appErr) {
+			return nil, appErr
+		}
+		return nil, errors.NewInternal(err, "failed to accept invitation")
+	}
+
+	return dtocms.NewUser(user), nil
+}
+
+// Bootstrap invites the first admin when no user with the email exists yet.
+// It is a no-op if the email is empty, the user exists or an invitation is
+// still pending.
+func (s *Invitation) Bootstrap(ctx context.Context, email string) error {
+	if email == "" {
+		return nil
+	}
+
+	if _, err := s.uow.Users().GetByEmail(ctx, email); err == nil {
+		return nil
+	} else if !errors.Is(err, uow.ErrRecordNotFound) {
+		return err
+	}
+
+	pending, err := s.uow.Invitations().CountPendingByEmail(ctx, email)
+	if err != nil || pending > 0 {
+		return err
+	}
+
+	_, err = s.invite(ctx, email, "", model.UserRoleAdmin, nil)
+	return err
+}
+
+// invite replaces the pending invitations of the email with a new one and
+// emails the invitation link.
+func (s *Invitation) invite(ctx context.Context, email, name string, role model.UserRole, invitedBy *string) (*model.Invitation, error) {
+	if _, err := s.uow.Users().GetByEmail(ctx, email); err == nil {
+		return nil, ErrUserAlreadyExists
+	} else if !errors.Is(err, uow.ErrRecordNotFound) {
+		return nil, errors.NewInternal(err, "failed to get user")
+	}
+
+	token, hash, err := util.NewToken(32)
+	if err != nil {
+		return nil, errors.NewInternal(err, "failed to generate invitation token")
+	}
+
+	inv := &model.Invitation{
+		Email:       strings.ToLower(email),
+		Name:        name,
+		Role:        role,
+		TokenHash:   hash,
+		InvitedByID: invitedBy,
+		ExpiresAt:   time.Now().Add(s.cfg.InvitationTTL),
+	}
+	err = s.uow.Transaction(ctx, func(ctx context.Context, tx uow.UnitOfWork) error {
+		if err := tx.Invitations().DeletePendingByEmail(ctx, email); err != nil {
+			return err
+		}
+		return tx.Invitations().Create(ctx, inv)
+	})
+	if err != nil {
+		return nil, errors.NewInternal(err, "failed to create invitation")
+	}
+
+	link := mkLink(s.cfg.BaseURL, "/invitations/accept", token)
+	if err := s.mailer.Send(ctx, newInvitationMail(inv.Email, link, inv.ExpiresAt)); err != nil {
+		return nil, errors.NewUpstream(err, "failed to send invitation to %s", inv.Email)
+	}
+
+	return inv, nil
+}
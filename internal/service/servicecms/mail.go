@@ -0,0 +1,48 @@
+package servicecms
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cirius-go/portfolio-server/pkg/mailer"
+)
+
+// mkLink builds a link to the cms frontend with the token as query parameter.
+func mkLink(baseURL, path, token string) string {
+	return fmt.Sprintf("%s%s?token=%s", strings.TrimRight(baseURL, "/"), path, url.QueryEscape(token))
+}
+
+func newInvitationMail(to, link string, expiresAt time.Time) *mailer.Message {
+	return &mailer.Message{
+		To:      []string{to},
+		Subject: "You have been invited to the portfolio CMS",
+		Text: fmt.Sprintf(`Hello,
+
+You have been invited to join the portfolio CMS. Open the link below to set
+your password and activate your account:
+
+%s
+
+The invitation expires at %s.
+`, link, expiresAt.UTC().Format(time.RFC1123)),
+	}
+}
+
+func newPasswordResetMail(to, link string, expiresAt time.Time) *mailer.Message {
+	return &mailer.Message{
+		To:      []string{to},
+		Subject: "Reset your portfolio CMS password",
+		Text: fmt.Sprintf(`Hello,
+
+We received a request to reset your password. Open the link below to choose
+a new one:
+
+%s
+
+The link expires at %s. If you did not request a password reset, you can
+ignore this email.
+`, link, expiresAt.UTC().Format(time.RFC1123)),
+	}
+}
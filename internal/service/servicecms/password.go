@@ -0,0 +1,134 @@
+package servicecms
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/cirius-go/portfolio-server/internal/config"
+	"github.com/cirius-go/portfolio-server/internal/dto/dtocms"
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+	"github.com/cirius-go/portfolio-server/internal/service"
+	"github.com/cirius-go/portfolio-server/internal/uow"
+	"github.com/cirius-go/portfolio-server/pkg/errors"
+	"github.com/cirius-go/portfolio-server/pkg/logging"
+	"github.com/cirius-go/portfolio-server/pkg/mailer"
+	"github.com/cirius-go/portfolio-server/util"
+)
+
+// ErrInvalidResetToken is returned when the password reset link is unknown,
+// used or expired.
+var ErrInvalidResetToken = errors.NewInvalidRequest(nil, "The password reset link is invalid or has expired")
+
+// Password is a service struct that encapsulates business logic.
+type Password struct {
+	service.Service
+	uow    uow.UnitOfWork
+	enf    RBACEnforcer
+	mailer mailer.Mailer
+	cfg    config.Onboarding
+}
+
+// NewPassword creates a new instance of Password service.
+func NewPassword(uow uow.UnitOfWork, enf RBACEnforcer, m mailer.Mailer, cfg config.Onboarding) *Password {
+	s := &Password{
+		uow:    uow,
+		enf:    enf,
+		mailer: m,
+		cfg:    cfg,
+	}
+	return s
+}
+
+// Forgot implements apicms.PasswordService.
+//
+// It always succeeds so that the response does not reveal whether the email
+// belongs to a user.
+func (s *Password) Forgot(ctx context.Context, req *dtocms.ForgotPasswordReq) (*dtocms.ForgotPasswordRes, error) {
+	if err := s.Validate(ctx, req); err != nil {
+		return nil, err
+	}
+
+	user, err := s.uow.Users().GetByEmail(ctx, req.Email)
+	if errors.Is(err, uow.ErrRecordNotFound) {
+		return &dtocms.ForgotPasswordRes{}, nil
+	}
+	if err != nil {
+		return nil, errors.NewInternal(err, "failed to get user")
+	}
+
+	token, hash, err := util.NewToken(32)
+	if err != nil {
+		return nil, errors.NewInternal(err, "failed to generate reset token")
+	}
+
+	prt := &model.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(s.cfg.PasswordResetTTL),
+	}
+	err = s.uow.Transaction(ctx, func(ctx context.Context, tx uow.UnitOfWork) error {
+		if err := tx.PasswordResetTokens().DeleteByUserID(ctx, user.ID); err != nil {
+			return err
+		}
+		return tx.PasswordResetTokens().Create(ctx, prt)
+	})
+	if err != nil {
+		return nil, errors.NewInternal(err, "failed to create reset token")
+	}
+
+	link := mkLink(s.cfg.BaseURL, "/password/reset", token)
+	if err := s.mailer.Send(ctx, newPasswordResetMail(user.Email, link, prt.ExpiresAt)); err != nil {
+		// a failed delivery must look the same as an unknown email.
+		logging.FromContext(ctx).Error("failed to send password reset mail", "error", err)
+	}
+
+	return &dtocms.ForgotPasswordRes{}, nil
+}
+
+// Reset implements apicms.PasswordService.
+func (s *Password) Reset(ctx context.Context, req *dtocms.ResetPasswordReq) (*dtocms.ResetPasswordRes, error) {
+	if err := s.Validate(ctx, req); err != nil {
+		return nil, err
+	}
+
+	prt, err := s.uow.PasswordResetTokens().GetActiveByTokenHash(ctx, util.HashToken(req.Token))
+	if errors.Is(err, uow.ErrRecordNotFound) {
+		return nil, ErrInvalidResetToken
+	}
+	if err != nil {
+		return nil, errors.NewInternal(err, "failed to get reset token")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, errors.NewInternal(err, "failed to hash password")
+	}
+
+	err = s.uow.Transaction(ctx, func(ctx context.Context, tx uow.UnitOfWork) error {
+		used, err := tx.PasswordResetTokens().Use(ctx, prt.ID)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidResetToken
+		}
+
+		if err := tx.Users().Update(ctx, prt.UserID, map[string]any{
+			"password_hash": string(hash),
+		}); err != nil {
+			return err
+		}
+		return tx.PasswordResetTokens().DeleteByUserID(ctx, prt.UserID)
+	})
+	if err != nil {
+		var appErr *errors.AppError
+		if errors.As(err, &appErr) {
+			return nil, appErr
+		}
+		return nil, errors.NewInternal(err, "failed to reset password")
+	}
+
+	return &dtocms.ResetPasswordRes{}, nil
+}
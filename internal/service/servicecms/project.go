@@ -1,15 +1,22 @@
 package servicecms
 
 import (
+	"context"
+
+	"github.com/cirius-go/portfolio-server/internal/dto/dtocms"
+	"github.com/cirius-go/portfolio-server/internal/outbox"
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
 	"github.com/cirius-go/portfolio-server/internal/service"
 	"github.com/cirius-go/portfolio-server/internal/uow"
+	"github.com/cirius-go/portfolio-server/pkg/errors"
 )
 
 // Project is a service struct that encapsulates business logic.
 type Project struct {
 	service.Service
-	uow uow.UnitOfWork
-	enf RBACEnforcer
+	uow   uow.UnitOfWork
+	enf   RBACEnforcer
+	hooks service.PublishHooks
 }
 
 // NewProject creates a new instance of Project service.
@@ -20,3 +27,66 @@ func NewProject(uow uow.UnitOfWork, enf RBACEnforcer) *Project {
 	}
 	return s
 }
+
+// OnPublish adds hooks called after an project is published or unpublished.
+func (s *Project) OnPublish(hooks ...service.PublishHook) *Project {
+	s.hooks = append(s.hooks, hooks...)
+	return s
+}
+
+// Publish implements apicms.ProjectService.
+func (s *Project) Publish(ctx context.Context, req *dtocms.PublishProjectReq) (*dtocms.PublishProjectRes, error) {
+	if err := s.Validate(ctx, req); err != nil {
+		return nil, err
+	}
+	return s.setPublished(ctx, req.ID, true)
+}
+
+// Unpublish implements apicms.ProjectService.
+func (s *Project) Unpublish(ctx context.Context, req *dtocms.UnpublishProjectReq) (*dtocms.UnpublishProjectRes, error) {
+	if err := s.Validate(ctx, req); err != nil {
+		return nil, err
+	}
+	return s.setPublished(ctx, req.ID, false)
+}
+
+func (s *Project) setPublished(ctx context.Context, id string, publish bool) (*dtocms.Publication, error) {
+	if _, err := authorize(ctx, s.enf, RBACObjProjects, RBACActPublish); err != nil {
+		return nil, err
+	}
+
+	m, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	e := service.PublishEvent{Entity: RBACObjProjects, Slug: m.Slug, Published: publish}
+	err = s.uow.Transaction(ctx, func(ctx context.Context, tx uow.UnitOfWork) error {
+		if err := tx.Projects().Update(ctx, id, publishUpdates(publish, m.PublishedAt)); err != nil {
+			return err
+		}
+		if err := outbox.Enqueue(ctx, tx, service.TopicPublished, e); err != nil {
+			return err
+		}
+		tx.AfterCommit(ctx, func(ctx context.Context) { s.hooks.Fire(ctx, e) })
+		return tx.InvalidateCache(ctx, service.CacheTagProjects, service.CacheTagTags)
+	}, uow.TxO().Name("publish_project"))
+	if err != nil {
+		return nil, errors.NewInternal(err, "failed to update project")
+	}
+	if m, err = s.get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return dtocms.NewProjectPublication(m), nil
+}
+
+func (s *Project) get(ctx context.Context, id string) (*model.Project, error) {
+	m, err := s.uow.Projects().GetByID(ctx, id)
+	if errors.Is(err, uow.ErrRecordNotFound) {
+		return nil, errors.NewNotFound(err, "project not found")
+	}
+	if err != nil {
+		return nil, errors.NewInternal(err, "failed to get project")
+	}
+	return m, nil
+}
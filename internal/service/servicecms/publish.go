@@ -0,0 +1,21 @@
+package servicecms
+
+import (
+	"time"
+
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+)
+
+// publishUpdates returns the column updates which publish or unpublish a
+// content. The first publish date is kept when a content is published again.
+func publishUpdates(publish bool, publishedAt *time.Time) map[string]any {
+	if !publish {
+		return map[string]any{"status": model.PublishStatusDraft}
+	}
+
+	updates := map[string]any{"status": model.PublishStatusPublished}
+	if publishedAt == nil {
+		updates["published_at"] = time.Now()
+	}
+	return updates
+}
@@ -0,0 +1,46 @@
+package servicecms
+
+import (
+	"context"
+
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+	"github.com/cirius-go/portfolio-server/internal/service"
+)
+
+// RBAC objects of the cms.
+const (
+	RBACObjUsers       = "users"
+	RBACObjInvitations = "invitations"
+	RBACObjArticles    = "articles"
+	RBACObjProjects    = "projects"
+	RBACObjAdmin       = "admin"
+)
+
+// RBAC actions of the cms.
+const (
+	RBACActCreate    = "create"
+	RBACActPublish   = "publish"
+	RBACActManageMFA = "manage_mfa"
+	RBACActRead      = "read"
+	RBACActManage    = "manage"
+)
+
+// DefaultPolicies returns the built-in RBAC policies (sub, obj, act) of the cms.
+func DefaultPolicies() [][]any {
+	return [][]any{
+		{model.UserRoleAdmin.String(), "*", "*"},
+	}
+}
+
+// authorize returns the session of the request if its role is allowed to
+// perform act on obj.
+func authorize(ctx context.Context, enf RBACEnforcer, obj, act string) (*service.Session, error) {
+	sess, ok := service.SessionFromContext(ctx)
+	if !ok {
+		return nil, service.ErrMissingSession
+	}
+	if !enf.Enforce(sess.Role.String(), obj, act) {
+		return nil, service.ErrForbiddenAction
+	}
+	return sess, nil
+}
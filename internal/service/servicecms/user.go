@@ -1,8 +1,12 @@
 package servicecms
 
 import (
+	"context"
+
+	"github.com/cirius-go/portfolio-server/internal/dto/dtocms"
 	"github.com/cirius-go/portfolio-server/internal/service"
 	"github.com/cirius-go/portfolio-server/internal/uow"
+	"github.com/cirius-go/portfolio-server/pkg/errors"
 )
 
 // User is a service struct that encapsulates business logic.
@@ -20,3 +24,49 @@ func NewUser(uow uow.UnitOfWork, enf RBACEnforcer) *User {
 	}
 	return s
 }
+
+// RequireMFA implements apicms.UserService.
+func (s *User) RequireMFA(ctx context.Context, req *dtocms.RequireMFAUserReq) (*dtocms.RequireMFAUserRes, error) {
+	if err := s.Validate(ctx, req); err != nil {
+		return nil, err
+	}
+	if _, err := authorize(ctx, s.enf, RBACObjUsers, RBACActManageMFA); err != nil {
+		return nil, err
+	}
+
+	if _, err := getUser(ctx, s.uow, req.ID); err != nil {
+		return nil, err
+	}
+	if err := s.uow.Users().Update(ctx, req.ID, map[string]any{"mfa_required": req.Required}); err != nil {
+		return nil, errors.NewInternal(err, "failed to update user")
+	}
+
+	user, err := getUser(ctx, s.uow, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	return dtocms.NewUser(user), nil
+}
+
+// ResetMFA implements apicms.UserService.
+func (s *User) ResetMFA(ctx context.Context, req *dtocms.ResetMFAUserReq) (*dtocms.ResetMFAUserRes, error) {
+	if err := s.Validate(ctx, req); err != nil {
+		return nil, err
+	}
+	if _, err := authorize(ctx, s.enf, RBACObjUsers, RBACActManageMFA); err != nil {
+		return nil, err
+	}
+
+	if _, err := getUser(ctx, s.uow, req.ID); err != nil {
+		return nil, err
+	}
+	if err := resetMFA(ctx, s.uow, req.ID); err != nil {
+		return nil, err
+	}
+
+	user, err := getUser(ctx, s.uow, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	return dtocms.NewUser(user), nil
+}
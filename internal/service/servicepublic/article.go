@@ -0,0 +1,89 @@
+package servicepublic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cirius-go/portfolio-server/internal/dto/dtopublic"
+	"github.com/cirius-go/portfolio-server/internal/repo"
+	"github.com/cirius-go/portfolio-server/internal/service"
+	"github.com/cirius-go/portfolio-server/internal/uow"
+	"github.com/cirius-go/portfolio-server/pkg/cache"
+	"github.com/cirius-go/portfolio-server/pkg/errors"
+)
+
+// Article is a service struct that encapsulates business logic.
+type Article struct {
+	service.Service
+	uow   uow.UnitOfWork
+	cache *cache.Cache
+}
+
+// NewArticle creates a new instance of Article service.
+func NewArticle(uow uow.UnitOfWork) *Article {
+	s := &Article{
+		uow: uow,
+	}
+	return s
+}
+
+// WithCache sets the cache of the hot reads.
+func (s *Article) WithCache(c *cache.Cache) *Article {
+	s.cache = c
+	return s
+}
+
+// List implements apipublic.ArticleService.
+func (s *Article) List(ctx context.Context, req *dtopublic.ListArticleReq) (*dtopublic.ListArticleRes, error) {
+	if err := s.Validate(ctx, req); err != nil {
+		return nil, err
+	}
+
+	page, perPage := paging(req.Page, req.PerPage)
+	e := cache.Entry{
+		Key:  fmt.Sprintf("articles:list:%d:%d:%s", page, perPage, req.Tag),
+		Tags: []string{service.CacheTagArticles},
+	}
+	return cache.GetOrLoad(ctx, s.cache, e, func(ctx context.Context) (*dtopublic.ListArticleRes, error) {
+		recs, total, err := s.uow.Articles().ListPublished(ctx, &repo.ListingRequest[repo.ArticleFilter]{
+			Page:    page,
+			PerPage: perPage,
+			Count:   true,
+			Filter:  repo.ArticleFilter{TagSlug: req.Tag},
+		})
+		if err != nil {
+			return nil, errors.NewInternal(err, "failed to list articles")
+		}
+
+		res := &dtopublic.ListArticleRes{
+			Recs:  make([]*dtopublic.ArticleSummary, 0, len(recs)),
+			Total: total,
+		}
+		for _, m := range recs {
+			res.Recs = append(res.Recs, dtopublic.NewArticleSummary(m))
+		}
+		return res, nil
+	})
+}
+
+// Get implements apipublic.ArticleService.
+func (s *Article) Get(ctx context.Context, req *dtopublic.GetArticleReq) (*dtopublic.GetArticleRes, error) {
+	if err := s.Validate(ctx, req); err != nil {
+		return nil, err
+	}
+
+	e := cache.Entry{
+		Key:  "articles:slug:" + req.Slug,
+		Tags: []string{service.CacheTagArticles},
+	}
+	return cache.GetOrLoad(ctx, s.cache, e, func(ctx context.Context) (*dtopublic.GetArticleRes, error) {
+		m, err := s.uow.Articles().GetPublishedBySlug(ctx, req.Slug)
+		if errors.Is(err, uow.ErrRecordNotFound) {
+			return nil, errors.NewNotFound(err, "article not found")
+		}
+		if err != nil {
+			return nil, errors.NewInternal(err, "failed to get article")
+		}
+		return dtopublic.NewArticle(m), nil
+	})
+}
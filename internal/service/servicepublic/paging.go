@@ -0,0 +1,15 @@
+package servicepublic
+
+// defaultPerPage is the page size of the listings when the request has none.
+const defaultPerPage = 20
+
+// paging returns the page and page size of a listing request.
+func paging(page, perPage int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = defaultPerPage
+	}
+	return page, perPage
+}
@@ -0,0 +1,50 @@
+package servicepublic
+
+import (
+	"context"
+
+	"github.com/cirius-go/portfolio-server/internal/dto/dtopublic"
+	"github.com/cirius-go/portfolio-server/internal/service"
+	"github.com/cirius-go/portfolio-server/internal/uow"
+	"github.com/cirius-go/portfolio-server/pkg/cache"
+	"github.com/cirius-go/portfolio-server/pkg/errors"
+)
+
+// Profile is a service struct that encapsulates business logic.
+type Profile struct {
+	service.Service
+	uow   uow.UnitOfWork
+	cache *cache.Cache
+}
+
+// NewProfile creates a new instance of Profile service.
+func NewProfile(uow uow.UnitOfWork) *Profile {
+	s := &Profile{
+		uow: uow,
+	}
+	return s
+}
+
+// WithCache sets the cache of the hot reads.
+func (s *Profile) WithCache(c *cache.Cache) *Profile {
+	s.cache = c
+	return s
+}
+
+// Get implements apipublic.ProfileService.
+func (s *Profile) Get(ctx context.Context, req *dtopublic.GetProfileReq) (*dtopublic.GetProfileRes, error) {
+	e := cache.Entry{
+		Key:  "profile",
+		Tags: []string{service.CacheTagProfile},
+	}
+	return cache.GetOrLoad(ctx, s.cache, e, func(ctx context.Context) (*dtopublic.GetProfileRes, error) {
+		m, err := s.uow.Profiles().GetCurrent(ctx)
+		if errors.Is(err, uow.ErrRecordNotFound) {
+			return nil, errors.NewNotFound(err, "profile not found")
+		}
+		if err != nil {
+			return nil, errors.NewInternal(err, "failed to get profile")
+		}
+		return dtopublic.NewProfile(m), nil
+	})
+}
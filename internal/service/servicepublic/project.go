@@ -0,0 +1,92 @@
+package servicepublic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cirius-go/portfolio-server/internal/dto/dtopublic"
+	"github.com/cirius-go/portfolio-server/internal/repo"
+	"github.com/cirius-go/portfolio-server/internal/service"
+	"github.com/cirius-go/portfolio-server/internal/uow"
+	"github.com/cirius-go/portfolio-server/pkg/cache"
+	"github.com/cirius-go/portfolio-server/pkg/errors"
+)
+
+// Project is a service struct that encapsulates business logic.
+type Project struct {
+	service.Service
+	uow   uow.UnitOfWork
+	cache *cache.Cache
+}
+
+// NewProject creates a new instance of Project service.
+func NewProject(uow uow.UnitOfWork) *Project {
+	s := &Project{
+		uow: uow,
+	}
+	return s
+}
+
+// WithCache sets the cache of the hot reads.
+func (s *Project) WithCache(c *cache.Cache) *Project {
+	s.cache = c
+	return s
+}
+
+// List implements apipublic.ProjectService.
+func (s *Project) List(ctx context.Context, req *dtopublic.ListProjectReq) (*dtopublic.ListProjectRes, error) {
+	if err := s.Validate(ctx, req); err != nil {
+		return nil, err
+	}
+
+	page, perPage := paging(req.Page, req.PerPage)
+	e := cache.Entry{
+		Key:  fmt.Sprintf("projects:list:%d:%d:%t:%s", page, perPage, req.Featured, req.Tag),
+		Tags: []string{service.CacheTagProjects},
+	}
+	return cache.GetOrLoad(ctx, s.cache, e, func(ctx context.Context) (*dtopublic.ListProjectRes, error) {
+		recs, total, err := s.uow.Projects().ListPublished(ctx, &repo.ListingRequest[repo.ProjectFilter]{
+			Page:    page,
+			PerPage: perPage,
+			Count:   true,
+			Filter: repo.ProjectFilter{
+				FeaturedOnly: req.Featured,
+				TagSlug:      req.Tag,
+			},
+		})
+		if err != nil {
+			return nil, errors.NewInternal(err, "failed to list projects")
+		}
+
+		res := &dtopublic.ListProjectRes{
+			Recs:  make([]*dtopublic.Project, 0, len(recs)),
+			Total: total,
+		}
+		for _, m := range recs {
+			res.Recs = append(res.Recs, dtopublic.NewProject(m))
+		}
+		return res, nil
+	})
+}
+
+// Get implements apipublic.ProjectService.
+func (s *Project) Get(ctx context.Context, req *dtopublic.GetProjectReq) (*dtopublic.GetProjectRes, error) {
+	if err := s.Validate(ctx, req); err != nil {
+		return nil, err
+	}
+
+	e := cache.Entry{
+		Key:  "projects:slug:" + req.Slug,
+		Tags: []string{service.CacheTagProjects},
+	}
+	return cache.GetOrLoad(ctx, s.cache, e, func(ctx context.Context) (*dtopublic.GetProjectRes, error) {
+		m, err := s.uow.Projects().GetPublishedBySlug(ctx, req.Slug)
+		if errors.Is(err, uow.ErrRecordNotFound) {
+			return nil, errors.NewNotFound(err, "project not found")
+		}
+		if err != nil {
+			return nil, errors.NewInternal(err, "failed to get project")
+		}
+		return dtopublic.NewProject(m), nil
+	})
+}
@@ -0,0 +1,47 @@
+package servicepublic
+
+import (
+	"context"
+
+	"github.com/cirius-go/portfolio-server/internal/dto/dtopublic"
+	"github.com/cirius-go/portfolio-server/internal/service"
+	"github.com/cirius-go/portfolio-server/internal/uow"
+	"github.com/cirius-go/portfolio-server/pkg/cache"
+	"github.com/cirius-go/portfolio-server/pkg/errors"
+)
+
+// Tag is a service struct that encapsulates business logic.
+type Tag struct {
+	service.Service
+	uow   uow.UnitOfWork
+	cache *cache.Cache
+}
+
+// NewTag creates a new instance of Tag service.
+func NewTag(uow uow.UnitOfWork) *Tag {
+	s := &Tag{
+		uow: uow,
+	}
+	return s
+}
+
+// WithCache sets the cache of the hot reads.
+func (s *Tag) WithCache(c *cache.Cache) *Tag {
+	s.cache = c
+	return s
+}
+
+// List implements apipublic.TagService.
+func (s *Tag) List(ctx context.Context, req *dtopublic.ListTagReq) (*dtopublic.ListTagRes, error) {
+	e := cache.Entry{
+		Key:  "tags:list",
+		Tags: []string{service.CacheTagTags},
+	}
+	return cache.GetOrLoad(ctx, s.cache, e, func(ctx context.Context) (*dtopublic.ListTagRes, error) {
+		recs, err := s.uow.Tags().ListPublished(ctx)
+		if err != nil {
+			return nil, errors.NewInternal(err, "failed to list tags")
+		}
+		return &dtopublic.ListTagRes{Recs: dtopublic.NewTags(recs)}, nil
+	})
+}
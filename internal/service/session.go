@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+	"github.com/cirius-go/portfolio-server/pkg/errors"
+)
+
+// ErrMissingSession is returned when the request is not authenticated.
+var ErrMissingSession = errors.NewUnauthorized(nil, "You must be signed in to perform this action")
+
+// Session represents the authenticated user of the request.
+type Session struct {
+	UserID string
+	Role   model.UserRole
+}
+
+// WithSession returns a copy of ctx carrying the session.
+func WithSession(ctx context.Context, s *Session) context.Context {
+	return context.WithValue(ctx, model.ContextKeySession, s)
+}
+
+// SessionFromContext returns the session carried by ctx, if any.
+func SessionFromContext(ctx context.Context) (*Session, bool) {
+	s, ok := ctx.Value(model.ContextKeySession).(*Session)
+	return s, ok && s != nil
+}
+
+// Session returns the session of the request or an unauthorized error.
+func (s *Service) Session(ctx context.Context) (*Session, error) {
+	sess, ok := SessionFromContext(ctx)
+	if !ok {
+		return nil, ErrMissingSession
+	}
+	return sess, nil
+}
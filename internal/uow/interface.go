@@ -2,7 +2,9 @@ package uow
 
 import (
 	"context"
+	"time"
 
+	"github.com/cirius-go/portfolio-server/internal/repo"
 	"github.com/cirius-go/portfolio-server/internal/repo/model"
 )
 
@@ -11,7 +13,32 @@ type TxHandler func(ctx context.Context, tx UnitOfWork) error
 
 // UnitOfWork represents the unit of work.
 type UnitOfWork interface {
-	Transaction(ctx context.Context, txHandler func(ctx context.Context, tx UnitOfWork) error) error
+	// Transaction runs the handler in a transaction, or in a savepoint of the
+	// transaction carried by ctx. The ctx handed to the handler carries the
+	// transaction. Only the first options are used.
+	Transaction(ctx context.Context, txHandler func(ctx context.Context, tx UnitOfWork) error, opts ...*TxOptions) error
+	// AfterCommit registers fn to run once the transaction commits, it runs
+	// right away outside of a transaction. A hook registered in a savepoint
+	// which rolls back never runs.
+	AfterCommit(ctx context.Context, fn func(ctx context.Context))
+	// AfterRollback registers fn to run once the transaction, or the
+	// savepoint it is registered in, rolls back. It never runs outside of a
+	// transaction.
+	AfterRollback(ctx context.Context, fn func(ctx context.Context))
+	// InvalidateCache invalidates the cache tags, after the commit when the
+	// unit is in a transaction.
+	InvalidateCache(ctx context.Context, tags ...string) error
+
+	//+codegen=DefineUOWHandler
+	Users() Users
+	UserRecoveryCodes() UserRecoveryCodes
+	Invitations() Invitations
+	PasswordResetTokens() PasswordResetTokens
+	Projects() Projects
+	Articles() Articles
+	Tags() Tags
+	Profiles() Profiles
+	OutboxEvents() OutboxEvents
 }
 
 // Common represents the common repository.
@@ -29,14 +56,67 @@ type Common[T any] interface {
 // Users repo as a unit.
 type Users interface {
 	Common[model.User]
+	GetByEmail(ctx context.Context, email string) (*model.User, error)
+	UseMFAStep(ctx context.Context, id string, step int64) (bool, error)
+	FailMFA(ctx context.Context, id string, maxAttempts int, lockout time.Duration) (bool, error)
+}
+
+// UserRecoveryCodes repo as a unit.
+type UserRecoveryCodes interface {
+	Common[model.UserRecoveryCode]
+	Replace(ctx context.Context, userID string, hashes []string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+	Use(ctx context.Context, userID, hash string) (bool, error)
+}
+
+// Invitations repo as a unit.
+type Invitations interface {
+	Common[model.Invitation]
+	GetPendingByTokenHash(ctx context.Context, hash string) (*model.Invitation, error)
+	Accept(ctx context.Context, id string) (bool, error)
+	CountPendingByEmail(ctx context.Context, email string) (int64, error)
+	DeletePendingByEmail(ctx context.Context, email string) error
+}
+
+// PasswordResetTokens repo as a unit.
+type PasswordResetTokens interface {
+	Common[model.PasswordResetToken]
+	GetActiveByTokenHash(ctx context.Context, hash string) (*model.PasswordResetToken, error)
+	Use(ctx context.Context, id string) (bool, error)
+	DeleteByUserID(ctx context.Context, userID string) error
 }
 
 // Projects repo as a unit.
 type Projects interface {
 	Common[model.Project]
+	ListPublished(ctx context.Context, req *repo.ListingRequest[repo.ProjectFilter]) ([]*model.Project, int64, error)
+	GetPublishedBySlug(ctx context.Context, slug string) (*model.Project, error)
 }
 
 // Articles repo as a unit.
 type Articles interface {
 	Common[model.Article]
+	ListPublished(ctx context.Context, req *repo.ListingRequest[repo.ArticleFilter]) ([]*model.Article, int64, error)
+	GetPublishedBySlug(ctx context.Context, slug string) (*model.Article, error)
+}
+
+// Tags repo as a unit.
+type Tags interface {
+	Common[model.Tag]
+	ListPublished(ctx context.Context) ([]*model.Tag, error)
+}
+
+// Profiles repo as a unit.
+type Profiles interface {
+	Common[model.Profile]
+	GetCurrent(ctx context.Context) (*model.Profile, error)
+}
+
+// OutboxEvents repo as a unit.
+type OutboxEvents interface {
+	Common[model.OutboxEvent]
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboxEvent, error)
+	MarkDelivered(ctx context.Context, id string) error
+	Retry(ctx context.Context, id string, attempts int, next time.Time, lastErr string) error
+	DeadLetter(ctx context.Context, id string, attempts int, lastErr string) error
 }
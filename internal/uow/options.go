@@ -0,0 +1,167 @@
+package uow
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	defaultTxName        = "default"
+	defaultTxMaxAttempts = 3
+	txRetryBaseDelay     = 20 * time.Millisecond
+	txRetryMaxDelay      = 500 * time.Millisecond
+)
+
+// TxOptions contains the options of a transaction. They only apply to the
+// outermost transaction, savepoints inherit the options of their transaction.
+type TxOptions struct {
+	name             string
+	isolation        sql.IsolationLevel
+	readOnly         bool
+	statementTimeout time.Duration
+	maxAttempts      int
+}
+
+// TxO creates new transaction options. By default a transaction runs at the
+// database isolation level and is attempted up to 3 times when it fails with
+// a serialization failure or a deadlock.
+func TxO() *TxOptions {
+	return &TxOptions{
+		name:        defaultTxName,
+		maxAttempts: defaultTxMaxAttempts,
+	}
+}
+
+// Name sets the name of the transaction in the metrics.
+func (o *TxOptions) Name(name string) *TxOptions {
+	o.name = name
+	return o
+}
+
+// Isolation sets the isolation level, e.g. sql.LevelSerializable.
+func (o *TxOptions) Isolation(level sql.IsolationLevel) *TxOptions {
+	o.isolation = level
+	return o
+}
+
+// ReadOnly makes the transaction read-only.
+func (o *TxOptions) ReadOnly() *TxOptions {
+	o.readOnly = true
+	return o
+}
+
+// StatementTimeout aborts the statements of the transaction which run longer
+// than d.
+func (o *TxOptions) StatementTimeout(d time.Duration) *TxOptions {
+	o.statementTimeout = d
+	return o
+}
+
+// Retry sets how many times the transaction is attempted when it fails with
+// a retryable error. The handler runs again on each attempt, so its side
+// effects must be registered with AfterCommit. 1 disables the retry.
+func (o *TxOptions) Retry(maxAttempts int) *TxOptions {
+	o.maxAttempts = max(maxAttempts, 1)
+	return o
+}
+
+func (o *TxOptions) sqlOptions() *sql.TxOptions {
+	if o.isolation == sql.LevelDefault && !o.readOnly {
+		return nil
+	}
+	return &sql.TxOptions{Isolation: o.isolation, ReadOnly: o.readOnly}
+}
+
+// backoff returns the delay before the next attempt, an exponential delay of
+// which the second half is jittered.
+func (o *TxOptions) backoff(attempt int) time.Duration {
+	delay := min(txRetryBaseDelay<<(attempt-1), txRetryMaxDelay)
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+func txOptions(opts []*TxOptions) *TxOptions {
+	if len(opts) > 0 && opts[0] != nil {
+		return opts[0]
+	}
+	return TxO()
+}
+
+// IsRetryable reports whether err is a Postgres serialization failure (40001)
+// or deadlock (40P01), after which the transaction can be attempted again.
+func IsRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// TxObserver is notified of the outcome of the outermost transactions.
+type TxObserver interface {
+	// ObserveTx is called once the transaction is over, attempts is 1 when
+	// the transaction was not retried.
+	ObserveTx(name string, attempts int, err error)
+}
+
+// MetricsObserver counts the transactions, retries and failures in
+// Prometheus, labeled by transaction name.
+type MetricsObserver struct {
+	total     *prometheus.CounterVec
+	retries   *prometheus.CounterVec
+	exhausted *prometheus.CounterVec
+}
+
+// NewMetricsObserver creates the observer and registers its metrics.
+func NewMetricsObserver(reg prometheus.Registerer) *MetricsObserver {
+	o := &MetricsObserver{
+		total: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "uow_transactions_total",
+			Help: "Number of transactions by outcome.",
+		}, []string{"name", "status"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "uow_transaction_retries_total",
+			Help: "Number of transaction attempts made again after a serialization failure or a deadlock.",
+		}, []string{"name"}),
+		exhausted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "uow_transaction_retries_exhausted_total",
+			Help: "Number of transactions which failed with a retryable error after their last attempt.",
+		}, []string{"name"}),
+	}
+	reg.MustRegister(o.total, o.retries, o.exhausted)
+	return o
+}
+
+// ObserveTx implements TxObserver.
+func (o *MetricsObserver) ObserveTx(name string, attempts int, err error) {
+	status := "committed"
+	if err != nil {
+		status = "failed"
+		if IsRetryable(err) {
+			o.exhausted.WithLabelValues(name).Inc()
+		}
+	}
+	o.total.WithLabelValues(name, status).Inc()
+	if attempts > 1 {
+		o.retries.WithLabelValues(name).Add(float64(attempts - 1))
+	}
+}
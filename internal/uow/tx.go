@@ -0,0 +1,139 @@
+package uow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"gorm.io/gorm"
+
+	"github.com/cirius-go/portfolio-server/internal/repo"
+	"github.com/cirius-go/portfolio-server/pkg/logging"
+)
+
+// txKey is the context key of the unit of the active transaction.
+type txKey struct{}
+
+// txState holds the hooks registered within a transaction or one of its
+// savepoints.
+type txState struct {
+	mu            sync.Mutex
+	afterCommit   []func(ctx context.Context)
+	afterRollback []func(ctx context.Context)
+}
+
+func (s *txState) onCommit(fn func(ctx context.Context)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.afterCommit = append(s.afterCommit, fn)
+}
+
+func (s *txState) onRollback(fn func(ctx context.Context)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.afterRollback = append(s.afterRollback, fn)
+}
+
+// merge hands the hooks of a released savepoint over to its parent.
+func (s *txState) merge(child *txState) {
+	child.mu.Lock()
+	defer child.mu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.afterCommit = append(s.afterCommit, child.afterCommit...)
+	s.afterRollback = append(s.afterRollback, child.afterRollback...)
+}
+
+// committed runs the after commit hooks.
+func (s *txState) committed(ctx context.Context) {
+	s.mu.Lock()
+	hooks := s.afterCommit
+	s.mu.Unlock()
+	runHooks(ctx, "after commit", hooks)
+}
+
+// rolledBack runs the after rollback hooks.
+func (s *txState) rolledBack(ctx context.Context) {
+	s.mu.Lock()
+	hooks := s.afterRollback
+	s.mu.Unlock()
+	runHooks(ctx, "after rollback", hooks)
+}
+
+// runHooks runs the hooks in order. The transaction is already over, so a
+// panicking hook is only reported and does not stop the others.
+func runHooks(ctx context.Context, kind string, hooks []func(ctx context.Context)) {
+	for _, h := range hooks {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					logging.FromContext(ctx).Error(kind+" hook panicked", "panic", r)
+				}
+			}()
+			h(ctx)
+		}()
+	}
+}
+
+// activeTx returns the unit of the transaction the unit belongs to, or the
+// one carried by ctx.
+func (u *uow) activeTx(ctx context.Context) *uow {
+	if u.tx != nil {
+		return u
+	}
+	if t, ok := ctx.Value(txKey{}).(*uow); ok {
+		return t
+	}
+	return nil
+}
+
+// child creates the unit of the transaction tx.
+func (u *uow) child(tx *gorm.DB, state *txState, depth int) *uow {
+	c := New(tx).WithCache(u.cache)
+	c.tx = state
+	c.depth = depth
+	return c
+}
+
+// bind returns a copy of ctx carrying the transaction of the unit. The
+// context must not be used once the transaction is over.
+func (u *uow) bind(ctx context.Context) context.Context {
+	return repo.WithTx(context.WithValue(ctx, txKey{}, u), u.db)
+}
+
+// unbind returns a copy of ctx which carries no transaction.
+func unbind(ctx context.Context) context.Context {
+	return repo.WithTx(context.WithValue(ctx, txKey{}, (*uow)(nil)), nil)
+}
+
+// savepoint runs the handler within a savepoint of the transaction of the
+// unit, rolling back to it when the handler fails. The hooks registered in a
+// released savepoint are handed over to the transaction, the after rollback
+// hooks of a rolled back one run right away.
+func (u *uow) savepoint(ctx context.Context, txHandler func(ctx context.Context, uow UnitOfWork) error) error {
+	var (
+		depth = u.depth + 1
+		name  = fmt.Sprintf("sp_%d", depth)
+		state = &txState{}
+	)
+
+	if err := u.db.SavePoint(name).Error; err != nil {
+		return err
+	}
+
+	spu := u.child(u.db, state, depth)
+	if err := txHandler(spu.bind(ctx), spu); err != nil {
+		if rbErr := u.db.RollbackTo(name).Error; rbErr != nil {
+			return errors.Join(err, rbErr)
+		}
+		state.rolledBack(unbind(ctx))
+		return err
+	}
+
+	if err := u.db.Exec("RELEASE SAVEPOINT " + name).Error; err != nil {
+		return err
+	}
+	u.tx.merge(state)
+	return nil
+}
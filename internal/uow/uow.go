@@ -2,18 +2,39 @@ package uow
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
 	"github.com/cirius-go/portfolio-server/internal/repo"
+	"github.com/cirius-go/portfolio-server/pkg/cache"
+	"github.com/cirius-go/portfolio-server/pkg/logging"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"gorm.io/gorm"
 )
 
+const tracerName = "github.com/cirius-go/portfolio-server/internal/uow"
+
+// ErrRecordNotFound is returned by the units when no record matches.
+var ErrRecordNotFound = gorm.ErrRecordNotFound
+
 // uow represents the Unit of Work.
 type uow struct {
 	db *gorm.DB
 
 	mu     *sync.Mutex
 	caches map[string]any
+
+	cache    *cache.Cache
+	observer TxObserver
+
+	// tx holds the hooks of the transaction or savepoint of the unit, it is
+	// nil outside of a transaction.
+	tx *txState
+	// depth is the savepoint nesting level of the unit, 0 for the
+	// transaction itself.
+	depth int
 }
 
 // New creates a new Unit of Work.
@@ -25,11 +46,121 @@ func New(db *gorm.DB) *uow {
 	}
 }
 
-// Transaction implements service.UnitOfWork.
-func (u *uow) Transaction(ctx context.Context, txHandler func(ctx context.Context, uow UnitOfWork) error) error {
-	return u.db.Transaction(func(tx *gorm.DB) error {
-		return txHandler(ctx, New(tx))
-	})
+// WithCache sets the application cache invalidated by the unit.
+func (u *uow) WithCache(c *cache.Cache) *uow {
+	u.cache = c
+	return u
+}
+
+// WithObserver sets the observer of the transactions, none is notified by
+// default.
+func (u *uow) WithObserver(o TxObserver) *uow {
+	u.observer = o
+	return u
+}
+
+// Transaction implements UnitOfWork.
+//
+// The context handed to the handler carries the transaction, so repositories
+// reached with it join the transaction even through a unit created outside
+// of it. When the unit or the context already holds a transaction, the
+// handler runs within a SAVEPOINT and its failure only rolls back its own
+// work.
+//
+// The outermost transaction is attempted again after a serialization failure
+// or a deadlock, as set by the options. The after commit and after rollback
+// hooks run once it is over, with the context of its caller.
+func (u *uow) Transaction(ctx context.Context, txHandler func(ctx context.Context, uow UnitOfWork) error, opts ...*TxOptions) error {
+	if parent := u.activeTx(ctx); parent != nil {
+		return parent.savepoint(ctx, txHandler)
+	}
+
+	var (
+		opt      = txOptions(opts)
+		attempts = 0
+		err      error
+	)
+
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "uow.tx "+opt.name)
+	defer span.End()
+	for {
+		attempts++
+		err = u.transaction(ctx, txHandler, opt)
+		if err == nil || attempts >= opt.maxAttempts || !IsRetryable(err) {
+			break
+		}
+		if sleep(ctx, opt.backoff(attempts)) != nil {
+			break
+		}
+	}
+
+	span.SetAttributes(attribute.Int("uow.tx.attempts", attempts))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	if u.observer != nil {
+		u.observer.ObserveTx(opt.name, attempts, err)
+	}
+	return err
+}
+
+// transaction runs one attempt of the outermost transaction.
+func (u *uow) transaction(ctx context.Context, txHandler func(ctx context.Context, uow UnitOfWork) error, opt *TxOptions) (err error) {
+	state := &txState{}
+	defer func() {
+		if r := recover(); r != nil {
+			state.rolledBack(ctx)
+			panic(r)
+		}
+	}()
+
+	err = u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if opt.statementTimeout > 0 {
+			if err := tx.Exec(fmt.Sprintf("SET LOCAL statement_timeout = %d", opt.statementTimeout.Milliseconds())).Error; err != nil {
+				return err
+			}
+		}
+
+		txu := u.child(tx, state, 0)
+		return txHandler(txu.bind(ctx), txu)
+	}, opt.sqlOptions())
+	if err != nil {
+		state.rolledBack(ctx)
+		return err
+	}
+
+	state.committed(ctx)
+	return nil
+}
+
+// AfterCommit implements UnitOfWork.
+func (u *uow) AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if t := u.activeTx(ctx); t != nil {
+		t.tx.onCommit(fn)
+		return
+	}
+	fn(ctx)
+}
+
+// AfterRollback implements UnitOfWork.
+func (u *uow) AfterRollback(ctx context.Context, fn func(ctx context.Context)) {
+	if t := u.activeTx(ctx); t != nil {
+		t.tx.onRollback(fn)
+	}
+}
+
+// InvalidateCache implements UnitOfWork.
+func (u *uow) InvalidateCache(ctx context.Context, tags ...string) error {
+	if t := u.activeTx(ctx); t != nil {
+		t.AfterCommit(ctx, func(ctx context.Context) {
+			if err := u.cache.InvalidateTags(ctx, tags...); err != nil {
+				logging.FromContext(ctx).Error("failed to invalidate cache tags", "tags", tags, "error", err)
+			}
+		})
+		return nil
+	}
+	return u.cache.InvalidateTags(ctx, tags...)
 }
 
 // Users retrieve cached unit or init a new one.
@@ -37,6 +168,21 @@ func (u *uow) Users() Users {
 	return lazyCache(u, "Users", repo.NewUsers)
 }
 
+// UserRecoveryCodes retrieve cached unit or init a new one.
+func (u *uow) UserRecoveryCodes() UserRecoveryCodes {
+	return lazyCache(u, "UserRecoveryCodes", repo.NewUserRecoveryCodes)
+}
+
+// Invitations retrieve cached unit or init a new one.
+func (u *uow) Invitations() Invitations {
+	return lazyCache(u, "Invitations", repo.NewInvitations)
+}
+
+// PasswordResetTokens retrieve cached unit or init a new one.
+func (u *uow) PasswordResetTokens() PasswordResetTokens {
+	return lazyCache(u, "PasswordResetTokens", repo.NewPasswordResetTokens)
+}
+
 // Projects retrieve cached unit or init a new one.
 func (u *uow) Projects() Projects {
 	return lazyCache(u, "Projects", repo.NewProjects)
@@ -46,3 +192,18 @@ func (u *uow) Projects() Projects {
 func (u *uow) Articles() Articles {
 	return lazyCache(u, "Articles", repo.NewArticles)
 }
+
+// Tags retrieve cached unit or init a new one.
+func (u *uow) Tags() Tags {
+	return lazyCache(u, "Tags", repo.NewTags)
+}
+
+// Profiles retrieve cached unit or init a new one.
+func (u *uow) Profiles() Profiles {
+	return lazyCache(u, "Profiles", repo.NewProfiles)
+}
+
+// OutboxEvents retrieve cached unit or init a new one.
+func (u *uow) OutboxEvents() OutboxEvents {
+	return lazyCache(u, "OutboxEvents", repo.NewOutboxEvents)
+}
@@ -0,0 +1,304 @@
+package uowfake
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cirius-go/portfolio-server/internal/repo"
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+	"github.com/cirius-go/portfolio-server/internal/uow"
+)
+
+// paginate returns the page of the records like repo.WithPaging does.
+func paginate[T any](recs []*T, page, perPage int) []*T {
+	if page < 1 {
+		return recs
+	}
+	start := min((page-1)*perPage, len(recs))
+	end := min(start+perPage, len(recs))
+	return recs[start:end]
+}
+
+// hasTag reports whether one of the tags has the slug.
+func hasTag(tags []*model.Tag, slug string) bool {
+	return slices.ContainsFunc(tags, func(t *model.Tag) bool { return t.Slug == slug })
+}
+
+// isPublished reports whether a content is visible to the public.
+func isPublished(status model.PublishStatus, publishedAt *time.Time) bool {
+	return status == model.PublishStatusPublished && publishedAt != nil && !publishedAt.After(time.Now())
+}
+
+// fakeUsers is the fake of uow.Users.
+type fakeUsers struct {
+	*table[model.User]
+}
+
+// GetByEmail implements uow.Users.
+func (r *fakeUsers) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	return r.first(func(m *model.User) bool { return strings.EqualFold(m.Email, email) })
+}
+
+// UseMFAStep implements uow.Users.
+func (r *fakeUsers) UseMFAStep(ctx context.Context, id string, step int64) (bool, error) {
+	n, err := r.updateWhere(ctx, func(m *model.User) bool {
+		return m.ID == id && m.MFALastStep < step
+	}, map[string]any{"mfa_last_step": step, "mfa_failed_attempts": 0})
+	return n > 0, err
+}
+
+// FailMFA implements uow.Users.
+func (r *fakeUsers) FailMFA(ctx context.Context, id string, maxAttempts int, lockout time.Duration) (bool, error) {
+	locked := false
+	_, err := r.updateFunc(ctx, r.byID(ctx, id), func(m *model.User) any {
+		if m.MFAFailedAttempts+1 < maxAttempts {
+			return map[string]any{"mfa_failed_attempts": m.MFAFailedAttempts + 1}
+		}
+		locked = true
+		return map[string]any{"mfa_failed_attempts": 0, "mfa_locked_until": time.Now().Add(lockout)}
+	})
+	return locked, err
+}
+
+// fakeUserRecoveryCodes is the fake of uow.UserRecoveryCodes.
+type fakeUserRecoveryCodes struct {
+	*table[model.UserRecoveryCode]
+}
+
+// Replace implements uow.UserRecoveryCodes.
+func (r *fakeUserRecoveryCodes) Replace(ctx context.Context, userID string, hashes []string) error {
+	if err := r.DeleteByUserID(ctx, userID); err != nil {
+		return err
+	}
+	for _, h := range hashes {
+		if err := r.Create(ctx, &model.UserRecoveryCode{UserID: userID, CodeHash: h}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteByUserID implements uow.UserRecoveryCodes.
+func (r *fakeUserRecoveryCodes) DeleteByUserID(ctx context.Context, userID string) error {
+	r.deleteWhere(func(m *model.UserRecoveryCode) bool { return m.UserID == userID })
+	return nil
+}
+
+// Use implements uow.UserRecoveryCodes.
+func (r *fakeUserRecoveryCodes) Use(ctx context.Context, userID, hash string) (bool, error) {
+	n, err := r.updateWhere(ctx, func(m *model.UserRecoveryCode) bool {
+		return m.UserID == userID && m.CodeHash == hash && m.UsedAt == nil
+	}, map[string]any{"used_at": time.Now()})
+	return n > 0, err
+}
+
+// fakeInvitations is the fake of uow.Invitations.
+type fakeInvitations struct {
+	*table[model.Invitation]
+}
+
+func (r *fakeInvitations) pending(m *model.Invitation) bool {
+	return m.AcceptedAt == nil && m.ExpiresAt.After(time.Now())
+}
+
+// GetPendingByTokenHash implements uow.Invitations.
+func (r *fakeInvitations) GetPendingByTokenHash(ctx context.Context, hash string) (*model.Invitation, error) {
+	return r.first(func(m *model.Invitation) bool { return m.TokenHash == hash && r.pending(m) })
+}
+
+// Accept implements uow.Invitations.
+func (r *fakeInvitations) Accept(ctx context.Context, id string) (bool, error) {
+	n, err := r.updateWhere(ctx, func(m *model.Invitation) bool {
+		return m.ID == id && r.pending(m)
+	}, map[string]any{"accepted_at": time.Now()})
+	return n > 0, err
+}
+
+// CountPendingByEmail implements uow.Invitations.
+func (r *fakeInvitations) CountPendingByEmail(ctx context.Context, email string) (int64, error) {
+	res := r.filter(func(m *model.Invitation) bool { return strings.EqualFold(m.Email, email) && r.pending(m) })
+	return int64(len(res)), nil
+}
+
+// DeletePendingByEmail implements uow.Invitations.
+func (r *fakeInvitations) DeletePendingByEmail(ctx context.Context, email string) error {
+	r.deleteWhere(func(m *model.Invitation) bool { return strings.EqualFold(m.Email, email) && m.AcceptedAt == nil })
+	return nil
+}
+
+// fakePasswordResetTokens is the fake of uow.PasswordResetTokens.
+type fakePasswordResetTokens struct {
+	*table[model.PasswordResetToken]
+}
+
+func (r *fakePasswordResetTokens) active(m *model.PasswordResetToken) bool {
+	return m.UsedAt == nil && m.ExpiresAt.After(time.Now())
+}
+
+// GetActiveByTokenHash implements uow.PasswordResetTokens.
+func (r *fakePasswordResetTokens) GetActiveByTokenHash(ctx context.Context, hash string) (*model.PasswordResetToken, error) {
+	return r.first(func(m *model.PasswordResetToken) bool { return m.TokenHash == hash && r.active(m) })
+}
+
+// Use implements uow.PasswordResetTokens.
+func (r *fakePasswordResetTokens) Use(ctx context.Context, id string) (bool, error) {
+	n, err := r.updateWhere(ctx, func(m *model.PasswordResetToken) bool {
+		return m.ID == id && r.active(m)
+	}, map[string]any{"used_at": time.Now()})
+	return n > 0, err
+}
+
+// DeleteByUserID implements uow.PasswordResetTokens.
+func (r *fakePasswordResetTokens) DeleteByUserID(ctx context.Context, userID string) error {
+	r.deleteWhere(func(m *model.PasswordResetToken) bool { return m.UserID == userID })
+	return nil
+}
+
+// fakeProjects is the fake of uow.Projects.
+type fakeProjects struct {
+	*table[model.Project]
+}
+
+// ListPublished implements uow.Projects.
+func (r *fakeProjects) ListPublished(ctx context.Context, req *repo.ListingRequest[repo.ProjectFilter]) ([]*model.Project, int64, error) {
+	res := r.filter(func(m *model.Project) bool {
+		return isPublished(m.Status, m.PublishedAt) &&
+			(!req.Filter.FeaturedOnly || m.Featured) &&
+			(req.Filter.TagSlug == "" || hasTag(m.Tags, req.Filter.TagSlug))
+	})
+	slices.SortStableFunc(res, func(a, b *model.Project) int {
+		if a.Position != b.Position {
+			return a.Position - b.Position
+		}
+		return b.PublishedAt.Compare(*a.PublishedAt)
+	})
+
+	total := int64(0)
+	if req.Count {
+		total = int64(len(res))
+	}
+	return paginate(res, req.Page, req.PerPage), total, nil
+}
+
+// GetPublishedBySlug implements uow.Projects.
+func (r *fakeProjects) GetPublishedBySlug(ctx context.Context, slug string) (*model.Project, error) {
+	return r.first(func(m *model.Project) bool { return m.Slug == slug && isPublished(m.Status, m.PublishedAt) })
+}
+
+// fakeArticles is the fake of uow.Articles.
+type fakeArticles struct {
+	*table[model.Article]
+}
+
+// ListPublished implements uow.Articles.
+func (r *fakeArticles) ListPublished(ctx context.Context, req *repo.ListingRequest[repo.ArticleFilter]) ([]*model.Article, int64, error) {
+	res := r.filter(func(m *model.Article) bool {
+		return isPublished(m.Status, m.PublishedAt) &&
+			(req.Filter.TagSlug == "" || hasTag(m.Tags, req.Filter.TagSlug))
+	})
+	slices.SortStableFunc(res, func(a, b *model.Article) int {
+		return b.PublishedAt.Compare(*a.PublishedAt)
+	})
+
+	total := int64(0)
+	if req.Count {
+		total = int64(len(res))
+	}
+	return paginate(res, req.Page, req.PerPage), total, nil
+}
+
+// GetPublishedBySlug implements uow.Articles.
+func (r *fakeArticles) GetPublishedBySlug(ctx context.Context, slug string) (*model.Article, error) {
+	return r.first(func(m *model.Article) bool { return m.Slug == slug && isPublished(m.Status, m.PublishedAt) })
+}
+
+// fakeTags is the fake of uow.Tags.
+type fakeTags struct {
+	*table[model.Tag]
+	store *store
+}
+
+// ListPublished implements uow.Tags. The tags of a content are the ones set
+// on its Tags association.
+func (r *fakeTags) ListPublished(ctx context.Context) ([]*model.Tag, error) {
+	used := map[string]bool{}
+	for _, m := range tableOf[model.Article](r.store).filter(func(m *model.Article) bool { return isPublished(m.Status, m.PublishedAt) }) {
+		for _, t := range m.Tags {
+			used[t.ID] = true
+		}
+	}
+	for _, m := range tableOf[model.Project](r.store).filter(func(m *model.Project) bool { return isPublished(m.Status, m.PublishedAt) }) {
+		for _, t := range m.Tags {
+			used[t.ID] = true
+		}
+	}
+
+	res := r.filter(func(m *model.Tag) bool { return used[m.ID] })
+	slices.SortStableFunc(res, func(a, b *model.Tag) int { return strings.Compare(a.Name, b.Name) })
+	return res, nil
+}
+
+// fakeProfiles is the fake of uow.Profiles.
+type fakeProfiles struct {
+	*table[model.Profile]
+}
+
+// GetCurrent implements uow.Profiles.
+func (r *fakeProfiles) GetCurrent(ctx context.Context) (*model.Profile, error) {
+	res := r.filter(func(*model.Profile) bool { return true })
+	if len(res) == 0 {
+		return nil, uow.ErrRecordNotFound
+	}
+	return slices.MinFunc(res, func(a, b *model.Profile) int { return a.CreatedAt.Compare(b.CreatedAt) }), nil
+}
+
+// fakeOutboxEvents is the fake of uow.OutboxEvents.
+type fakeOutboxEvents struct {
+	*table[model.OutboxEvent]
+}
+
+// ClaimDue implements uow.OutboxEvents.
+func (r *fakeOutboxEvents) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboxEvent, error) {
+	now := time.Now()
+	res := r.filter(func(m *model.OutboxEvent) bool {
+		return m.Status == model.OutboxStatusPending && !m.NextAttemptAt.After(now)
+	})
+	slices.SortStableFunc(res, func(a, b *model.OutboxEvent) int { return a.NextAttemptAt.Compare(b.NextAttemptAt) })
+	res = res[:min(limit, len(res))]
+
+	for _, m := range res {
+		if err := r.Update(ctx, m.ID, map[string]any{"next_attempt_at": now.Add(lease)}); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// MarkDelivered implements uow.OutboxEvents.
+func (r *fakeOutboxEvents) MarkDelivered(ctx context.Context, id string) error {
+	return r.Update(ctx, id, map[string]any{
+		"status":       model.OutboxStatusDelivered,
+		"delivered_at": time.Now(),
+		"last_error":   "",
+	})
+}
+
+// Retry implements uow.OutboxEvents.
+func (r *fakeOutboxEvents) Retry(ctx context.Context, id string, attempts int, next time.Time, lastErr string) error {
+	return r.Update(ctx, id, map[string]any{
+		"attempts":        attempts,
+		"next_attempt_at": next,
+		"last_error":      lastErr,
+	})
+}
+
+// DeadLetter implements uow.OutboxEvents.
+func (r *fakeOutboxEvents) DeadLetter(ctx context.Context, id string, attempts int, lastErr string) error {
+	return r.Update(ctx, id, map[string]any{
+		"status":     model.OutboxStatusDead,
+		"attempts":   attempts,
+		"last_error": lastErr,
+	})
+}
@@ -0,0 +1,343 @@
+package uowfake
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// schemas caches the parsed schemas of the models.
+var schemas = &sync.Map{}
+
+// tableData is the data of a table which can be copied for a snapshot.
+type tableData interface {
+	clone() tableData
+}
+
+// rows are the records of a table in insertion order.
+type rows[T any] struct {
+	recs []*T
+}
+
+func (r *rows[T]) clone() tableData {
+	c := &rows[T]{recs: make([]*T, len(r.recs))}
+	for i, m := range r.recs {
+		v := *m
+		c.recs[i] = &v
+	}
+	return c
+}
+
+// store is the in-memory database shared by the units.
+type store struct {
+	mu     sync.Mutex
+	tables map[string]tableData
+}
+
+func newStore() *store {
+	return &store{tables: map[string]tableData{}}
+}
+
+// snapshot copies the records of every table.
+func (s *store) snapshot() map[string]tableData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snap := make(map[string]tableData, len(s.tables))
+	for k, t := range s.tables {
+		snap[k] = t.clone()
+	}
+	return snap
+}
+
+// restore puts back the records of a snapshot.
+func (s *store) restore(snap map[string]tableData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tables = snap
+}
+
+// table gives access to the records of the model T. Records are copied in
+// and out, so that callers never share memory with the store, except for the
+// associations which are copied shallowly.
+type table[T any] struct {
+	store *store
+	sch   *schema.Schema
+}
+
+func tableOf[T any](s *store) *table[T] {
+	sch, err := schema.Parse(new(T), schemas, schema.NamingStrategy{})
+	if err != nil {
+		panic(fmt.Sprintf("uowfake: failed to parse the schema of %T: %v", new(T), err))
+	}
+	return &table[T]{store: s, sch: sch}
+}
+
+// rows returns the rows of the table. The store must be locked.
+func (t *table[T]) rows() *rows[T] {
+	r, ok := t.store.tables[t.sch.Table].(*rows[T])
+	if !ok {
+		r = &rows[T]{}
+		t.store.tables[t.sch.Table] = r
+	}
+	return r
+}
+
+// All implements uow.Common.
+func (t *table[T]) All(ctx context.Context) ([]*T, error) {
+	return t.filter(func(*T) bool { return true }), nil
+}
+
+// Create implements uow.Common. The id, timestamps and default values are
+// set like the database does.
+func (t *table[T]) Create(ctx context.Context, m *T) error {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	return t.create(ctx, m)
+}
+
+func (t *table[T]) create(ctx context.Context, m *T) error {
+	var (
+		rv  = reflect.ValueOf(m).Elem()
+		now = time.Now()
+	)
+
+	for _, f := range t.sch.Fields {
+		if _, zero := f.ValueOf(ctx, rv); !zero {
+			continue
+		}
+
+		var v any
+		switch {
+		case f.PrimaryKey && f.FieldType.Kind() == reflect.String:
+			v = uuid.Must(uuid.NewV7()).String()
+		case f.AutoCreateTime > 0 || f.AutoUpdateTime > 0:
+			v = now
+		case f.HasDefaultValue && f.DefaultValueInterface != nil:
+			v = f.DefaultValueInterface
+		default:
+			continue
+		}
+		if err := f.Set(ctx, rv, v); err != nil {
+			return err
+		}
+	}
+
+	r := t.rows()
+	if err := t.checkUnique(ctx, r, m); err != nil {
+		return err
+	}
+
+	c := *m
+	r.recs = append(r.recs, &c)
+	return nil
+}
+
+// checkUnique fails with gorm.ErrDuplicatedKey when m has the primary key or
+// the value of a single column unique index of another record.
+func (t *table[T]) checkUnique(ctx context.Context, r *rows[T], m *T) error {
+	fields := []*schema.Field{t.sch.PrioritizedPrimaryField}
+	for _, f := range t.sch.Fields {
+		if f.Unique {
+			fields = append(fields, f)
+		}
+	}
+	for _, idx := range t.sch.ParseIndexes() {
+		if idx.Class == "UNIQUE" && len(idx.Fields) == 1 {
+			fields = append(fields, idx.Fields[0].Field)
+		}
+	}
+
+	rv := reflect.ValueOf(m).Elem()
+	for _, rec := range r.recs {
+		if rec == m {
+			continue
+		}
+		recv := reflect.ValueOf(rec).Elem()
+		for _, f := range fields {
+			a, _ := f.ValueOf(ctx, rv)
+			b, _ := f.ValueOf(ctx, recv)
+			if reflect.DeepEqual(a, b) {
+				return fmt.Errorf("%w: %s.%s", gorm.ErrDuplicatedKey, t.sch.Table, f.DBName)
+			}
+		}
+	}
+	return nil
+}
+
+// Get implements uow.Common. The record must match the non zero fields of m.
+func (t *table[T]) Get(ctx context.Context, m *T) error {
+	var (
+		rv   = reflect.ValueOf(m).Elem()
+		conv = map[*schema.Field]any{}
+	)
+	for _, f := range t.sch.Fields {
+		if v, zero := f.ValueOf(ctx, rv); !zero {
+			conv[f] = v
+		}
+	}
+
+	rec, err := t.first(func(rec *T) bool {
+		recv := reflect.ValueOf(rec).Elem()
+		for f, v := range conv {
+			if rv, _ := f.ValueOf(ctx, recv); !reflect.DeepEqual(rv, v) {
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	*m = *rec
+	return nil
+}
+
+// GetByID implements uow.Common.
+func (t *table[T]) GetByID(ctx context.Context, id string) (*T, error) {
+	return t.first(t.byID(ctx, id))
+}
+
+// Update implements uow.Common. data is either a map of columns or a model
+// whose non zero fields are updated.
+func (t *table[T]) Update(ctx context.Context, id string, data any) error {
+	_, err := t.updateWhere(ctx, t.byID(ctx, id), data)
+	return err
+}
+
+// DeleteByID implements uow.Common.
+func (t *table[T]) DeleteByID(ctx context.Context, id string) error {
+	t.deleteWhere(t.byID(ctx, id))
+	return nil
+}
+
+// HardDeleteByID implements uow.Common.
+func (t *table[T]) HardDeleteByID(ctx context.Context, id string) error {
+	return t.DeleteByID(ctx, id)
+}
+
+// byID returns the predicate matching the record with the id.
+func (t *table[T]) byID(ctx context.Context, id string) func(*T) bool {
+	return func(rec *T) bool {
+		v, _ := t.sch.PrioritizedPrimaryField.ValueOf(ctx, reflect.ValueOf(rec).Elem())
+		return v == id
+	}
+}
+
+// first returns a copy of the first record matching the predicate.
+func (t *table[T]) first(match func(*T) bool) (*T, error) {
+	res := t.filter(match)
+	if len(res) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return res[0], nil
+}
+
+// filter returns copies of the records matching the predicate.
+func (t *table[T]) filter(match func(*T) bool) []*T {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+
+	res := make([]*T, 0)
+	for _, rec := range t.rows().recs {
+		if match(rec) {
+			c := *rec
+			res = append(res, &c)
+		}
+	}
+	return res
+}
+
+// updateWhere updates the records matching the predicate and returns how many
+// were updated.
+func (t *table[T]) updateWhere(ctx context.Context, match func(*T) bool, data any) (int, error) {
+	return t.updateFunc(ctx, match, func(*T) any { return data })
+}
+
+// updateFunc is updateWhere with the data computed from each record, like an
+// update with expressions, e.g. SET n = n + 1.
+func (t *table[T]) updateFunc(ctx context.Context, match func(*T) bool, data func(rec *T) any) (int, error) {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+
+	n := 0
+	for _, rec := range t.rows().recs {
+		if !match(rec) {
+			continue
+		}
+		// update a copy, so that a failed update leaves the record as is.
+		c := *rec
+		if err := t.apply(ctx, &c, data(rec)); err != nil {
+			return n, err
+		}
+		*rec = c
+		n++
+	}
+	return n, nil
+}
+
+// apply sets the columns of data on the record.
+func (t *table[T]) apply(ctx context.Context, rec *T, data any) error {
+	rv := reflect.ValueOf(rec).Elem()
+
+	switch d := data.(type) {
+	case map[string]any:
+		for col, v := range d {
+			f := t.sch.LookUpField(col)
+			if f == nil {
+				return fmt.Errorf("uowfake: unknown column %s.%s", t.sch.Table, col)
+			}
+			if err := f.Set(ctx, rv, v); err != nil {
+				return err
+			}
+		}
+	default:
+		dv := reflect.Indirect(reflect.ValueOf(data))
+		if dv.Type() != rv.Type() {
+			return fmt.Errorf("uowfake: cannot update %s with %T", t.sch.Table, data)
+		}
+		for _, f := range t.sch.Fields {
+			if v, zero := f.ValueOf(ctx, dv); !zero && !f.PrimaryKey {
+				if err := f.Set(ctx, rv, v); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, f := range t.sch.Fields {
+		if f.AutoUpdateTime > 0 {
+			if err := f.Set(ctx, rv, time.Now()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// deleteWhere removes the records matching the predicate and returns how many
+// were removed.
+func (t *table[T]) deleteWhere(match func(*T) bool) int {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+
+	var (
+		r    = t.rows()
+		kept = r.recs[:0]
+	)
+	for _, rec := range r.recs {
+		if !match(rec) {
+			kept = append(kept, rec)
+		}
+	}
+	n := len(r.recs) - len(kept)
+	r.recs = kept
+	return n
+}
@@ -0,0 +1,223 @@
+// Package uowfake provides an in-memory uow.UnitOfWork, so that services can
+// be tested without Postgres.
+package uowfake
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+	"github.com/cirius-go/portfolio-server/internal/uow"
+)
+
+// txKey is the context key of the unit of the active transaction.
+type txKey struct{}
+
+// UnitOfWork is an in-memory uow.UnitOfWork.
+//
+// A transaction snapshots the records and puts them back when its handler
+// fails, savepoints do the same for nested transactions. Transactions are
+// serialized, but reads outside of a transaction see their uncommitted
+// records. The transaction options are ignored.
+type UnitOfWork struct {
+	store *store
+	txMu  *sync.Mutex
+
+	// tx holds the hooks of the transaction of the unit, it is nil outside
+	// of a transaction.
+	tx *txState
+
+	mu          *sync.Mutex
+	invalidated *[]string
+}
+
+var _ uow.UnitOfWork = (*UnitOfWork)(nil)
+
+// New creates a new empty UnitOfWork.
+func New() *UnitOfWork {
+	return &UnitOfWork{
+		store:       newStore(),
+		txMu:        &sync.Mutex{},
+		mu:          &sync.Mutex{},
+		invalidated: &[]string{},
+	}
+}
+
+// InvalidatedTags returns the cache tags invalidated so far, in order.
+func (u *UnitOfWork) InvalidatedTags() []string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return append([]string{}, *u.invalidated...)
+}
+
+// Transaction implements uow.UnitOfWork.
+func (u *UnitOfWork) Transaction(ctx context.Context, txHandler func(ctx context.Context, tx uow.UnitOfWork) error, opts ...*uow.TxOptions) (err error) {
+	parent := u.activeTx(ctx)
+	if parent == nil {
+		u.txMu.Lock()
+		defer u.txMu.Unlock()
+	}
+
+	var (
+		snap  = u.store.snapshot()
+		state = &txState{}
+		txu   = *u
+	)
+	txu.tx = state
+
+	defer func() {
+		if r := recover(); r != nil {
+			u.store.restore(snap)
+			state.rolledBack(ctx)
+			panic(r)
+		}
+	}()
+
+	if err := txHandler(context.WithValue(ctx, txKey{}, &txu), &txu); err != nil {
+		u.store.restore(snap)
+		state.rolledBack(ctx)
+		return err
+	}
+
+	if parent != nil {
+		parent.tx.merge(state)
+		return nil
+	}
+	state.committed(ctx)
+	return nil
+}
+
+// AfterCommit implements uow.UnitOfWork.
+func (u *UnitOfWork) AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if t := u.activeTx(ctx); t != nil {
+		t.tx.onCommit(fn)
+		return
+	}
+	fn(ctx)
+}
+
+// AfterRollback implements uow.UnitOfWork.
+func (u *UnitOfWork) AfterRollback(ctx context.Context, fn func(ctx context.Context)) {
+	if t := u.activeTx(ctx); t != nil {
+		t.tx.onRollback(fn)
+	}
+}
+
+// InvalidateCache implements uow.UnitOfWork.
+func (u *UnitOfWork) InvalidateCache(ctx context.Context, tags ...string) error {
+	u.AfterCommit(ctx, func(ctx context.Context) {
+		u.mu.Lock()
+		defer u.mu.Unlock()
+		*u.invalidated = append(*u.invalidated, tags...)
+	})
+	return nil
+}
+
+// activeTx returns the unit of the transaction the unit belongs to, or the
+// one carried by ctx.
+func (u *UnitOfWork) activeTx(ctx context.Context) *UnitOfWork {
+	if u.tx != nil {
+		return u
+	}
+	if t, ok := ctx.Value(txKey{}).(*UnitOfWork); ok && t.store == u.store {
+		return t
+	}
+	return nil
+}
+
+// txState holds the hooks registered within a transaction.
+type txState struct {
+	mu            sync.Mutex
+	afterCommit   []func(ctx context.Context)
+	afterRollback []func(ctx context.Context)
+}
+
+func (s *txState) onCommit(fn func(ctx context.Context)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.afterCommit = append(s.afterCommit, fn)
+}
+
+func (s *txState) onRollback(fn func(ctx context.Context)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.afterRollback = append(s.afterRollback, fn)
+}
+
+// merge hands the hooks of a committed nested transaction over to its
+// parent.
+func (s *txState) merge(child *txState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.afterCommit = append(s.afterCommit, child.afterCommit...)
+	s.afterRollback = append(s.afterRollback, child.afterRollback...)
+}
+
+func (s *txState) committed(ctx context.Context) {
+	for _, h := range s.afterCommit {
+		h(ctx)
+	}
+}
+
+func (s *txState) rolledBack(ctx context.Context) {
+	for _, h := range s.afterRollback {
+		h(ctx)
+	}
+}
+
+// Seed stores the records as they are, e.g. with their id and timestamps
+// already set. It panics if a record cannot be stored.
+func Seed[T any](u *UnitOfWork, recs ...*T) {
+	t := tableOf[T](u.store)
+	for _, m := range recs {
+		if err := t.Create(context.Background(), m); err != nil {
+			panic(fmt.Sprintf("uowfake: failed to seed %T: %v", m, err))
+		}
+	}
+}
+
+// Users returns the fake unit.
+func (u *UnitOfWork) Users() uow.Users {
+	return &fakeUsers{tableOf[model.User](u.store)}
+}
+
+// UserRecoveryCodes returns the fake unit.
+func (u *UnitOfWork) UserRecoveryCodes() uow.UserRecoveryCodes {
+	return &fakeUserRecoveryCodes{tableOf[model.UserRecoveryCode](u.store)}
+}
+
+// Invitations returns the fake unit.
+func (u *UnitOfWork) Invitations() uow.Invitations {
+	return &fakeInvitations{tableOf[model.Invitation](u.store)}
+}
+
+// PasswordResetTokens returns the fake unit.
+func (u *UnitOfWork) PasswordResetTokens() uow.PasswordResetTokens {
+	return &fakePasswordResetTokens{tableOf[model.PasswordResetToken](u.store)}
+}
+
+// Projects returns the fake unit.
+func (u *UnitOfWork) Projects() uow.Projects {
+	return &fakeProjects{tableOf[model.Project](u.store)}
+}
+
+// Articles returns the fake unit.
+func (u *UnitOfWork) Articles() uow.Articles {
+	return &fakeArticles{tableOf[model.Article](u.store)}
+}
+
+// Tags returns the fake unit.
+func (u *UnitOfWork) Tags() uow.Tags {
+	return &fakeTags{tableOf[model.Tag](u.store), u.store}
+}
+
+// Profiles returns the fake unit.
+func (u *UnitOfWork) Profiles() uow.Profiles {
+	return &fakeProfiles{tableOf[model.Profile](u.store)}
+}
+
+// OutboxEvents returns the fake unit.
+func (u *UnitOfWork) OutboxEvents() uow.OutboxEvents {
+	return &fakeOutboxEvents{tableOf[model.OutboxEvent](u.store)}
+}
@@ -0,0 +1,361 @@
+package uowfake_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/cirius-go/portfolio-server/internal/repo"
+	"github.com/cirius-go/portfolio-server/internal/repo/model"
+	"github.com/cirius-go/portfolio-server/internal/uow"
+	"github.com/cirius-go/portfolio-server/internal/uow/uowfake"
+)
+
+var errHandler = errors.New("handler failed")
+
+func TestCreate(t *testing.T) {
+	var (
+		ctx = context.Background()
+		u   = uowfake.New()
+	)
+
+	user := &model.User{Email: "a@example.com"}
+	if err := u.Users().Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	if user.ID == "" || user.CreatedAt.IsZero() || user.UpdatedAt.IsZero() {
+		t.Errorf("id and timestamps not set: %+v", user.Model)
+	}
+	if user.Role != model.UserRoleEditor {
+		t.Errorf("role = %q, want the default %q", user.Role, model.UserRoleEditor)
+	}
+
+	err := u.Users().Create(ctx, &model.User{Email: "a@example.com"})
+	if !errors.Is(err, gorm.ErrDuplicatedKey) {
+		t.Errorf("Create() of a duplicate email error = %v, want %v", err, gorm.ErrDuplicatedKey)
+	}
+
+	// the records are copied in and out of the store.
+	user.Name = "changed"
+	got, err := u.Users().GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "" {
+		t.Errorf("the store shares memory with the caller")
+	}
+}
+
+func TestSeed(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		u       = uowfake.New()
+		created = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+		user    = &model.User{Model: model.Model{ID: "u1", CreatedAt: created}, Email: "a@example.com"}
+	)
+	uowfake.Seed(u, user)
+
+	got, err := u.Users().GetByID(ctx, "u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.CreatedAt.Equal(created) {
+		t.Errorf("created at = %v, want the seeded %v", got.CreatedAt, created)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Seed() of a duplicate did not panic")
+		}
+	}()
+	uowfake.Seed(u, &model.User{Model: model.Model{ID: "u1"}, Email: "b@example.com"})
+}
+
+func TestUpdate(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		data    any
+		want    string
+		wantErr bool
+	}{
+		{name: "columns", data: map[string]any{"name": "Ann"}, want: "Ann"},
+		{name: "model", data: &model.User{Name: "Bob"}, want: "Bob"},
+		{name: "zero fields of a model are kept", data: &model.User{}, want: "Old"},
+		{name: "unknown column", data: map[string]any{"nope": 1}, want: "Old", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := uowfake.New()
+			uowfake.Seed(u, &model.User{Model: model.Model{ID: "u1"}, Email: "a@example.com", Name: "Old"})
+
+			if err := u.Users().Update(ctx, "u1", tt.data); (err != nil) != tt.wantErr {
+				t.Fatalf("Update() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			got, _ := u.Users().GetByID(ctx, "u1")
+			if got.Name != tt.want {
+				t.Errorf("name = %q, want %q", got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransaction(t *testing.T) {
+	ctx := context.Background()
+
+	// hooks records the hooks run on commit and rollback of the transactions
+	// they are registered in.
+	type hooks struct{ ran []string }
+	register := func(h *hooks, ctx context.Context, tx uow.UnitOfWork, name string) {
+		tx.AfterCommit(ctx, func(context.Context) { h.ran = append(h.ran, name+" committed") })
+		tx.AfterRollback(ctx, func(context.Context) { h.ran = append(h.ran, name+" rolled back") })
+	}
+	createUser := func(ctx context.Context, tx uow.UnitOfWork, email string) error {
+		return tx.Users().Create(ctx, &model.User{Email: email})
+	}
+
+	tests := []struct {
+		name       string
+		run        func(u *uowfake.UnitOfWork, h *hooks) error
+		wantErr    error
+		wantEmails []string
+		wantHooks  []string
+		wantTags   []string
+	}{
+		{
+			name: "commit",
+			run: func(u *uowfake.UnitOfWork, h *hooks) error {
+				return u.Transaction(ctx, func(ctx context.Context, tx uow.UnitOfWork) error {
+					register(h, ctx, tx, "tx")
+					if err := createUser(ctx, tx, "a@example.com"); err != nil {
+						return err
+					}
+					return tx.InvalidateCache(ctx, "users")
+				})
+			},
+			wantEmails: []string{"a@example.com"},
+			wantHooks:  []string{"tx committed"},
+			wantTags:   []string{"users"},
+		},
+		{
+			name: "rollback on error",
+			run: func(u *uowfake.UnitOfWork, h *hooks) error {
+				return u.Transaction(ctx, func(ctx context.Context, tx uow.UnitOfWork) error {
+					register(h, ctx, tx, "tx")
+					if err := createUser(ctx, tx, "a@example.com"); err != nil {
+						return err
+					}
+					if err := tx.InvalidateCache(ctx, "users"); err != nil {
+						return err
+					}
+					return errHandler
+				})
+			},
+			wantErr:   errHandler,
+			wantHooks: []string{"tx rolled back"},
+		},
+		{
+			name: "rollback on panic",
+			run: func(u *uowfake.UnitOfWork, h *hooks) (err error) {
+				defer func() {
+					if recover() != nil {
+						err = errHandler
+					}
+				}()
+				return u.Transaction(ctx, func(ctx context.Context, tx uow.UnitOfWork) error {
+					register(h, ctx, tx, "tx")
+					if err := createUser(ctx, tx, "a@example.com"); err != nil {
+						return err
+					}
+					panic("boom")
+				})
+			},
+			wantErr:   errHandler,
+			wantHooks: []string{"tx rolled back"},
+		},
+		{
+			name: "failed nested transaction rolls back to its savepoint",
+			run: func(u *uowfake.UnitOfWork, h *hooks) error {
+				return u.Transaction(ctx, func(ctx context.Context, tx uow.UnitOfWork) error {
+					register(h, ctx, tx, "outer")
+					if err := createUser(ctx, tx, "a@example.com"); err != nil {
+						return err
+					}
+					err := tx.Transaction(ctx, func(ctx context.Context, tx uow.UnitOfWork) error {
+						register(h, ctx, tx, "inner")
+						if err := createUser(ctx, tx, "b@example.com"); err != nil {
+							return err
+						}
+						return errHandler
+					})
+					if !errors.Is(err, errHandler) {
+						t.Errorf("nested Transaction() error = %v, want %v", err, errHandler)
+					}
+					return nil
+				})
+			},
+			wantEmails: []string{"a@example.com"},
+			wantHooks:  []string{"inner rolled back", "outer committed"},
+		},
+		{
+			name: "nested transaction rolls back with its parent",
+			run: func(u *uowfake.UnitOfWork, h *hooks) error {
+				return u.Transaction(ctx, func(ctx context.Context, tx uow.UnitOfWork) error {
+					err := tx.Transaction(ctx, func(ctx context.Context, tx uow.UnitOfWork) error {
+						register(h, ctx, tx, "inner")
+						return createUser(ctx, tx, "b@example.com")
+					})
+					if err != nil {
+						return err
+					}
+					return errHandler
+				})
+			},
+			wantErr:   errHandler,
+			wantHooks: []string{"inner rolled back"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				u = uowfake.New()
+				h = &hooks{}
+			)
+			if err := tt.run(u, h); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Transaction() error = %v, want %v", err, tt.wantErr)
+			}
+
+			users, _ := u.Users().All(ctx)
+			var emails []string
+			for _, m := range users {
+				emails = append(emails, m.Email)
+			}
+			if !slices.Equal(emails, tt.wantEmails) {
+				t.Errorf("emails = %v, want %v", emails, tt.wantEmails)
+			}
+			if !slices.Equal(h.ran, tt.wantHooks) {
+				t.Errorf("hooks = %v, want %v", h.ran, tt.wantHooks)
+			}
+			if got := u.InvalidatedTags(); !slices.Equal(got, tt.wantTags) {
+				t.Errorf("invalidated tags = %v, want %v", got, tt.wantTags)
+			}
+		})
+	}
+}
+
+func TestAfterCommitOutsideTransaction(t *testing.T) {
+	var (
+		ctx = context.Background()
+		u   = uowfake.New()
+		ran bool
+	)
+	u.AfterCommit(ctx, func(context.Context) { ran = true })
+	if !ran {
+		t.Errorf("AfterCommit() outside of a transaction did not run the hook")
+	}
+	if err := u.InvalidateCache(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if got := u.InvalidatedTags(); !slices.Equal(got, []string{"a"}) {
+		t.Errorf("invalidated tags = %v, want [a]", got)
+	}
+}
+
+func TestListPublished(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		u      = uowfake.New()
+		now    = time.Now()
+		ago    = func(d time.Duration) *time.Time { t := now.Add(-d); return &t }
+		goTag  = &model.Tag{Model: model.Model{ID: "t1"}, Name: "Go", Slug: "go"}
+		sqlTag = &model.Tag{Model: model.Model{ID: "t2"}, Name: "SQL", Slug: "sql"}
+	)
+	uowfake.Seed(u, goTag, sqlTag)
+	uowfake.Seed(u,
+		&model.Article{Model: model.Model{ID: "old"}, Slug: "old", Status: model.PublishStatusPublished, PublishedAt: ago(3 * time.Hour), Tags: []*model.Tag{goTag}},
+		&model.Article{Model: model.Model{ID: "new"}, Slug: "new", Status: model.PublishStatusPublished, PublishedAt: ago(time.Hour), Tags: []*model.Tag{goTag, sqlTag}},
+		&model.Article{Model: model.Model{ID: "mid"}, Slug: "mid", Status: model.PublishStatusPublished, PublishedAt: ago(2 * time.Hour)},
+		&model.Article{Model: model.Model{ID: "draft"}, Slug: "draft", Status: model.PublishStatusDraft, Tags: []*model.Tag{goTag}},
+		&model.Article{Model: model.Model{ID: "scheduled"}, Slug: "scheduled", Status: model.PublishStatusPublished, PublishedAt: ago(-time.Hour)},
+	)
+
+	tests := []struct {
+		name      string
+		req       repo.ListingRequest[repo.ArticleFilter]
+		wantIDs   []string
+		wantTotal int64
+	}{
+		{name: "newest first", req: repo.ListingRequest[repo.ArticleFilter]{Count: true}, wantIDs: []string{"new", "mid", "old"}, wantTotal: 3},
+		{name: "by tag", req: repo.ListingRequest[repo.ArticleFilter]{Filter: repo.ArticleFilter{TagSlug: "go"}, Count: true}, wantIDs: []string{"new", "old"}, wantTotal: 2},
+		{name: "page", req: repo.ListingRequest[repo.ArticleFilter]{Page: 2, PerPage: 2, Count: true}, wantIDs: []string{"old"}, wantTotal: 3},
+		{name: "without count", req: repo.ListingRequest[repo.ArticleFilter]{Page: 1, PerPage: 1}, wantIDs: []string{"new"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, total, err := u.Articles().ListPublished(ctx, &tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, m := range res {
+				ids = append(ids, m.ID)
+			}
+			if !slices.Equal(ids, tt.wantIDs) || total != tt.wantTotal {
+				t.Errorf("ListPublished() = %v, %d, want %v, %d", ids, total, tt.wantIDs, tt.wantTotal)
+			}
+		})
+	}
+
+	if _, err := u.Articles().GetPublishedBySlug(ctx, "draft"); !errors.Is(err, uow.ErrRecordNotFound) {
+		t.Errorf("GetPublishedBySlug() of a draft error = %v, want %v", err, uow.ErrRecordNotFound)
+	}
+
+	tags, err := u.Tags().ListPublished(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 2 || tags[0].Slug != "go" || tags[1].Slug != "sql" {
+		t.Errorf("Tags().ListPublished() = %v, want go and sql", tags)
+	}
+}
+
+func TestUsers(t *testing.T) {
+	var (
+		ctx = context.Background()
+		u   = uowfake.New()
+	)
+	uowfake.Seed(u, &model.User{Model: model.Model{ID: "u1"}, Email: "Ann@Example.com"})
+
+	if got, err := u.Users().GetByEmail(ctx, "ann@example.COM"); err != nil || got.ID != "u1" {
+		t.Errorf("GetByEmail() = %v, %v, want u1", got, err)
+	}
+
+	steps := []struct {
+		step int64
+		want bool
+	}{{10, true}, {10, false}, {9, false}, {11, true}}
+	for _, s := range steps {
+		if got, err := u.Users().UseMFAStep(ctx, "u1", s.step); err != nil || got != s.want {
+			t.Errorf("UseMFAStep(%d) = %v, %v, want %v", s.step, got, err, s.want)
+		}
+	}
+
+	for i := 1; i <= 3; i++ {
+		locked, err := u.Users().FailMFA(ctx, "u1", 3, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if locked != (i == 3) {
+			t.Errorf("FailMFA() #%d locked = %v", i, locked)
+		}
+	}
+	got, _ := u.Users().GetByID(ctx, "u1")
+	if got.MFALockedUntil == nil || got.MFAFailedAttempts != 0 {
+		t.Errorf("after the lock: locked until %v, %d attempts", got.MFALockedUntil, got.MFAFailedAttempts)
+	}
+}
@@ -2,6 +2,7 @@ package uow
 
 import (
 	"fmt"
+	"log/slog"
 
 	"gorm.io/gorm"
 )
@@ -21,7 +22,7 @@ func lazyCache[Repo any](u *uow, key string, fn func(db *gorm.DB) *Repo) *Repo {
 		return v
 	default:
 		// Should never happen.
-		fmt.Printf("unexpected type %T of %s repo in lazyCache UnitOfWork.\n", v, key)
+		slog.Error("unexpected repo type in lazyCache UnitOfWork", "repo", key, "type", fmt.Sprintf("%T", v))
 		return u.caches[key].(*Repo)
 	}
 }
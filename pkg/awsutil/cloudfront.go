@@ -0,0 +1,50 @@
+package awsutil
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront/types"
+)
+
+// CloudFront is a service to interact with an AWS CloudFront distribution.
+type CloudFront struct {
+	client         *cloudfront.Client
+	distributionID string
+}
+
+// NewCloudFront creates a new CloudFront service for the distribution.
+func NewCloudFront(cfg aws.Config, distributionID string) *CloudFront {
+	return &CloudFront{
+		client: cloudfront.NewFromConfig(cfg, func(o *cloudfront.Options) {
+			o.APIOptions = append(o.APIOptions, traceOperations)
+		}),
+		distributionID: distributionID,
+	}
+}
+
+// Invalidate removes the paths from the edge caches of the distribution. A
+// path may end with '*' to invalidate every path with its prefix.
+func (c *CloudFront) Invalidate(ctx context.Context, paths ...string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	_, err := c.client.CreateInvalidation(ctx, &cloudfront.CreateInvalidationInput{
+		DistributionId: aws.String(c.distributionID),
+		InvalidationBatch: &types.InvalidationBatch{
+			CallerReference: aws.String(fmt.Sprintf("%d", time.Now().UnixNano())),
+			Paths: &types.Paths{
+				Items:    paths,
+				Quantity: aws.Int32(int32(len(paths))),
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to invalidate %v of distribution %s: %w", paths, c.distributionID, err)
+	}
+	return nil
+}
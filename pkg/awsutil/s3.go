@@ -28,7 +28,9 @@ type S3 struct {
 
 // NewS3 creates a new S3 service.
 func NewS3(cfg aws.Config) *S3 {
-	client := s3.NewFromConfig(cfg)
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.APIOptions = append(o.APIOptions, traceOperations)
+	})
 	return &S3{
 		client:        client,
 		presignClient: s3.NewPresignClient(client),
@@ -86,6 +88,14 @@ func (s *S3) ObjectExists(ctx context.Context, bucket, key string) (bool, error)
 	return false, err
 }
 
+// HeadBucket checks that the bucket exists and is accessible.
+func (s *S3) HeadBucket(ctx context.Context, bucket string) error {
+	_, err := s.client.HeadBucket(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(bucket),
+	})
+	return err
+}
+
 // MoveObject copies the object from/to given paths and delete it from source
 func (s *S3) MoveObject(ctx context.Context, bucket, frompath, topath string) error {
 	err := s.CopyObject(ctx, bucket, frompath, topath)
@@ -0,0 +1,46 @@
+package awsutil
+
+import (
+	"context"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/cirius-go/portfolio-server/pkg/awsutil"
+
+// traceOperations adds a client span around each operation of the stack with
+// the global tracer provider. It is meant for the APIOptions of a client.
+func traceOperations(stack *middleware.Stack) error {
+	tracer := otel.Tracer(tracerName)
+
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("TraceOperation", func(
+		ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler,
+	) (middleware.InitializeOutput, middleware.Metadata, error) {
+		var (
+			service = awsmiddleware.GetServiceID(ctx)
+			op      = awsmiddleware.GetOperationName(ctx)
+		)
+		ctx, span := tracer.Start(ctx, service+"."+op,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.RPCSystemKey.String("aws-api"),
+				semconv.RPCService(service),
+				semconv.RPCMethod(op),
+				semconv.CloudRegion(awsmiddleware.GetRegion(ctx)),
+			),
+		)
+		defer span.End()
+
+		out, md, err := next.HandleInitialize(ctx, in)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return out, md, err
+	}), middleware.After)
+}
@@ -0,0 +1,234 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/cirius-go/portfolio-server/pkg/logging"
+)
+
+// Driver represents the cache backend implementation.
+// ENUM(memory,redis)
+//
+//go:generate go-enum --marshal --names --values
+type Driver string
+
+// Config contains the cache configuration.
+type Config struct {
+	Driver     Driver        `envconfig:"DRIVER" validate:"enum"`
+	Prefix     string        `envconfig:"PREFIX"` // prepended to every key and tag.
+	DefaultTTL time.Duration `envconfig:"DEFAULT_TTL"`
+	LRUSize    int           `envconfig:"LRU_SIZE" validate:"min=0"` // max entries of the memory driver.
+	Redis      RedisConfig   `envconfig:"REDIS"`
+
+	// FreshWindow is how long the loads of invalidated tags must read fresh
+	// data, e.g. from the primary database while the replicas catch up. It
+	// should exceed the replica lag.
+	FreshWindow time.Duration `envconfig:"FRESH_WINDOW"`
+}
+
+// Backend stores raw values. Entries can be grouped by tags so that they are
+// invalidated together.
+type Backend interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, val []byte, ttl time.Duration, tags ...string) error
+	Delete(ctx context.Context, keys ...string) error
+	InvalidateTags(ctx context.Context, tags ...string) error
+}
+
+// Cache wraps a backend with key prefixing, a default ttl and single-flight
+// loading. A nil *Cache is valid and caches nothing.
+type Cache struct {
+	backend    Backend
+	prefix     string
+	defaultTTL time.Duration
+	group      singleflight.Group
+
+	freshWindow time.Duration
+	fresh       func(ctx context.Context) context.Context
+}
+
+// New creates the cache with the backend selected by cfg.Driver.
+func New(cfg Config) (*Cache, error) {
+	var b Backend
+	switch cfg.Driver {
+	case DriverMemory:
+		b = NewLRU(cfg.LRUSize)
+	case DriverRedis:
+		b = NewRedis(&cfg.Redis)
+	default:
+		return nil, fmt.Errorf("unsupported cache driver %q", cfg.Driver)
+	}
+
+	return NewWithBackend(b, cfg.Prefix, cfg.DefaultTTL), nil
+}
+
+// NewWithBackend creates the cache on top of the backend.
+func NewWithBackend(b Backend, prefix string, defaultTTL time.Duration) *Cache {
+	return &Cache{
+		backend:    b,
+		prefix:     prefix,
+		defaultTTL: defaultTTL,
+	}
+}
+
+// WithFreshReads sets how the loads of the tags invalidated in the last
+// window get fresh data, e.g. db.WithPrimary. The mark of an
+// invalidation is stored in the backend, so that it is seen by every instance
+// sharing it.
+func (c *Cache) WithFreshReads(window time.Duration, fresh func(ctx context.Context) context.Context) *Cache {
+	if c == nil {
+		return nil
+	}
+	c.freshWindow = window
+	c.fresh = fresh
+	return c
+}
+
+// Backend returns the underlying backend.
+func (c *Cache) Backend() Backend {
+	return c.backend
+}
+
+// Delete removes the keys.
+func (c *Cache) Delete(ctx context.Context, keys ...string) error {
+	if c == nil {
+		return nil
+	}
+	return c.backend.Delete(ctx, c.prefixed(keys)...)
+}
+
+// InvalidateTags removes every entry of the tags.
+func (c *Cache) InvalidateTags(ctx context.Context, tags ...string) error {
+	if c == nil || len(tags) == 0 {
+		return nil
+	}
+	tags = c.prefixed(tags)
+	if err := c.backend.InvalidateTags(ctx, tags...); err != nil {
+		return err
+	}
+	if c.fresh == nil || c.freshWindow <= 0 {
+		return nil
+	}
+	for _, tag := range tags {
+		if err := c.backend.Set(ctx, freshKey(tag), []byte{1}, c.freshWindow); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// freshKey is the key marking the prefixed tag as recently invalidated.
+func freshKey(tag string) string {
+	return tag + ":fresh"
+}
+
+// mustBeFresh reports whether one of the prefixed tags was invalidated in
+// the fresh window. A failure to tell is taken as a yes.
+func (c *Cache) mustBeFresh(ctx context.Context, tags []string) bool {
+	if c.fresh == nil || c.freshWindow <= 0 {
+		return false
+	}
+	for _, tag := range tags {
+		if _, ok, err := c.backend.Get(ctx, freshKey(tag)); ok || err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Close closes the backend if it holds any resource.
+func (c *Cache) Close() error {
+	if c == nil {
+		return nil
+	}
+	if cl, ok := c.backend.(interface{ Close() error }); ok {
+		return cl.Close()
+	}
+	return nil
+}
+
+func (c *Cache) prefixed(vs []string) []string {
+	if c.prefix == "" {
+		return vs
+	}
+	res := make([]string, len(vs))
+	for i, v := range vs {
+		res[i] = c.prefix + v
+	}
+	return res
+}
+
+// Entry describes how a loaded value is cached.
+type Entry struct {
+	Key  string
+	TTL  time.Duration // the cache default when zero.
+	Tags []string
+}
+
+// GetOrLoad returns the cached value of the entry, or loads, caches and
+// returns it. Concurrent loads of the same key are collapsed into one, which
+// is not canceled with the ctx of the caller running it, so that the other
+// callers still get the value.
+//
+// The loads of the tags invalidated in the fresh window read fresh data, see
+// WithFreshReads. A value loaded while its tags were invalidated may be
+// stale, it is returned but not cached.
+//
+// Backend failures are not fatal: the value is loaded as if it was not
+// cached.
+func GetOrLoad[T any](ctx context.Context, c *Cache, e Entry, load func(ctx context.Context) (T, error)) (T, error) {
+	if c == nil {
+		return load(ctx)
+	}
+
+	var (
+		zero T
+		key  = c.prefix + e.Key
+	)
+
+	if b, ok, err := c.backend.Get(ctx, key); err == nil && ok {
+		v := new(T)
+		if err := json.Unmarshal(b, v); err == nil {
+			return *v, nil
+		}
+	}
+
+	v, err, _ := c.group.Do(key, func() (any, error) {
+		var (
+			ctx   = context.WithoutCancel(ctx)
+			tags  = c.prefixed(e.Tags)
+			fresh = c.mustBeFresh(ctx, tags)
+		)
+		loadCtx := ctx
+		if fresh {
+			loadCtx = c.fresh(ctx)
+		}
+		v, err := load(loadCtx)
+		if err != nil {
+			return nil, err
+		}
+		if !fresh && c.mustBeFresh(ctx, tags) {
+			return v, nil
+		}
+
+		if b, err := json.Marshal(v); err == nil {
+			ttl := e.TTL
+			if ttl == 0 {
+				ttl = c.defaultTTL
+			}
+			if err := c.backend.Set(ctx, key, b, ttl, tags...); err != nil {
+				logging.FromContext(ctx).Warn("failed to cache", "key", key, "error", err)
+			}
+		}
+		return v, nil
+	})
+	if err != nil {
+		return zero, err
+	}
+	return v.(T), nil
+}
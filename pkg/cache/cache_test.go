@@ -0,0 +1,327 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// backends returns the backends the tests run on, redis being served by
+// miniredis.
+func backends(t *testing.T) map[string]func(t *testing.T) Backend {
+	return map[string]func(t *testing.T) Backend{
+		"lru": func(t *testing.T) Backend {
+			return NewLRU(16)
+		},
+		"redis": func(t *testing.T) Backend {
+			srv := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+			t.Cleanup(func() { client.Close() })
+			return NewRedisWithClient(client)
+		},
+	}
+}
+
+func TestBackend(t *testing.T) {
+	ctx := context.Background()
+
+	for name, newBackend := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			tests := []struct {
+				name string
+				run  func(t *testing.T, b Backend)
+			}{
+				{
+					name: "get missing",
+					run: func(t *testing.T, b Backend) {
+						if _, ok, err := b.Get(ctx, "k"); ok || err != nil {
+							t.Fatalf("Get() = %v, %v, want a miss", ok, err)
+						}
+					},
+				},
+				{
+					name: "set and get",
+					run: func(t *testing.T, b Backend) {
+						mustSet(t, b, "k", "v", time.Minute)
+						wantValue(t, b, "k", "v")
+					},
+				},
+				{
+					name: "delete",
+					run: func(t *testing.T, b Backend) {
+						mustSet(t, b, "k", "v", time.Minute)
+						if err := b.Delete(ctx, "k"); err != nil {
+							t.Fatal(err)
+						}
+						wantMiss(t, b, "k")
+					},
+				},
+				{
+					name: "invalidate tags",
+					run: func(t *testing.T, b Backend) {
+						mustSet(t, b, "a", "1", time.Minute, "articles")
+						mustSet(t, b, "b", "2", time.Minute, "articles", "article:b")
+						mustSet(t, b, "c", "3", time.Minute, "projects")
+						if err := b.InvalidateTags(ctx, "articles"); err != nil {
+							t.Fatal(err)
+						}
+						wantMiss(t, b, "a")
+						wantMiss(t, b, "b")
+						wantValue(t, b, "c", "3")
+					},
+				},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					tt.run(t, newBackend(t))
+				})
+			}
+		})
+	}
+}
+
+func TestRedisTagExpiry(t *testing.T) {
+	var (
+		srv    = miniredis.RunT(t)
+		client = redis.NewClient(&redis.Options{Addr: srv.Addr()})
+		b      = NewRedisWithClient(client)
+	)
+	defer client.Close()
+
+	tests := []struct {
+		name string
+		ttls []time.Duration
+		want time.Duration
+	}{
+		{name: "expires with its key", ttls: []time.Duration{time.Minute}, want: time.Minute},
+		{name: "expires with its longest lived key", ttls: []time.Duration{time.Hour, time.Minute}, want: time.Hour},
+		{name: "is extended by a longer lived key", ttls: []time.Duration{time.Minute, time.Hour}, want: time.Hour},
+		{name: "does not expire with a key without expiry", ttls: []time.Duration{time.Minute, 0}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv.FlushAll()
+			for i, ttl := range tt.ttls {
+				mustSet(t, b, string(rune('a'+i)), "v", ttl, "tag")
+			}
+			if got := srv.TTL(tagKeyPrefix + "tag"); got != tt.want {
+				t.Errorf("ttl of the tag = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetOrLoad(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("caches the loaded value", func(t *testing.T) {
+		var (
+			c     = NewWithBackend(NewLRU(16), "p:", time.Minute)
+			loads int
+		)
+		load := func(context.Context) (string, error) {
+			loads++
+			return "v", nil
+		}
+		for range 3 {
+			v, err := GetOrLoad(ctx, c, Entry{Key: "k"}, load)
+			if err != nil || v != "v" {
+				t.Fatalf("GetOrLoad() = %q, %v", v, err)
+			}
+		}
+		if loads != 1 {
+			t.Errorf("loaded %d times, want 1", loads)
+		}
+		wantValue(t, c.Backend(), "p:k", `"v"`)
+	})
+
+	t.Run("does not cache errors", func(t *testing.T) {
+		var (
+			c       = NewWithBackend(NewLRU(16), "", time.Minute)
+			errLoad = errors.New("load failed")
+		)
+		if _, err := GetOrLoad(ctx, c, Entry{Key: "k"}, func(context.Context) (int, error) { return 0, errLoad }); !errors.Is(err, errLoad) {
+			t.Fatalf("GetOrLoad() error = %v, want %v", err, errLoad)
+		}
+		wantMiss(t, c.Backend(), "k")
+	})
+
+	t.Run("nil cache loads", func(t *testing.T) {
+		var c *Cache
+		v, err := GetOrLoad(ctx, c, Entry{Key: "k"}, func(context.Context) (int, error) { return 1, nil })
+		if err != nil || v != 1 {
+			t.Fatalf("GetOrLoad() = %d, %v", v, err)
+		}
+	})
+
+	t.Run("a canceled caller does not fail the others", func(t *testing.T) {
+		var (
+			c       = NewWithBackend(NewLRU(16), "", time.Minute)
+			started = make(chan struct{})
+			release = make(chan struct{})
+			loads   atomic.Int32
+			wg      sync.WaitGroup
+		)
+		load := func(ctx context.Context) (string, error) {
+			loads.Add(1)
+			close(started)
+			<-release
+			return "v", ctx.Err()
+		}
+
+		cctx, cancel := context.WithCancel(ctx)
+		var firstErr, secondErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, firstErr = GetOrLoad(cctx, c, Entry{Key: "k"}, load)
+		}()
+		<-started
+		go func() {
+			defer wg.Done()
+			_, secondErr = GetOrLoad(ctx, c, Entry{Key: "k"}, load)
+		}()
+		// let the second caller join the load.
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+		close(release)
+		wg.Wait()
+
+		if firstErr != nil || secondErr != nil {
+			t.Fatalf("errors = %v, %v, want none", firstErr, secondErr)
+		}
+		if n := loads.Load(); n != 1 {
+			t.Errorf("loaded %d times, want 1", n)
+		}
+	})
+}
+
+type freshKeyCtx struct{}
+
+func TestGetOrLoadFreshReads(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		fresh = func(ctx context.Context) context.Context { return context.WithValue(ctx, freshKeyCtx{}, true) }
+		entry = Entry{Key: "k", Tags: []string{"t"}}
+	)
+	isFresh := func(ctx context.Context) bool {
+		v, _ := ctx.Value(freshKeyCtx{}).(bool)
+		return v
+	}
+
+	const window = 50 * time.Millisecond
+	// elapse moves the clock of the backend past the window.
+	backends := map[string]func(t *testing.T) (Backend, func()){
+		"lru": func(t *testing.T) (Backend, func()) {
+			return NewLRU(16), func() { time.Sleep(2 * window) }
+		},
+		"redis": func(t *testing.T) (Backend, func()) {
+			srv := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+			t.Cleanup(func() { client.Close() })
+			return NewRedisWithClient(client), func() { srv.FastForward(2 * window) }
+		},
+	}
+
+	for name, newBackend := range backends {
+		t.Run(name, func(t *testing.T) {
+			b, elapse := newBackend(t)
+			c := NewWithBackend(b, "p:", time.Minute).WithFreshReads(window, fresh)
+
+			tests := []struct {
+				name      string
+				setup     func(t *testing.T)
+				load      func(ctx context.Context) (bool, error)
+				wantFresh bool
+				wantCache bool
+			}{
+				{
+					name:      "not invalidated",
+					wantCache: true,
+				},
+				{
+					name: "invalidated in the window",
+					setup: func(t *testing.T) {
+						if err := c.InvalidateTags(ctx, "t"); err != nil {
+							t.Fatal(err)
+						}
+					},
+					wantFresh: true,
+					wantCache: true,
+				},
+				{
+					name: "invalidated before the window",
+					setup: func(t *testing.T) {
+						if err := c.InvalidateTags(ctx, "t"); err != nil {
+							t.Fatal(err)
+						}
+						elapse()
+					},
+					wantCache: true,
+				},
+				{
+					name: "invalidated while loading",
+					load: func(ctx context.Context) (bool, error) {
+						if err := c.InvalidateTags(ctx, "t"); err != nil {
+							return false, err
+						}
+						return isFresh(ctx), nil
+					},
+				},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					if err := c.Delete(ctx, entry.Key); err != nil {
+						t.Fatal(err)
+					}
+					if tt.setup != nil {
+						tt.setup(t)
+					}
+					load := tt.load
+					if load == nil {
+						load = func(ctx context.Context) (bool, error) { return isFresh(ctx), nil }
+					}
+
+					got, err := GetOrLoad(ctx, c, entry, load)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if got != tt.wantFresh {
+						t.Errorf("loaded fresh = %v, want %v", got, tt.wantFresh)
+					}
+					if _, ok, _ := c.Backend().Get(ctx, "p:k"); ok != tt.wantCache {
+						t.Errorf("cached = %v, want %v", ok, tt.wantCache)
+					}
+				})
+			}
+		})
+	}
+}
+
+func mustSet(t *testing.T, b Backend, key, val string, ttl time.Duration, tags ...string) {
+	t.Helper()
+	if err := b.Set(context.Background(), key, []byte(val), ttl, tags...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func wantValue(t *testing.T, b Backend, key, want string) {
+	t.Helper()
+	got, ok, err := b.Get(context.Background(), key)
+	if err != nil || !ok || string(got) != want {
+		t.Fatalf("Get(%q) = %q, %v, %v, want %q", key, got, ok, err, want)
+	}
+}
+
+func wantMiss(t *testing.T, b Backend, key string) {
+	t.Helper()
+	if _, ok, err := b.Get(context.Background(), key); ok || err != nil {
+		t.Fatalf("Get(%q) = %v, %v, want a miss", key, ok, err)
+	}
+}
@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultLRUSize is the capacity of the LRU when none is given.
+const defaultLRUSize = 1024
+
+// LRU is an in-memory backend which evicts the least recently used entries
+// once it is full. Entries are local to the process, so invalidations are not
+// seen by other instances until the entries expire.
+type LRU struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+	tags  map[string]map[string]struct{}
+}
+
+type lruEntry struct {
+	key       string
+	val       []byte
+	expiresAt time.Time // zero never expires.
+	tags      []string
+}
+
+// NewLRU creates a new LRU backend holding at most size entries.
+func NewLRU(size int) *LRU {
+	if size <= 0 {
+		size = defaultLRUSize
+	}
+	return &LRU{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+		tags:  make(map[string]map[string]struct{}),
+	}
+}
+
+// Get implements Backend.
+func (l *LRU) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	el, ok := l.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	e := el.Value.(*lruEntry)
+	if !e.expiresAt.IsZero() && time.Now().After(e.expiresAt) {
+		l.remove(el)
+		return nil, false, nil
+	}
+
+	l.ll.MoveToFront(el)
+	return e.val, true, nil
+}
+
+// Set implements Backend.
+func (l *LRU) Set(ctx context.Context, key string, val []byte, ttl time.Duration, tags ...string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if el, ok := l.items[key]; ok {
+		l.remove(el)
+	}
+
+	e := &lruEntry{key: key, val: val, tags: tags}
+	if ttl > 0 {
+		e.expiresAt = time.Now().Add(ttl)
+	}
+	l.items[key] = l.ll.PushFront(e)
+	for _, t := range tags {
+		if l.tags[t] == nil {
+			l.tags[t] = make(map[string]struct{})
+		}
+		l.tags[t][key] = struct{}{}
+	}
+
+	for l.ll.Len() > l.size {
+		l.remove(l.ll.Back())
+	}
+	return nil
+}
+
+// Delete implements Backend.
+func (l *LRU) Delete(ctx context.Context, keys ...string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, k := range keys {
+		if el, ok := l.items[k]; ok {
+			l.remove(el)
+		}
+	}
+	return nil
+}
+
+// InvalidateTags implements Backend.
+func (l *LRU) InvalidateTags(ctx context.Context, tags ...string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, t := range tags {
+		for k := range l.tags[t] {
+			if el, ok := l.items[k]; ok {
+				l.remove(el)
+			}
+		}
+		delete(l.tags, t)
+	}
+	return nil
+}
+
+// Len returns the number of entries, including the expired ones not yet
+// evicted.
+func (l *LRU) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.ll.Len()
+}
+
+func (l *LRU) remove(el *list.Element) {
+	e := el.Value.(*lruEntry)
+	l.ll.Remove(el)
+	delete(l.items, e.key)
+	for _, t := range e.tags {
+		delete(l.tags[t], e.key)
+		if len(l.tags[t]) == 0 {
+			delete(l.tags, t)
+		}
+	}
+}
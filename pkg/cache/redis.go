@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisConfig contains the redis connection configuration. The tags need
+// redis 7 or later.
+type RedisConfig struct {
+	Addr     string `envconfig:"ADDR"`
+	Username string `envconfig:"USERNAME"`
+	Password string `envconfig:"PASSWORD"`
+	DB       int    `envconfig:"DB"`
+	TLS      bool   `envconfig:"TLS"`
+}
+
+// tagKeyPrefix namespaces the sets holding the keys of each tag.
+const tagKeyPrefix = "tag:"
+
+// Redis is a backend storing entries in redis, shared by every instance.
+//
+// The keys of a tag are kept in a set which is removed when the tag is
+// invalidated, and expires with its longest lived key otherwise.
+type Redis struct {
+	client redis.UniversalClient
+	closer func() error
+}
+
+// NewRedis creates a new Redis backend connected with the config.
+func NewRedis(cfg *RedisConfig) *Redis {
+	opts := &redis.Options{
+		Addr:     cfg.Addr,
+		Username: cfg.Username,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	}
+	if cfg.TLS {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	client := redis.NewClient(opts)
+	return &Redis{client: client, closer: client.Close}
+}
+
+// NewRedisWithClient creates a new Redis backend on top of the client. The
+// client is not closed by the backend.
+func NewRedisWithClient(client redis.UniversalClient) *Redis {
+	return &Redis{client: client}
+}
+
+// Get implements Backend.
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	b, err := r.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return b, true, nil
+}
+
+// Set implements Backend.
+func (r *Redis) Set(ctx context.Context, key string, val []byte, ttl time.Duration, tags ...string) error {
+	_, err := r.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.Set(ctx, key, val, ttl)
+		for _, t := range tags {
+			tagKey := tagKeyPrefix + t
+			p.SAdd(ctx, tagKey, key)
+			if ttl > 0 {
+				// a new set expires with the key, an existing one only
+				// lives longer.
+				p.ExpireNX(ctx, tagKey, ttl)
+				p.ExpireGT(ctx, tagKey, ttl)
+			} else {
+				p.Persist(ctx, tagKey)
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+// Delete implements Backend.
+func (r *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return r.client.Del(ctx, keys...).Err()
+}
+
+// InvalidateTags implements Backend.
+func (r *Redis) InvalidateTags(ctx context.Context, tags ...string) error {
+	for _, t := range tags {
+		tagKey := tagKeyPrefix + t
+		keys, err := r.client.SMembers(ctx, tagKey).Result()
+		if err != nil {
+			return err
+		}
+		if err := r.client.Del(ctx, append(keys, tagKey)...).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the connection of a backend created by NewRedis.
+func (r *Redis) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer()
+}
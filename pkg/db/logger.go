@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+
+	"github.com/cirius-go/portfolio-server/pkg/logging"
+)
+
+// QueryLogger is a gorm logger writing structured lines with the logger of
+// the context, so that they carry its request attributes. It logs the failed
+// queries and the ones slower than its threshold as allowed by its level, and
+// every query of the contexts it is verbose for. The queries are logged
+// without their values, which can be personal data or secrets.
+type QueryLogger struct {
+	level         logger.LogLevel
+	slowThreshold time.Duration
+	verbose       func(ctx context.Context) bool
+}
+
+// NewQueryLogger creates the logger.
+func NewQueryLogger(level logger.LogLevel, slowThreshold time.Duration) *QueryLogger {
+	return &QueryLogger{
+		level:         level,
+		slowThreshold: slowThreshold,
+	}
+}
+
+// WithVerbose sets the function which tells if every query of a context is
+// logged.
+func (l *QueryLogger) WithVerbose(fn func(ctx context.Context) bool) *QueryLogger {
+	l.verbose = fn
+	return l
+}
+
+// LogMode implements logger.Interface.
+func (l *QueryLogger) LogMode(level logger.LogLevel) logger.Interface {
+	c := *l
+	c.level = level
+	return &c
+}
+
+// Info implements logger.Interface.
+func (l *QueryLogger) Info(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, logger.Info, slog.LevelInfo, msg, args)
+}
+
+// Warn implements logger.Interface.
+func (l *QueryLogger) Warn(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, logger.Warn, slog.LevelWarn, msg, args)
+}
+
+// Error implements logger.Interface.
+func (l *QueryLogger) Error(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, logger.Error, slog.LevelError, msg, args)
+}
+
+func (l *QueryLogger) log(ctx context.Context, min logger.LogLevel, level slog.Level, msg string, args []any) {
+	if l.level < min && !l.isVerbose(ctx) {
+		return
+	}
+	logging.FromContext(ctx).Log(ctx, level, fmt.Sprintf(msg, args...), "source", utils.FileWithLineNum())
+}
+
+// ParamsFilter implements gorm.ParamsFilter, it drops the values of the
+// logged queries.
+func (l *QueryLogger) ParamsFilter(ctx context.Context, sql string, params ...any) (string, []any) {
+	return sql, nil
+}
+
+// Trace implements logger.Interface.
+func (l *QueryLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	var (
+		elapsed = time.Since(begin)
+		verbose = l.isVerbose(ctx)
+		level   slog.Level
+		msg     string
+	)
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && (l.level >= logger.Error || verbose):
+		level, msg = slog.LevelError, "query failed"
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && (l.level != logger.Silent || verbose):
+		level, msg = slog.LevelWarn, "slow query"
+	case l.level >= logger.Info || verbose:
+		level, msg = slog.LevelInfo, "query"
+	default:
+		return
+	}
+
+	sql, rows := fc()
+	args := []any{
+		"sql", sql,
+		"rows", rows,
+		"elapsed", elapsed,
+		"source", utils.FileWithLineNum(),
+	}
+	if err != nil {
+		args = append(args, "error", err)
+	}
+	logging.FromContext(ctx).Log(ctx, level, msg, args...)
+}
+
+func (l *QueryLogger) isVerbose(ctx context.Context) bool {
+	return l.verbose != nil && l.verbose(ctx)
+}
@@ -0,0 +1,43 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm/logger"
+
+	"github.com/cirius-go/portfolio-server/pkg/logging"
+)
+
+func TestQueryLoggerSlowQueries(t *testing.T) {
+	const threshold = 200 * time.Millisecond
+
+	tests := []struct {
+		name    string
+		level   logger.LogLevel
+		elapsed time.Duration
+		want    bool
+	}{
+		{name: "unset level", elapsed: time.Second, want: true},
+		{name: "error level", level: logger.Error, elapsed: time.Second, want: true},
+		{name: "silent", level: logger.Silent, elapsed: time.Second},
+		{name: "fast query", level: logger.Warn, elapsed: time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			ctx := logging.WithContext(context.Background(), slog.New(slog.NewTextHandler(&buf, nil)))
+
+			l := NewQueryLogger(tt.level, threshold)
+			l.Trace(ctx, time.Now().Add(-tt.elapsed), func() (string, int64) { return "SELECT 1", 1 }, nil)
+
+			if got := strings.Contains(buf.String(), "slow query"); got != tt.want {
+				t.Errorf("slow query logged = %v, want %v: %s", got, tt.want, buf.String())
+			}
+		})
+	}
+}
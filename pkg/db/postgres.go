@@ -1,26 +1,58 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/url"
+	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
 
 	"github.com/cirius-go/portfolio-server/util"
 )
 
 type PostgresConfig struct {
 	DSN      string            `envconfig:"DSN"`
-	Host     string            `envconfig:"HOST"`
-	Port     int               `envconfig:"PORT"`
+	Host     string            `envconfig:"HOST" validate:"required_without=DSN"`
+	Port     int               `envconfig:"PORT" validate:"min=0,max=65535"`
 	Username string            `envconfig:"USERNAME"`
 	Password string            `envconfig:"PASSWORD"`
-	Database string            `envconfig:"DATABASE"`
+	Database string            `envconfig:"DATABASE" validate:"required_without=DSN"`
 	Args     util.QueryDecoder `envconfig:"ARGS"`
-	LogLevel logger.LogLevel   `envconfig:"LOG_LEVEL"` // default 3
+	LogLevel logger.LogLevel   `envconfig:"LOG_LEVEL"` // 1 silent, 2 error, 3 warn, 4 info.
+	// SlowThreshold is the duration above which a query is logged as slow,
+	// at any log level but silent. Disabled when zero.
+	SlowThreshold time.Duration `envconfig:"SLOW_THRESHOLD"`
+
+	// MaxOpenConns, MaxIdleConns, ConnMaxLifetime and ConnMaxIdleTime size
+	// the pool of each connection, zero keeps the database/sql default.
+	MaxOpenConns    int           `envconfig:"MAX_OPEN_CONNS" validate:"min=0"`
+	MaxIdleConns    int           `envconfig:"MAX_IDLE_CONNS" validate:"min=0"`
+	ConnMaxLifetime time.Duration `envconfig:"CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `envconfig:"CONN_MAX_IDLE_TIME"`
+
+	// StatementCache prepares and caches the statements on each connection.
+	// It needs session pooling, so the simple protocol is used when it is
+	// off, e.g. behind RDS Proxy or PgBouncer in transaction mode.
+	StatementCache bool `envconfig:"STATEMENT_CACHE"`
+	// StatementCacheSize is the number of statements cached per connection,
+	// the pgx default when zero.
+	StatementCacheSize int `envconfig:"STATEMENT_CACHE_SIZE"`
+
+	// ReplicaDSNs are the read replicas, reads use the primary when empty.
+	ReplicaDSNs []string `envconfig:"REPLICA_DSNS"`
+	// ReplicaCheckInterval is how often the replicas are pinged, 10s when
+	// zero.
+	ReplicaCheckInterval time.Duration `envconfig:"REPLICA_CHECK_INTERVAL"`
 }
 
 // buildDSN string.
@@ -49,16 +81,53 @@ func (c *PostgresConfig) buildDSN() string {
 	return u.String()
 }
 
+// open opens a pool on the dsn.
+func (c *PostgresConfig) open(dsn string) (*sql.DB, error) {
+	pgxCfg, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.StatementCache {
+		pgxCfg.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+	} else if c.StatementCacheSize > 0 {
+		pgxCfg.StatementCacheCapacity = c.StatementCacheSize
+	}
+
+	conn := stdlib.OpenDB(*pgxCfg)
+	if c.MaxOpenConns > 0 {
+		conn.SetMaxOpenConns(c.MaxOpenConns)
+	}
+	if c.MaxIdleConns > 0 {
+		conn.SetMaxIdleConns(c.MaxIdleConns)
+	}
+	if c.ConnMaxLifetime > 0 {
+		conn.SetConnMaxLifetime(c.ConnMaxLifetime)
+	}
+	if c.ConnMaxIdleTime > 0 {
+		conn.SetConnMaxIdleTime(c.ConnMaxIdleTime)
+	}
+	return conn, nil
+}
+
 // Postgres contains configured gorm + connection.
 type Postgres struct {
-	Conn *sql.DB
-	DB   *gorm.DB
+	Conn   *sql.DB
+	DB     *gorm.DB
+	Logger *QueryLogger
+
+	replicas *replicaPolicy
+	stop     context.CancelFunc
 }
 
 // NewPostgres connects the database and config gorm.
 func NewPostgres(cfg PostgresConfig) (*Postgres, error) {
+	p := &Postgres{
+		Logger: NewQueryLogger(cfg.LogLevel, cfg.SlowThreshold),
+	}
+
 	gormCfg := &gorm.Config{
-		Logger:                                   logger.Default.LogMode(cfg.LogLevel),
+		Logger:                                   p.Logger,
 		IgnoreRelationshipsWhenMigrating:         true,
 		DisableForeignKeyConstraintWhenMigrating: true,
 		CreateBatchSize:                          1000,
@@ -72,20 +141,120 @@ func NewPostgres(cfg PostgresConfig) (*Postgres, error) {
 		return nil, fmt.Errorf("empty dsn")
 	}
 
-	db, err := gorm.Open(postgres.New(postgres.Config{
-		DSN:                  addr,
-		PreferSimpleProtocol: true,
-	}), gormCfg)
+	conn, err := cfg.open(addr)
 	if err != nil {
 		return nil, err
 	}
 
-	p := &Postgres{}
-	p.Conn, err = db.DB()
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), gormCfg)
 	if err != nil {
+		conn.Close()
 		return nil, err
 	}
 
+	p.Conn = conn
 	p.DB = db
+	if err := registerTracing(db); err != nil {
+		p.Close()
+		return nil, err
+	}
+	if len(cfg.ReplicaDSNs) > 0 {
+		if err := p.useReplicas(cfg); err != nil {
+			p.Close()
+			return nil, err
+		}
+	}
 	return p, nil
 }
+
+// useReplicas routes the reads outside of transactions to the replicas and
+// starts their health check.
+func (p *Postgres) useReplicas(cfg PostgresConfig) error {
+	p.replicas = &replicaPolicy{primary: p.Conn}
+
+	dialectors := make([]gorm.Dialector, 0, len(cfg.ReplicaDSNs))
+	for i, dsn := range cfg.ReplicaDSNs {
+		conn, err := cfg.open(dsn)
+		if err != nil {
+			return fmt.Errorf("open replica %d: %w", i, err)
+		}
+
+		r := &replica{name: fmt.Sprintf("replica_%d", i), conn: conn}
+		r.healthy.Store(true)
+		p.replicas.replicas = append(p.replicas.replicas, r)
+
+		dialectors = append(dialectors, postgres.New(postgres.Config{Conn: conn}))
+	}
+
+	err := p.DB.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: dialectors,
+		Policy:   p.replicas,
+	}))
+	if err != nil {
+		return err
+	}
+	if err := registerForcePrimary(p.DB); err != nil {
+		return err
+	}
+
+	interval := cfg.ReplicaCheckInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	var ctx context.Context
+	ctx, p.stop = context.WithCancel(context.Background())
+	go p.replicas.watch(ctx, interval)
+	return nil
+}
+
+// Close stops the replica health check and closes every connection.
+func (p *Postgres) Close() error {
+	if p.stop != nil {
+		p.stop()
+	}
+
+	var errs []error
+	if p.replicas != nil {
+		for _, r := range p.replicas.replicas {
+			errs = append(errs, r.conn.Close())
+		}
+	}
+	errs = append(errs, p.Conn.Close())
+	return errors.Join(errs...)
+}
+
+// Stats returns the pool statistics of the primary and of each replica.
+func (p *Postgres) Stats() map[string]sql.DBStats {
+	stats := map[string]sql.DBStats{"primary": p.Conn.Stats()}
+	if p.replicas != nil {
+		for _, r := range p.replicas.replicas {
+			stats[r.name] = r.conn.Stats()
+		}
+	}
+	return stats
+}
+
+// RegisterMetrics registers the pool statistics of the primary and of each
+// replica, labeled by db_name, and the number of healthy replicas.
+func (p *Postgres) RegisterMetrics(reg prometheus.Registerer) error {
+	cs := []prometheus.Collector{
+		collectors.NewDBStatsCollector(p.Conn, "primary"),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "db_replicas_healthy",
+			Help: "Number of read replicas in rotation.",
+		}, func() float64 { return float64(p.HealthyReplicas()) }),
+	}
+	if p.replicas != nil {
+		for _, r := range p.replicas.replicas {
+			cs = append(cs, collectors.NewDBStatsCollector(r.conn, r.name))
+		}
+	}
+
+	for _, c := range cs {
+		if err := reg.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+type primaryKey struct{}
+
+// WithPrimary returns a copy of ctx whose reads go to the primary, so that
+// they see the writes which were not replicated yet.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// PrimaryForced reports whether the reads of ctx must go to the primary.
+func PrimaryForced(ctx context.Context) bool {
+	v, _ := ctx.Value(primaryKey{}).(bool)
+	return v
+}
+
+// registerForcePrimary routes the reads of the statements whose context was
+// made by WithPrimary to the primary.
+func registerForcePrimary(db *gorm.DB) error {
+	force := func(db *gorm.DB) {
+		if ctx := db.Statement.Context; ctx != nil && PrimaryForced(ctx) {
+			dbresolver.Write.ModifyStatement(db.Statement)
+		}
+	}
+
+	cb := db.Callback()
+	if err := cb.Query().Before("gorm:db_resolver").Register("db:force_primary", force); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:db_resolver").Register("db:force_primary", force); err != nil {
+		return err
+	}
+	return cb.Raw().Before("gorm:db_resolver").Register("db:force_primary", force)
+}
+
+// replica is a read replica and its last known health.
+type replica struct {
+	name    string
+	conn    *sql.DB
+	healthy atomic.Bool
+}
+
+// replicaPolicy is a dbresolver.Policy which takes the healthy replicas in
+// turn, or the primary when none is healthy.
+type replicaPolicy struct {
+	primary  gorm.ConnPool
+	replicas []*replica
+	next     atomic.Uint64
+}
+
+// Resolve implements dbresolver.Policy.
+func (p *replicaPolicy) Resolve([]gorm.ConnPool) gorm.ConnPool {
+	n := uint64(len(p.replicas))
+	start := p.next.Add(1)
+	for i := range n {
+		if r := p.replicas[(start+i)%n]; r.healthy.Load() {
+			return r.conn
+		}
+	}
+	return p.primary
+}
+
+// Healthy returns the number of replicas in rotation.
+func (p *replicaPolicy) Healthy() int {
+	n := 0
+	for _, r := range p.replicas {
+		if r.healthy.Load() {
+			n++
+		}
+	}
+	return n
+}
+
+// watch pings the replicas every interval until ctx is done, dropping the
+// failing ones from the rotation and restoring them once they answer again.
+func (p *replicaPolicy) watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		p.check(ctx, interval)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *replicaPolicy) check(ctx context.Context, timeout time.Duration) {
+	for _, r := range p.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := r.conn.PingContext(pingCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+
+		healthy := err == nil
+		if r.healthy.Swap(healthy) == healthy {
+			continue
+		}
+		if healthy {
+			slog.Info("replica back in rotation", "replica", r.name)
+		} else {
+			slog.Warn("replica dropped from rotation", "replica", r.name, "error", err)
+		}
+	}
+}
+
+// HealthyReplicas returns the number of read replicas in rotation.
+func (p *Postgres) HealthyReplicas() int {
+	if p.replicas == nil {
+		return 0
+	}
+	return p.replicas.Healthy()
+}
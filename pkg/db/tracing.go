@@ -0,0 +1,100 @@
+package db
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	tracerName  = "github.com/cirius-go/portfolio-server/pkg/db"
+	spanInstKey = "db:span"
+)
+
+// registerTracing adds gorm callbacks producing a client span per statement
+// with the global tracer provider.
+func registerTracing(db *gorm.DB) error {
+	tracer := otel.Tracer(tracerName)
+
+	before := func(op string) func(*gorm.DB) {
+		return func(db *gorm.DB) {
+			if db.Statement.Context == nil {
+				return
+			}
+			ctx, span := tracer.Start(db.Statement.Context, "db."+op,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(semconv.DBSystemPostgreSQL),
+			)
+			db.Statement.Context = ctx
+			db.InstanceSet(spanInstKey, span)
+		}
+	}
+
+	after := func(db *gorm.DB) {
+		v, ok := db.InstanceGet(spanInstKey)
+		if !ok {
+			return
+		}
+		span := v.(trace.Span)
+		defer span.End()
+
+		query := SanitizeSQL(db.Statement.SQL.String())
+		span.SetAttributes(
+			semconv.DBQueryText(query),
+			semconv.DBOperationName(operationOf(query)),
+			semconv.DBCollectionName(db.Statement.Table),
+		)
+		if err := db.Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+
+	cb := db.Callback()
+	for _, p := range []struct {
+		op            string
+		before, after func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	} {
+		if err := p.before("db:trace_before", before(p.op)); err != nil {
+			return err
+		}
+		if err := p.after("db:trace_after", after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// literals matches the string and number literals of a statement, and its
+// placeholders which are kept.
+var literals = regexp.MustCompile(`\$\d+|'(?:[^']|'')*'|\b\d+(?:\.\d+)?\b`)
+
+// SanitizeSQL replaces the literals of the statement with "?", so that no
+// value ends up in the traces.
+func SanitizeSQL(query string) string {
+	return literals.ReplaceAllStringFunc(query, func(m string) string {
+		if strings.HasPrefix(m, "$") {
+			return m
+		}
+		return "?"
+	})
+}
+
+// operationOf returns the first keyword of the statement.
+func operationOf(query string) string {
+	op, _, _ := strings.Cut(strings.TrimSpace(query), " ")
+	return strings.ToUpper(op)
+}
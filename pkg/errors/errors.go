@@ -3,11 +3,13 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 
+	"github.com/cirius-go/portfolio-server/pkg/logging"
 	"github.com/cirius-go/portfolio-server/util"
 )
 
@@ -21,7 +23,7 @@ var (
 )
 
 // ErrorType represents an error type.
-// ENUM(internal,invalid_request,conflict,not_found,unauthorized,forbidden,unknown,upstream)
+// ENUM(internal,invalid_request,conflict,not_found,unauthorized,forbidden,unknown,upstream,too_many_requests)
 //
 //go:generate go-enum --marshal --names --values --ptr
 type ErrorType string
@@ -83,6 +85,11 @@ func (e *AppError) Error() string {
 	return fmt.Sprintf("%s: %s", e.Type, e.Message)
 }
 
+// Unwrap returns the internal error.
+func (e *AppError) Unwrap() error {
+	return e.Internal
+}
+
 // NewInternal creates a new internal error.
 func NewInternal(err error, msg string, args ...any) *AppError {
 	return New(ErrorTypeInternal, http.StatusInternalServerError, err, msg, args...)
@@ -118,6 +125,11 @@ func NewUpstream(err error, msg string, args ...any) *AppError {
 	return New(ErrorTypeUpstream, http.StatusBadGateway, err, msg, args...)
 }
 
+// NewTooManyRequests creates a new too many requests error.
+func NewTooManyRequests(err error, msg string, args ...any) *AppError {
+	return New(ErrorTypeTooManyRequests, http.StatusTooManyRequests, err, msg, args...)
+}
+
 // NewUnknown creates a new unknown error.
 func NewUnknown(err error, msg string, args ...any) *AppError {
 	return New(ErrorTypeUnknown, http.StatusInternalServerError, err, msg, args...)
@@ -142,6 +154,7 @@ func FromEchoError(err error) *AppError {
 
 type ErrorHandlerConfig struct {
 	reqHeaderDebugFlag string
+	serverDebug        func() bool
 }
 
 func NewErrorHandlerConfig() *ErrorHandlerConfig {
@@ -155,6 +168,13 @@ func (e *ErrorHandlerConfig) WithReqHeaderDebugFlag(flag string) *ErrorHandlerCo
 	return e
 }
 
+// WithServerDebug reads the debug mode of the server with fn instead of the
+// one of echo, so that it can change while the server runs.
+func (e *ErrorHandlerConfig) WithServerDebug(fn func() bool) *ErrorHandlerConfig {
+	e.serverDebug = fn
+	return e
+}
+
 func CreateEchoErrorHandler(cfgs ...*ErrorHandlerConfig) echo.HTTPErrorHandler {
 	var cfg = util.IfNull(NewErrorHandlerConfig(), cfgs...)
 	return func(err error, c echo.Context) {
@@ -162,8 +182,12 @@ func CreateEchoErrorHandler(cfgs ...*ErrorHandlerConfig) echo.HTTPErrorHandler {
 			return
 		}
 
+		srvDebug := c.Echo().Debug
+		if cfg.serverDebug != nil {
+			srvDebug = cfg.serverDebug()
+		}
+
 		var (
-			srvDebug   = c.Echo().Debug
 			reqDebug   = util.StrBool(c.Request().Header.Get(cfg.reqHeaderDebugFlag))
 			debug      = util.IfZero(srvDebug, reqDebug)
 			statusCode = http.StatusInternalServerError
@@ -247,8 +271,35 @@ func CreateEchoErrorHandler(cfgs ...*ErrorHandlerConfig) echo.HTTPErrorHandler {
 			statusCode = http.StatusInternalServerError
 		}
 
+		if id := c.Response().Header().Get(echo.HeaderXRequestID); id != "" {
+			res["request_id"] = id
+		}
+		logError(c, statusCode, err)
 		if marshalErr := c.JSON(statusCode, res); marshalErr != nil {
-			fmt.Println("cannot marshal error response", marshalErr)
+			logging.FromContext(c.Request().Context()).Error("cannot marshal error response", "error", marshalErr)
+		}
+	}
+}
+
+// logError logs the server errors, and the client errors whose internal error
+// is hidden from the response.
+func logError(c echo.Context, status int, err error) {
+	var (
+		ae       *AppError
+		internal = errors.As(err, &ae) && ae.Internal != nil
+		level    = slog.LevelError
+		args     = []any{"status", status, "error", err}
+	)
+	if status < http.StatusInternalServerError {
+		if !internal {
+			return
 		}
+		level = slog.LevelWarn
 	}
+	if internal {
+		args = append(args, "internal", ae.Internal)
+	}
+
+	ctx := c.Request().Context()
+	logging.FromContext(ctx).Log(ctx, level, "request failed", args...)
 }
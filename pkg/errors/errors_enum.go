@@ -28,6 +28,8 @@ const (
 	ErrorTypeUnknown ErrorType = "unknown"
 	// ErrorTypeUpstream is a ErrorType of type upstream.
 	ErrorTypeUpstream ErrorType = "upstream"
+	// ErrorTypeTooManyRequests is a ErrorType of type too_many_requests.
+	ErrorTypeTooManyRequests ErrorType = "too_many_requests"
 )
 
 var ErrInvalidErrorType = fmt.Errorf("not a valid ErrorType, try [%s]", strings.Join(_ErrorTypeNames, ", "))
@@ -41,6 +43,7 @@ var _ErrorTypeNames = []string{
 	string(ErrorTypeForbidden),
 	string(ErrorTypeUnknown),
 	string(ErrorTypeUpstream),
+	string(ErrorTypeTooManyRequests),
 }
 
 // ErrorTypeNames returns a list of possible string values of ErrorType.
@@ -61,6 +64,7 @@ func ErrorTypeValues() []ErrorType {
 		ErrorTypeForbidden,
 		ErrorTypeUnknown,
 		ErrorTypeUpstream,
+		ErrorTypeTooManyRequests,
 	}
 }
 
@@ -77,14 +81,15 @@ func (x ErrorType) IsValid() bool {
 }
 
 var _ErrorTypeValue = map[string]ErrorType{
-	"internal":        ErrorTypeInternal,
-	"invalid_request": ErrorTypeInvalidRequest,
-	"conflict":        ErrorTypeConflict,
-	"not_found":       ErrorTypeNotFound,
-	"unauthorized":    ErrorTypeUnauthorized,
-	"forbidden":       ErrorTypeForbidden,
-	"unknown":         ErrorTypeUnknown,
-	"upstream":        ErrorTypeUpstream,
+	"internal":          ErrorTypeInternal,
+	"invalid_request":   ErrorTypeInvalidRequest,
+	"conflict":          ErrorTypeConflict,
+	"not_found":         ErrorTypeNotFound,
+	"unauthorized":      ErrorTypeUnauthorized,
+	"forbidden":         ErrorTypeForbidden,
+	"unknown":           ErrorTypeUnknown,
+	"upstream":          ErrorTypeUpstream,
+	"too_many_requests": ErrorTypeTooManyRequests,
 }
 
 // ParseErrorType attempts to convert a string to a ErrorType.
@@ -0,0 +1,141 @@
+// Package health serves the liveness, readiness and build info probes of the
+// server.
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/cirius-go/portfolio-server/pkg/logging"
+)
+
+// Check reports whether a dependency of the server is usable.
+type Check func(ctx context.Context) error
+
+// Status is the body of the probes.
+type Status struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker runs the readiness checks of the server. It stops being ready once
+// it drains, so that the load balancer sends no new requests to a server
+// which is shutting down.
+//
+// The results are reused for the cache TTL, so that the probes cannot load
+// the dependencies.
+type Checker struct {
+	timeout  time.Duration
+	cacheTTL time.Duration
+	checks   []namedCheck
+	draining atomic.Bool
+
+	mu        sync.Mutex
+	last      *Status
+	lastOK    bool
+	checkedAt time.Time
+}
+
+// NewChecker creates a checker giving each check at most timeout to pass, 5
+// seconds when zero. The results are cached for a second.
+func NewChecker(timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return &Checker{timeout: timeout, cacheTTL: time.Second}
+}
+
+// WithCacheTTL sets how long the results of the checks are reused, they are
+// run on every call when zero.
+func (c *Checker) WithCacheTTL(d time.Duration) *Checker {
+	c.cacheTTL = d
+	return c
+}
+
+// Add registers the check under name.
+func (c *Checker) Add(name string, check Check) *Checker {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+	return c
+}
+
+// Drain makes the checker report the server as not ready from now on.
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Ready runs the checks concurrently and reports whether all of them passed.
+// The checks are reported as ok or failing, their errors are logged.
+func (c *Checker) Ready(ctx context.Context) (*Status, bool) {
+	if c.draining.Load() {
+		return &Status{Status: "draining"}, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.last != nil && time.Since(c.checkedAt) < c.cacheTTL {
+		return c.last, c.lastOK
+	}
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		ok  = true
+		res = &Status{Status: "ok", Checks: make(map[string]string, len(c.checks))}
+	)
+	for _, nc := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+			state := "ok"
+			err := nc.check(ctx)
+			if err != nil {
+				state = "failing"
+				logging.FromContext(ctx).Warn("readiness check failed", "check", nc.name, "error", err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			res.Checks[nc.name] = state
+			ok = ok && err == nil
+		}()
+	}
+	wg.Wait()
+
+	if !ok {
+		res.Status = "failing"
+	}
+	c.last, c.lastOK, c.checkedAt = res, ok, time.Now()
+	return res, ok
+}
+
+// Liveness returns the handler reporting that the process serves requests.
+func (c *Checker) Liveness() echo.HandlerFunc {
+	return func(ec echo.Context) error {
+		return ec.JSON(http.StatusOK, &Status{Status: "ok"})
+	}
+}
+
+// Readiness returns the handler reporting the checks, with 503 Service
+// Unavailable when any of them fails.
+func (c *Checker) Readiness() echo.HandlerFunc {
+	return func(ec echo.Context) error {
+		res, ok := c.Ready(ec.Request().Context())
+		if !ok {
+			return ec.JSON(http.StatusServiceUnavailable, res)
+		}
+		return ec.JSON(http.StatusOK, res)
+	}
+}
@@ -0,0 +1,79 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckerReady(t *testing.T) {
+	ctx := context.Background()
+	failing := func(context.Context) error { return errors.New("dial tcp 10.0.0.12:5432: connection refused") }
+	passing := func(context.Context) error { return nil }
+
+	tests := []struct {
+		name       string
+		checker    func() *Checker
+		wantOK     bool
+		wantStatus Status
+	}{
+		{
+			name:       "passing",
+			checker:    func() *Checker { return NewChecker(0).Add("database", passing) },
+			wantOK:     true,
+			wantStatus: Status{Status: "ok", Checks: map[string]string{"database": "ok"}},
+		},
+		{
+			name: "failing without the error",
+			checker: func() *Checker {
+				return NewChecker(0).Add("database", failing).Add("bucket", passing)
+			},
+			wantStatus: Status{Status: "failing", Checks: map[string]string{"database": "failing", "bucket": "ok"}},
+		},
+		{
+			name: "draining",
+			checker: func() *Checker {
+				c := NewChecker(0).Add("database", passing)
+				c.Drain()
+				return c
+			},
+			wantStatus: Status{Status: "draining"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, ok := tt.checker().Ready(ctx)
+			if ok != tt.wantOK || res.Status != tt.wantStatus.Status || len(res.Checks) != len(tt.wantStatus.Checks) {
+				t.Fatalf("Ready() = %+v, %v, want %+v, %v", res, ok, tt.wantStatus, tt.wantOK)
+			}
+			for name, want := range tt.wantStatus.Checks {
+				if got := res.Checks[name]; got != want {
+					t.Errorf("check %s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckerCache(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		calls int
+	)
+	c := NewChecker(0).WithCacheTTL(50*time.Millisecond).Add("database", func(context.Context) error {
+		calls++
+		return nil
+	})
+
+	c.Ready(ctx)
+	c.Ready(ctx)
+	if calls != 1 {
+		t.Fatalf("checked %d times within the ttl, want 1", calls)
+	}
+	time.Sleep(60 * time.Millisecond)
+	c.Ready(ctx)
+	if calls != 2 {
+		t.Fatalf("checked %d times after the ttl, want 2", calls)
+	}
+}
@@ -0,0 +1,56 @@
+package health
+
+import (
+	"net/http"
+	"runtime/debug"
+
+	"github.com/labstack/echo/v4"
+)
+
+// buildTime is set by the build scripts with
+// -ldflags "-X github.com/cirius-go/portfolio-server/pkg/health.buildTime=...".
+var buildTime string
+
+// BuildInfo describes the running binary.
+type BuildInfo struct {
+	Commit     string `json:"commit,omitempty"`
+	Modified   bool   `json:"modified"`
+	CommitTime string `json:"commit_time,omitempty"`
+	BuildTime  string `json:"build_time,omitempty"`
+	GoVersion  string `json:"go_version"`
+	Stage      string `json:"stage"`
+}
+
+// Build returns the build info of the binary, the commit is recorded by
+// go build -buildvcs.
+func Build(stage string) *BuildInfo {
+	res := &BuildInfo{
+		BuildTime: buildTime,
+		Stage:     stage,
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return res
+	}
+	res.GoVersion = bi.GoVersion
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			res.Commit = s.Value
+		case "vcs.time":
+			res.CommitTime = s.Value
+		case "vcs.modified":
+			res.Modified = s.Value == "true"
+		}
+	}
+	return res
+}
+
+// Version returns the handler of the build info.
+func Version(stage string) echo.HandlerFunc {
+	info := Build(stage)
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, info)
+	}
+}
@@ -0,0 +1,67 @@
+// Package idempotency records the responses of the requests sent with an
+// idempotency key, so that a retried request is answered without being
+// handled twice.
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+// Config contains the idempotency configuration.
+type Config struct {
+	// TTL is how long a key is remembered after its first use.
+	TTL time.Duration `envconfig:"TTL" validate:"gt=0"`
+	// Lease is how long a request holds its key before its response is
+	// recorded. A key whose request neither completed nor released it, e.g.
+	// because the instance died, can be claimed again once its lease ends. It
+	// should exceed the request timeout.
+	Lease time.Duration `envconfig:"LEASE" validate:"gt=0"`
+}
+
+// Record is the state of a key.
+type Record struct {
+	Key         string `gorm:"primaryKey"`
+	Fingerprint string `gorm:"not null"`
+	// Status is the status of the response, 0 while the request is handled.
+	Status      int    `gorm:"not null;default:0"`
+	ContentType string `gorm:"not null;default:''"`
+	Body        []byte
+	CreatedAt   time.Time
+	// ExpiresAt is the end of the lease while the request is handled, and
+	// of the TTL once its response is recorded.
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+// TableName implements schema.Tabler.
+func (Record) TableName() string {
+	return "idempotency_keys"
+}
+
+// Completed reports whether the response of the request is recorded.
+func (r *Record) Completed() bool {
+	return r.Status != 0
+}
+
+// Claim is the hold of a request on its key. A request whose lease ended
+// and whose key was claimed again no longer holds it.
+type Claim struct {
+	Key         string
+	Fingerprint string
+	CreatedAt   time.Time
+}
+
+// Store holds the records of the keys.
+type Store interface {
+	// Begin claims the unused or expired key for the request with the
+	// fingerprint, for the lease. It returns the claim, or the record of the
+	// request holding the key.
+	Begin(ctx context.Context, key, fingerprint string) (*Claim, *Record, error)
+	// Complete records the response of the request of the claim while it
+	// holds the key, which is then remembered for the TTL.
+	Complete(ctx context.Context, claim *Claim, status int, contentType string, body []byte) error
+	// Release frees the key of the request of the claim while it holds the
+	// key and its response is not recorded, so that the request can be sent
+	// again with it.
+	Release(ctx context.Context, claim *Claim) error
+}
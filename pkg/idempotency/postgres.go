@@ -0,0 +1,94 @@
+package idempotency
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/cirius-go/portfolio-server/pkg/db"
+	"github.com/cirius-go/portfolio-server/pkg/logging"
+)
+
+// sweepInterval is how often the expired records are deleted.
+const sweepInterval = time.Hour
+
+// Postgres is a store keeping the records in the database. The expired
+// records, and the requests whose lease ended, are replaced when their key is
+// used again, and deleted by the requests at most once per sweep interval.
+type Postgres struct {
+	db        *gorm.DB
+	ttl       time.Duration
+	lease     time.Duration
+	lastSweep atomic.Int64
+}
+
+// NewPostgres creates a new Postgres store with the TTL and lease of cfg.
+func NewPostgres(db *gorm.DB, cfg Config) *Postgres {
+	p := &Postgres{db: db, ttl: cfg.TTL, lease: cfg.Lease}
+	p.lastSweep.Store(time.Now().UnixNano())
+	return p
+}
+
+// Begin implements Store.
+func (p *Postgres) Begin(ctx context.Context, key, fingerprint string) (*Claim, *Record, error) {
+	now := time.Now()
+	if last := p.lastSweep.Load(); now.UnixNano()-last > int64(sweepInterval) && p.lastSweep.CompareAndSwap(last, now.UnixNano()) {
+		// the next sweep deletes what this one missed.
+		if err := p.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&Record{}).Error; err != nil {
+			logging.FromContext(ctx).Warn("failed to delete the expired idempotency keys", "error", err)
+		}
+	}
+
+	// created_at is returned as stored, which is less precise than now.
+	var claimed []time.Time
+	err := p.db.WithContext(ctx).Raw(`
+		INSERT INTO idempotency_keys (key, fingerprint, status, content_type, created_at, expires_at)
+		VALUES (@key, @fingerprint, 0, '', @now, @expires_at)
+		ON CONFLICT (key) DO UPDATE SET
+			fingerprint = EXCLUDED.fingerprint,
+			status = 0,
+			content_type = '',
+			body = NULL,
+			created_at = EXCLUDED.created_at,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= @now
+		RETURNING created_at`,
+		map[string]any{"key": key, "fingerprint": fingerprint, "now": now, "expires_at": now.Add(p.lease)},
+	).Scan(&claimed).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(claimed) > 0 {
+		return &Claim{Key: key, Fingerprint: fingerprint, CreatedAt: claimed[0]}, nil, nil
+	}
+
+	rec := &Record{}
+	err = p.db.WithContext(db.WithPrimary(ctx)).Where("key = ?", key).Take(rec).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, rec, nil
+}
+
+// held scopes a query to the record of the claim while its response is not
+// recorded.
+func held(tx *gorm.DB, c *Claim) *gorm.DB {
+	return tx.Where("key = ? AND fingerprint = ? AND created_at = ? AND status = 0", c.Key, c.Fingerprint, c.CreatedAt)
+}
+
+// Complete implements Store.
+func (p *Postgres) Complete(ctx context.Context, claim *Claim, status int, contentType string, body []byte) error {
+	return held(p.db.WithContext(ctx).Model(&Record{}), claim).Updates(map[string]any{
+		"status":       status,
+		"content_type": contentType,
+		"body":         body,
+		"expires_at":   gorm.Expr("created_at + make_interval(secs => ?)", p.ttl.Seconds()),
+	}).Error
+}
+
+// Release implements Store.
+func (p *Postgres) Release(ctx context.Context, claim *Claim) error {
+	return held(p.db.WithContext(ctx), claim).Delete(&Record{}).Error
+}
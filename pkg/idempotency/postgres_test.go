@@ -0,0 +1,147 @@
+//go:build integration
+
+package idempotency_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/cirius-go/portfolio-server/internal/itest"
+	"github.com/cirius-go/portfolio-server/pkg/idempotency"
+)
+
+var env *itest.Env
+
+func TestMain(m *testing.M) {
+	env = itest.MustStart()
+	code := m.Run()
+	env.Close()
+	os.Exit(code)
+}
+
+func TestPostgres(t *testing.T) {
+	const lease = 100 * time.Millisecond
+	ctx := context.Background()
+
+	// claims holds the claims of the steps by name, the steps run in order on
+	// the same key.
+	type claims map[string]*idempotency.Claim
+	type step struct {
+		name string
+		run  func(s *idempotency.Postgres, c claims) (*idempotency.Record, error)
+		// wantHolder is nil when the key is claimed.
+		wantHolder *idempotency.Record
+	}
+	begin := func(fingerprint string) func(s *idempotency.Postgres, c claims) (*idempotency.Record, error) {
+		return func(s *idempotency.Postgres, c claims) (*idempotency.Record, error) {
+			claim, rec, err := s.Begin(ctx, "k", fingerprint)
+			if claim != nil {
+				c[fingerprint] = claim
+			}
+			return rec, err
+		}
+	}
+	complete := func(fingerprint string) func(s *idempotency.Postgres, c claims) (*idempotency.Record, error) {
+		return func(s *idempotency.Postgres, c claims) (*idempotency.Record, error) {
+			return nil, s.Complete(ctx, c[fingerprint], http.StatusCreated, "application/json", []byte(`{}`))
+		}
+	}
+	release := func(fingerprint string) func(s *idempotency.Postgres, c claims) (*idempotency.Record, error) {
+		return func(s *idempotency.Postgres, c claims) (*idempotency.Record, error) {
+			return nil, s.Release(ctx, c[fingerprint])
+		}
+	}
+	wait := func(d time.Duration) func(s *idempotency.Postgres, c claims) (*idempotency.Record, error) {
+		return func(*idempotency.Postgres, claims) (*idempotency.Record, error) {
+			time.Sleep(d)
+			return nil, nil
+		}
+	}
+
+	tests := []struct {
+		name  string
+		ttl   time.Duration
+		steps []step
+	}{
+		{
+			name: "completed key is replayed",
+			ttl:  time.Hour,
+			steps: []step{
+				{name: "claim", run: begin("a")},
+				{name: "in progress", run: begin("a"), wantHolder: &idempotency.Record{Fingerprint: "a"}},
+				{name: "complete", run: complete("a")},
+				// the recorded response outlives the lease.
+				{name: "wait past the lease", run: wait(2 * lease)},
+				{name: "replay", run: begin("a"), wantHolder: &idempotency.Record{Fingerprint: "a", Status: http.StatusCreated}},
+				{name: "release of a completed key", run: release("a")},
+				{name: "still replayed", run: begin("a"), wantHolder: &idempotency.Record{Fingerprint: "a", Status: http.StatusCreated}},
+			},
+		},
+		{
+			name: "released key is claimed again",
+			ttl:  time.Hour,
+			steps: []step{
+				{name: "claim", run: begin("a")},
+				{name: "release", run: release("a")},
+				{name: "claim again", run: begin("b")},
+			},
+		},
+		{
+			name: "abandoned key is taken over after the lease",
+			ttl:  time.Hour,
+			steps: []step{
+				{name: "claim", run: begin("a")},
+				{name: "wait past the lease", run: wait(2 * lease)},
+				{name: "take over", run: begin("b")},
+				{name: "in progress", run: begin("b"), wantHolder: &idempotency.Record{Fingerprint: "b"}},
+			},
+		},
+		{
+			name: "stale holder cannot settle the key taken over",
+			ttl:  time.Hour,
+			steps: []step{
+				{name: "claim", run: begin("a")},
+				{name: "wait past the lease", run: wait(2 * lease)},
+				{name: "take over", run: begin("b")},
+				{name: "stale complete", run: complete("a")},
+				{name: "stale release", run: release("a")},
+				{name: "still in progress", run: begin("b"), wantHolder: &idempotency.Record{Fingerprint: "b"}},
+				{name: "complete", run: complete("b")},
+				{name: "replay", run: begin("b"), wantHolder: &idempotency.Record{Fingerprint: "b", Status: http.StatusCreated}},
+			},
+		},
+		{
+			name: "expired response is forgotten",
+			ttl:  lease,
+			steps: []step{
+				{name: "claim", run: begin("a")},
+				{name: "complete", run: complete("a")},
+				{name: "wait past the ttl", run: wait(2 * lease)},
+				{name: "claim again", run: begin("b")},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				s = idempotency.NewPostgres(env.Tx(t), idempotency.Config{TTL: tt.ttl, Lease: lease})
+				c = claims{}
+			)
+			for _, st := range tt.steps {
+				got, err := st.run(s, c)
+				if err != nil {
+					t.Fatalf("%s: error = %v", st.name, err)
+				}
+				if (got == nil) != (st.wantHolder == nil) {
+					t.Fatalf("%s: holder = %+v, want %+v", st.name, got, st.wantHolder)
+				}
+				if got != nil && (got.Fingerprint != st.wantHolder.Fingerprint || got.Status != st.wantHolder.Status) {
+					t.Errorf("%s: holder = %+v, want %+v", st.name, got, st.wantHolder)
+				}
+			}
+		})
+	}
+}
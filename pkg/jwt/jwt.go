@@ -1,6 +1,8 @@
 package jwt
 
 import (
+	"time"
+
 	"github.com/golang-jwt/jwt/v5"
 
 	"github.com/cirius-go/portfolio-server/pkg/errors"
@@ -10,9 +12,24 @@ var (
 	ErrInvalidToken = errors.NewUnauthorized(nil, "invalid token")
 )
 
+// Aliases of the signing methods.
+var (
+	HS256 = jwt.SigningMethodHS256
+	HS384 = jwt.SigningMethodHS384
+	HS512 = jwt.SigningMethodHS512
+)
+
+// RegisteredClaims is an alias of jwt.RegisteredClaims.
+type RegisteredClaims = jwt.RegisteredClaims
+
+// Claims are the claims of a token.
+type Claims = jwt.Claims
+
 type Config struct {
-	alg    *jwt.SigningMethodHMAC
-	secret []byte
+	alg      *jwt.SigningMethodHMAC
+	secret   []byte
+	ttl      time.Duration
+	audience string
 }
 
 func C() *Config {
@@ -29,6 +46,19 @@ func (c *Config) Secret(secret []byte) *Config {
 	return c
 }
 
+// TTL sets the lifetime of the tokens created by NewClaims.
+func (c *Config) TTL(ttl time.Duration) *Config {
+	c.ttl = ttl
+	return c
+}
+
+// Audience sets the audience of the tokens. Tokens with another audience are
+// rejected by ParseToken.
+func (c *Config) Audience(aud string) *Config {
+	c.audience = aud
+	return c
+}
+
 // JWT represents the jwt service.
 type JWT struct {
 	cfg *Config
@@ -49,6 +79,28 @@ func NewJWTWithConfig(c *Config) *JWT {
 	}
 }
 
+// TTL returns the configured lifetime of the tokens.
+func (s *JWT) TTL() time.Duration {
+	return s.cfg.ttl
+}
+
+// NewClaims creates the registered claims for the subject with the configured
+// audience and expiration.
+func (s *JWT) NewClaims(subject string) RegisteredClaims {
+	now := time.Now()
+	c := RegisteredClaims{
+		Subject:  subject,
+		IssuedAt: jwt.NewNumericDate(now),
+	}
+	if s.cfg.audience != "" {
+		c.Audience = jwt.ClaimStrings{s.cfg.audience}
+	}
+	if s.cfg.ttl > 0 {
+		c.ExpiresAt = jwt.NewNumericDate(now.Add(s.cfg.ttl))
+	}
+	return c
+}
+
 // NewToken generates a new JWT token.
 func (s *JWT) NewToken(payload jwt.Claims) (string, error) {
 	token := jwt.NewWithClaims(s.cfg.alg, payload)
@@ -57,15 +109,20 @@ func (s *JWT) NewToken(payload jwt.Claims) (string, error) {
 
 // ParseToken parses a token and returns the claims.
 func (s *JWT) ParseToken(token string, customClaims jwt.Claims) error {
+	opts := []jwt.ParserOption{}
+	if s.cfg.audience != "" {
+		opts = append(opts, jwt.WithAudience(s.cfg.audience))
+	}
+
 	tk, err := jwt.ParseWithClaims(token, customClaims, func(t *jwt.Token) (any, error) {
 		if m, ok := t.Method.(*jwt.SigningMethodHMAC); !ok || m != s.cfg.alg {
 			return nil, ErrInvalidToken
 		}
 
 		return s.cfg.secret, nil
-	})
+	}, opts...)
 	if err != nil {
-		return err
+		return ErrInvalidToken.WithInternal(err)
 	}
 	if !tk.Valid {
 		return ErrInvalidToken
@@ -0,0 +1,161 @@
+// Package lifecycle runs the background components of a process and stops
+// them, along with its resources, when the process shuts down.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// defaultTimeout is the shutdown timeout when none is given.
+const defaultTimeout = 15 * time.Second
+
+type closer struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Manager runs the components of a process until it is interrupted or one of
+// them fails. It then cancels the components, waits for them to return and
+// stops the registered closers in the reverse order of their registration,
+// all within the shutdown timeout. The closers have their own part of it, so
+// that a component overrunning its budget does not leave them without time.
+type Manager struct {
+	timeout      time.Duration
+	closeTimeout time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	closers []closer
+	failed  chan error
+	once    sync.Once
+}
+
+// New creates a manager stopping everything within timeout, 15 seconds when
+// zero. A fifth of it is left to the closers.
+func New(timeout time.Duration) *Manager {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		timeout:      timeout,
+		closeTimeout: timeout / 5,
+		ctx:          ctx,
+		cancel:       cancel,
+		failed:       make(chan error, 1),
+	}
+}
+
+// WithCloseTimeout sets the part of the shutdown timeout left to the closers,
+// it is ignored unless below the shutdown timeout.
+func (m *Manager) WithCloseTimeout(d time.Duration) *Manager {
+	if d > 0 && d < m.timeout {
+		m.closeTimeout = d
+	}
+	return m
+}
+
+// Add registers the closer of a resource, it is called with the shutdown
+// context.
+func (m *Manager) Add(name string, fn func(ctx context.Context) error) *Manager {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closers = append(m.closers, closer{name: name, fn: fn})
+	return m
+}
+
+// AddCloser registers the closer of a resource which takes no context.
+func (m *Manager) AddCloser(name string, fn func() error) *Manager {
+	return m.Add(name, func(context.Context) error { return fn() })
+}
+
+// Go runs the component in a goroutine until its context is cancelled by the
+// shutdown. The process is shut down when it fails.
+func (m *Manager) Go(name string, fn func(ctx context.Context) error) {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		err := fn(m.ctx)
+		if err == nil || m.ctx.Err() != nil {
+			return
+		}
+		slog.Error("component failed", "component", name, "error", err)
+		select {
+		case m.failed <- err:
+		default:
+		}
+	}()
+}
+
+// Run waits until the process is interrupted or terminated, or until a
+// component fails, then shuts down. It returns the failure of the component
+// joined with the errors of the shutdown.
+func (m *Manager) Run() error {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sig)
+
+	var err error
+	select {
+	case s := <-sig:
+		slog.Info("shutting down", "signal", s.String())
+	case err = <-m.failed:
+		slog.Info("shutting down after a failure")
+	}
+	return errors.Join(err, m.Shutdown())
+}
+
+// Shutdown cancels the components, waits for them to return and stops the
+// closers in reverse order. Only the first call has an effect.
+func (m *Manager) Shutdown() error {
+	var err error
+	m.once.Do(func() {
+		drain := m.timeout - m.closeTimeout
+		ctx, cancel := context.WithTimeout(context.Background(), drain)
+		defer cancel()
+
+		m.cancel()
+		done := make(chan struct{})
+		go func() {
+			m.wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			slog.Warn("components still running after the shutdown timeout", "timeout", drain)
+		}
+
+		cctx, ccancel := context.WithTimeout(context.Background(), m.closeTimeout)
+		defer ccancel()
+
+		m.mu.Lock()
+		closers := m.closers
+		m.mu.Unlock()
+
+		var errs []error
+		for i := len(closers) - 1; i >= 0; i-- {
+			c := closers[i]
+			start := time.Now()
+			if cerr := c.fn(cctx); cerr != nil {
+				slog.Error("failed to stop", "component", c.name, "error", cerr)
+				errs = append(errs, cerr)
+				continue
+			}
+			slog.Info("stopped", "component", c.name, "elapsed", time.Since(start))
+		}
+		err = errors.Join(errs...)
+	})
+	return err
+}
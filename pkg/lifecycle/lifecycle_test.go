@@ -0,0 +1,41 @@
+package lifecycle
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestShutdownCloseBudget(t *testing.T) {
+	const (
+		timeout      = 200 * time.Millisecond
+		closeTimeout = 100 * time.Millisecond
+	)
+	m := New(timeout).WithCloseTimeout(closeTimeout)
+
+	// the component ignores its cancellation and overruns the drain.
+	block := make(chan struct{})
+	defer close(block)
+	m.Go("stuck", func(context.Context) error {
+		<-block
+		return nil
+	})
+
+	var left time.Duration
+	m.Add("db", func(ctx context.Context) error {
+		deadline, _ := ctx.Deadline()
+		left = time.Until(deadline)
+		return nil
+	})
+
+	start := time.Now()
+	if err := m.Shutdown(); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > timeout+50*time.Millisecond {
+		t.Errorf("Shutdown() took %s, want at most %s", elapsed, timeout)
+	}
+	if left < closeTimeout-20*time.Millisecond {
+		t.Errorf("closer had %s left, want its budget of %s", left, closeTimeout)
+	}
+}
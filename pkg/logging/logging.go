@@ -0,0 +1,77 @@
+// Package logging sets up the slog logger of the server and carries the
+// request-scoped loggers in contexts.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// Format is the output format of the logs.
+// ENUM(json,pretty)
+//
+//go:generate go-enum --marshal --names --values
+type Format string
+
+// Config contains the logging configuration.
+type Config struct {
+	Level  slog.Level `envconfig:"LEVEL"`
+	Format Format     `envconfig:"FORMAT" validate:"enum"` // pretty is meant for terminals.
+}
+
+// New creates the logger writing to w.
+func New(cfg Config, w io.Writer) *slog.Logger {
+	return newLogger(cfg.Format, w, cfg.Level)
+}
+
+func newLogger(format Format, w io.Writer, level slog.Leveler) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level}
+	if format == FormatJson {
+		return slog.New(slog.NewJSONHandler(w, opts))
+	}
+	return slog.New(newPrettyHandler(w, opts))
+}
+
+// level is the level of the default logger, it can be changed at runtime.
+var level slog.LevelVar
+
+// Setup creates the logger writing to stdout and makes it the default one.
+// Its level can be changed afterwards with SetLevel.
+func Setup(cfg Config) *slog.Logger {
+	level.Set(cfg.Level)
+	l := newLogger(cfg.Format, os.Stdout, &level)
+	slog.SetDefault(l)
+	return l
+}
+
+// Level returns the level of the default logger.
+func Level() slog.Level {
+	return level.Level()
+}
+
+// SetLevel changes the level of the default logger.
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+type loggerKey struct{}
+
+// WithContext returns a copy of ctx carrying the logger.
+func WithContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext returns the logger carried by ctx, or the default one.
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
+
+// With returns a copy of ctx whose logger has the attributes.
+func With(ctx context.Context, args ...any) context.Context {
+	return WithContext(ctx, FromContext(ctx).With(args...))
+}
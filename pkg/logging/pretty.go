@@ -0,0 +1,87 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+var levelColors = map[slog.Level]string{
+	slog.LevelDebug: "\033[90m",
+	slog.LevelInfo:  "\033[36m",
+	slog.LevelWarn:  "\033[33m",
+	slog.LevelError: "\033[31m",
+}
+
+// prettyHandler writes a short time, the colored level and the message
+// before the attributes, which are formatted by a text handler.
+type prettyHandler struct {
+	attrs slog.Handler
+
+	mu  *sync.Mutex
+	buf *bytes.Buffer
+	w   io.Writer
+}
+
+func newPrettyHandler(w io.Writer, opts *slog.HandlerOptions) *prettyHandler {
+	buf := &bytes.Buffer{}
+	return &prettyHandler{
+		attrs: slog.NewTextHandler(buf, &slog.HandlerOptions{
+			Level:       opts.Level,
+			ReplaceAttr: dropBuiltins,
+		}),
+		mu:  &sync.Mutex{},
+		buf: buf,
+		w:   w,
+	}
+}
+
+// dropBuiltins removes the time, level and message, written by the pretty
+// handler itself.
+func dropBuiltins(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == slog.LevelKey || a.Key == slog.MessageKey) {
+		return slog.Attr{}
+	}
+	return a
+}
+
+// Enabled implements slog.Handler.
+func (h *prettyHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.attrs.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+func (h *prettyHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.buf.Reset()
+	if err := h.attrs.Handle(ctx, r); err != nil {
+		return err
+	}
+
+	color := levelColors[r.Level]
+	if color == "" {
+		color = levelColors[slog.LevelError]
+	}
+	_, err := fmt.Fprintf(h.w, "%s %s%-5s\033[0m %s %s\n",
+		r.Time.Format("15:04:05.000"), color, r.Level, r.Message, bytes.TrimSpace(h.buf.Bytes()))
+	return err
+}
+
+// WithAttrs implements slog.Handler.
+func (h *prettyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.attrs = h.attrs.WithAttrs(attrs)
+	return &c
+}
+
+// WithGroup implements slog.Handler.
+func (h *prettyHandler) WithGroup(name string) slog.Handler {
+	c := *h
+	c.attrs = h.attrs.WithGroup(name)
+	return &c
+}
@@ -0,0 +1,53 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// File writes messages as .eml files into a directory, or to a writer when no
+// directory is configured. It is meant for local development.
+type File struct {
+	dir string
+	w   io.Writer
+	mu  sync.Mutex
+}
+
+// NewFile creates a new file mailer. An empty dir prints messages to stdout.
+func NewFile(dir string) (*File, error) {
+	if dir == "" {
+		return NewWriter(os.Stdout), nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &File{dir: dir}, nil
+}
+
+// NewWriter creates a file mailer which writes messages to w.
+func NewWriter(w io.Writer) *File {
+	return &File{w: w}
+}
+
+// Send implements Mailer.
+func (f *File) Send(ctx context.Context, msg *Message) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.dir == "" {
+		_, err := fmt.Fprintf(f.w, "⇨ mail sent\n%s\n", msg.Bytes())
+		return err
+	}
+
+	name := fmt.Sprintf("%s.eml", time.Now().Format("20060102T150405.000000000"))
+	return os.WriteFile(filepath.Join(f.dir, name), msg.Bytes(), 0o644)
+}
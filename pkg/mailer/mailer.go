@@ -0,0 +1,180 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// Driver represents the mailer implementation.
+// ENUM(smtp,ses,file,smtpstub)
+//
+//go:generate go-enum --marshal --names --values
+type Driver string
+
+// Config contains the mailer configuration.
+type Config struct {
+	Driver  Driver     `envconfig:"DRIVER" validate:"enum"`
+	From    string     `envconfig:"FROM" validate:"required"`
+	SMTP    SMTPConfig `envconfig:"SMTP"`
+	FileDir string     `envconfig:"FILE_DIR"` // empty prints messages to stdout.
+}
+
+// Message represents an email message.
+type Message struct {
+	From    string
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer sends email messages.
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// New creates the mailer selected by cfg.Driver. The aws config is only used
+// by the ses driver.
+//
+// When the driver is smtpstub, an in-process SMTP server is started and the
+// returned mailer must be closed to stop it.
+func New(cfg Config, awsCfg aws.Config) (Mailer, error) {
+	var (
+		m   Mailer
+		err error
+	)
+
+	switch cfg.Driver {
+	case DriverSmtp:
+		m = NewSMTP(&cfg.SMTP)
+	case DriverSes:
+		m = NewSES(awsCfg)
+	case DriverFile:
+		m, err = NewFile(cfg.FileDir)
+	case DriverSmtpstub:
+		m, err = NewStub()
+	default:
+		return nil, fmt.Errorf("unsupported mailer driver %q", cfg.Driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return WithDefaultFrom(m, cfg.From), nil
+}
+
+// WithDefaultFrom wraps the mailer so that messages without sender are sent
+// from the given address.
+func WithDefaultFrom(m Mailer, from string) Mailer {
+	if from == "" {
+		return m
+	}
+	return &defaultFrom{Mailer: m, from: from}
+}
+
+type defaultFrom struct {
+	Mailer
+	from string
+}
+
+func (d *defaultFrom) Send(ctx context.Context, msg *Message) error {
+	if msg.From == "" {
+		clone := *msg
+		clone.From = d.from
+		msg = &clone
+	}
+	return d.Mailer.Send(ctx, msg)
+}
+
+// Close closes the underlying mailer if it holds any resource.
+func (d *defaultFrom) Close() error {
+	if c, ok := d.Mailer.(interface{ Close() error }); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Validate checks the required fields and addresses of the message.
+func (m *Message) Validate() error {
+	if _, err := mail.ParseAddress(m.From); err != nil {
+		return fmt.Errorf("invalid sender %q: %w", m.From, err)
+	}
+	if len(m.To) == 0 {
+		return fmt.Errorf("message has no recipient")
+	}
+	for _, to := range m.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return fmt.Errorf("invalid recipient %q: %w", to, err)
+		}
+	}
+	if m.Text == "" && m.HTML == "" {
+		return fmt.Errorf("message has no body")
+	}
+	return nil
+}
+
+// Bytes encodes the message in RFC 5322 format. Messages with both text and
+// HTML bodies are sent as multipart/alternative.
+func (m *Message) Bytes() []byte {
+	buf := &bytes.Buffer{}
+	header := func(k, v string) {
+		fmt.Fprintf(buf, "%s: %s\r\n", k, v)
+	}
+
+	header("From", m.From)
+	header("To", strings.Join(m.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+
+	if m.Text == "" || m.HTML == "" {
+		contentType, body := "text/plain; charset=utf-8", m.Text
+		if m.HTML != "" {
+			contentType, body = "text/html; charset=utf-8", m.HTML
+		}
+		header("Content-Type", contentType)
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		writeQuotedPrintable(buf, body)
+		return buf.Bytes()
+	}
+
+	boundary := newBoundary()
+	header("Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", boundary))
+	buf.WriteString("\r\n")
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", m.Text},
+		{"text/html; charset=utf-8", m.HTML},
+	} {
+		fmt.Fprintf(buf, "--%s\r\n", boundary)
+		header("Content-Type", part.contentType)
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		writeQuotedPrintable(buf, part.body)
+		buf.WriteString("\r\n")
+	}
+	fmt.Fprintf(buf, "--%s--\r\n", boundary)
+	return buf.Bytes()
+}
+
+func writeQuotedPrintable(buf *bytes.Buffer, body string) {
+	w := quotedprintable.NewWriter(buf)
+	_, _ = w.Write([]byte(body))
+	_ = w.Close()
+}
+
+func newBoundary() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+)
+
+// SES sends messages through AWS Simple Email Service.
+type SES struct {
+	client *sesv2.Client
+}
+
+// NewSES creates a new SES mailer.
+func NewSES(cfg aws.Config) *SES {
+	return &SES{client: sesv2.NewFromConfig(cfg)}
+}
+
+// Send implements Mailer.
+func (s *SES) Send(ctx context.Context, msg *Message) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+
+	body := &types.Body{}
+	if msg.Text != "" {
+		body.Text = &types.Content{Data: aws.String(msg.Text), Charset: aws.String("UTF-8")}
+	}
+	if msg.HTML != "" {
+		body.Html = &types.Content{Data: aws.String(msg.HTML), Charset: aws.String("UTF-8")}
+	}
+
+	_, err := s.client.SendEmail(ctx, &sesv2.SendEmailInput{
+		FromEmailAddress: aws.String(msg.From),
+		Destination:      &types.Destination{ToAddresses: msg.To},
+		Content: &types.EmailContent{
+			Simple: &types.Message{
+				Subject: &types.Content{Data: aws.String(msg.Subject), Charset: aws.String("UTF-8")},
+				Body:    body,
+			},
+		},
+	})
+	return err
+}
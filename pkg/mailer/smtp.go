@@ -0,0 +1,56 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+)
+
+// SMTPConfig contains the config of an SMTP mailer.
+type SMTPConfig struct {
+	Host     string `envconfig:"HOST"`
+	Port     int    `envconfig:"PORT"`
+	Username string `envconfig:"USERNAME"`
+	Password string `envconfig:"PASSWORD"`
+}
+
+// SMTP sends messages through an SMTP server. STARTTLS is used when the
+// server supports it.
+type SMTP struct {
+	cfg *SMTPConfig
+}
+
+// NewSMTP creates a new SMTP mailer.
+func NewSMTP(cfg *SMTPConfig) *SMTP {
+	return &SMTP{cfg: cfg}
+}
+
+// Send implements Mailer.
+func (s *SMTP) Send(ctx context.Context, msg *Message) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- smtp.SendMail(addr, auth, msg.From, msg.To, msg.Bytes())
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			return fmt.Errorf("smtp: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,177 @@
+package smtpstub
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message is a message received by the server.
+type Message struct {
+	From       string
+	To         []string
+	Data       []byte
+	ReceivedAt time.Time
+}
+
+// Server is a minimal in-process SMTP server which accepts every message and
+// keeps it in memory. It supports neither TLS nor authentication and is only
+// meant to exercise mail flows without network access.
+type Server struct {
+	ln        net.Listener
+	onMessage func(*Message)
+
+	mu   sync.Mutex
+	msgs []*Message
+	wg   sync.WaitGroup
+}
+
+// Start starts a server listening on addr, e.g. "127.0.0.1:0". onMessage is
+// called for each received message if not nil.
+func Start(addr string, onMessage func(*Message)) (*Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{ln: ln, onMessage: onMessage}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Host returns the host the server listens on.
+func (s *Server) Host() string {
+	return s.ln.Addr().(*net.TCPAddr).IP.String()
+}
+
+// Port returns the port the server listens on.
+func (s *Server) Port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+// Messages returns the received messages.
+func (s *Server) Messages() []*Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Message(nil), s.msgs...)
+}
+
+// Reset removes the received messages.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.msgs = nil
+}
+
+// Close stops the server and waits for the open sessions to end.
+func (s *Server) Close() error {
+	err := s.ln.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			_ = conn.SetDeadline(time.Now().Add(time.Minute))
+			s.handle(textproto.NewConn(conn))
+		}()
+	}
+}
+
+func (s *Server) handle(c *textproto.Conn) {
+	reply := func(code int, msg string) error {
+		return c.PrintfLine("%d %s", code, msg)
+	}
+
+	if reply(220, "smtpstub ESMTP ready") != nil {
+		return
+	}
+
+	msg := &Message{}
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			err = c.PrintfLine("250-smtpstub\r\n250 8BITMIME")
+		case "MAIL":
+			msg = &Message{From: parsePath(arg, "FROM:")}
+			err = reply(250, "OK")
+		case "RCPT":
+			msg.To = append(msg.To, parsePath(arg, "TO:"))
+			err = reply(250, "OK")
+		case "DATA":
+			if len(msg.To) == 0 {
+				err = reply(503, "need RCPT command")
+				break
+			}
+			if err = reply(354, "end data with <CR><LF>.<CR><LF>"); err != nil {
+				return
+			}
+			if msg.Data, err = c.ReadDotBytes(); err != nil {
+				return
+			}
+			msg.ReceivedAt = time.Now()
+			s.store(msg)
+			id := strconv.FormatInt(msg.ReceivedAt.UnixNano(), 36)
+			msg = &Message{}
+			err = reply(250, "OK: queued as "+id)
+		case "RSET":
+			msg = &Message{}
+			err = reply(250, "OK")
+		case "NOOP":
+			err = reply(250, "OK")
+		case "QUIT":
+			_ = reply(221, "bye")
+			return
+		default:
+			err = reply(502, "command not implemented")
+		}
+
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				_ = reply(451, "local error")
+			}
+			return
+		}
+	}
+}
+
+func (s *Server) store(msg *Message) {
+	s.mu.Lock()
+	s.msgs = append(s.msgs, msg)
+	s.mu.Unlock()
+
+	if s.onMessage != nil {
+		s.onMessage(msg)
+	}
+}
+
+// parsePath extracts the address from "FROM:<addr> params".
+func parsePath(arg, prefix string) string {
+	if len(arg) >= len(prefix) && strings.EqualFold(arg[:len(prefix)], prefix) {
+		arg = arg[len(prefix):]
+	}
+	arg, _, _ = strings.Cut(strings.TrimSpace(arg), " ")
+	return strings.Trim(arg, "<>")
+}
@@ -0,0 +1,42 @@
+package mailer
+
+import (
+	"bytes"
+	"log/slog"
+	"net/mail"
+
+	"github.com/cirius-go/portfolio-server/pkg/mailer/smtpstub"
+)
+
+// Stub is an SMTP mailer connected to an in-process smtpstub server. The
+// received messages are logged without their body, which can be inspected
+// through Server.
+type Stub struct {
+	*SMTP
+	Server *smtpstub.Server
+}
+
+// NewStub starts an smtpstub server on a random local port and returns a
+// mailer sending to it.
+func NewStub() (*Stub, error) {
+	srv, err := smtpstub.Start("127.0.0.1:0", func(m *smtpstub.Message) {
+		var subject string
+		if msg, err := mail.ReadMessage(bytes.NewReader(m.Data)); err == nil {
+			subject = msg.Header.Get("Subject")
+		}
+		slog.Info("smtpstub received mail", "from", m.From, "to", m.To, "subject", subject, "size", len(m.Data))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Stub{
+		SMTP:   NewSMTP(&SMTPConfig{Host: srv.Host(), Port: srv.Port()}),
+		Server: srv,
+	}, nil
+}
+
+// Close stops the smtpstub server.
+func (s *Stub) Close() error {
+	return s.Server.Close()
+}
@@ -0,0 +1,73 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"net/mail"
+	"testing"
+)
+
+func TestStub(t *testing.T) {
+	stub, err := NewStub()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stub.Close()
+
+	tests := []struct {
+		name    string
+		msg     *Message
+		wantErr bool
+	}{
+		{
+			name: "text",
+			msg:  &Message{From: "cms@example.com", To: []string{"a@example.com"}, Subject: "Hello", Text: "hi"},
+		},
+		{
+			name: "text and html",
+			msg:  &Message{From: "cms@example.com", To: []string{"a@example.com", "b@example.com"}, Subject: "Both", Text: "hi", HTML: "<p>hi</p>"},
+		},
+		{
+			name:    "no recipient",
+			msg:     &Message{From: "cms@example.com", Subject: "None", Text: "hi"},
+			wantErr: true,
+		},
+		{
+			name:    "no body",
+			msg:     &Message{From: "cms@example.com", To: []string{"a@example.com"}, Subject: "Empty"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub.Server.Reset()
+
+			err := stub.Send(context.Background(), tt.msg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			msgs := stub.Server.Messages()
+			if tt.wantErr {
+				if len(msgs) != 0 {
+					t.Fatalf("received %d messages, want none", len(msgs))
+				}
+				return
+			}
+			if len(msgs) != 1 {
+				t.Fatalf("received %d messages, want 1", len(msgs))
+			}
+
+			got := msgs[0]
+			if len(got.To) != len(tt.msg.To) {
+				t.Errorf("recipients = %v, want %v", got.To, tt.msg.To)
+			}
+			m, err := mail.ReadMessage(bytes.NewReader(got.Data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := m.Header.Get("Subject"); s != tt.msg.Subject {
+				t.Errorf("subject = %q, want %q", s, tt.msg.Subject)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package metrics
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// EMF writes the gathered metrics as CloudWatch embedded metric format log
+// lines, one per metric and label set. The labels become the dimensions.
+//
+// Counters, and the count and sum of summaries, are written as their
+// increase since the previous flush so that CloudWatch can sum them.
+// Histograms are written as the distribution of their observations since the
+// previous flush, so that CloudWatch computes their percentiles. Gauges are
+// written when they change, and at least every gauge interval.
+type EMF struct {
+	gatherer      prometheus.Gatherer
+	namespace     string
+	w             io.Writer
+	gaugeInterval time.Duration
+
+	mu      sync.Mutex
+	last    map[string]float64
+	written map[string]time.Time
+}
+
+// NewEMF creates the writer of the metrics of the gatherer.
+func NewEMF(g prometheus.Gatherer, namespace string, w io.Writer) *EMF {
+	return &EMF{
+		gatherer:      g,
+		namespace:     namespace,
+		w:             w,
+		gaugeInterval: time.Minute,
+		last:          map[string]float64{},
+		written:       map[string]time.Time{},
+	}
+}
+
+// WithGaugeInterval sets how often the gauges are written when they do not
+// change, a minute by default.
+func (e *EMF) WithGaugeInterval(d time.Duration) *EMF {
+	e.gaugeInterval = d
+	return e
+}
+
+type emfMetric struct {
+	Name string `json:"Name"`
+	Unit string `json:"Unit,omitempty"`
+}
+
+type emfDirective struct {
+	Namespace  string      `json:"Namespace"`
+	Dimensions [][]string  `json:"Dimensions"`
+	Metrics    []emfMetric `json:"Metrics"`
+}
+
+type emfMetadata struct {
+	Timestamp         int64          `json:"Timestamp"`
+	CloudWatchMetrics []emfDirective `json:"CloudWatchMetrics"`
+}
+
+// emfDistribution is a metric value made of several observations, each of
+// the Values being observed the matching number of Counts.
+type emfDistribution struct {
+	Values []float64 `json:"Values"`
+	Counts []float64 `json:"Counts"`
+}
+
+// Flush writes the metrics which changed since the previous flush.
+func (e *EMF) Flush() error {
+	mfs, err := e.gatherer.Gather()
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	for _, mf := range mfs {
+		for _, m := range mf.GetMetric() {
+			values, changed := e.values(mf, m, now)
+			if !changed {
+				continue
+			}
+
+			line := map[string]any{}
+			dims := make([]string, 0, len(m.GetLabel()))
+			for _, l := range m.GetLabel() {
+				dims = append(dims, l.GetName())
+				line[l.GetName()] = l.GetValue()
+			}
+
+			directive := emfDirective{Namespace: e.namespace, Dimensions: [][]string{dims}}
+			for _, v := range values {
+				directive.Metrics = append(directive.Metrics, emfMetric{Name: v.name, Unit: v.unit})
+				line[v.name] = v.value
+			}
+			line["_aws"] = emfMetadata{Timestamp: now.UnixMilli(), CloudWatchMetrics: []emfDirective{directive}}
+
+			b, err := json.Marshal(line)
+			if err != nil {
+				return err
+			}
+			if _, err := e.w.Write(append(b, '\n')); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type emfValue struct {
+	name  string
+	unit  string
+	value any
+}
+
+// values returns the values of the metric to write, and whether any of them
+// changed since the previous flush.
+func (e *EMF) values(mf *dto.MetricFamily, m *dto.Metric, now time.Time) ([]emfValue, bool) {
+	name := mf.GetName()
+	key := name + labelKey(m)
+
+	switch mf.GetType() {
+	case dto.MetricType_COUNTER:
+		d := e.delta(key, m.GetCounter().GetValue())
+		return []emfValue{{name, "Count", d}}, d != 0
+	case dto.MetricType_GAUGE:
+		v := m.GetGauge().GetValue()
+		return []emfValue{{name, "", v}}, e.gaugeChanged(key, v, now)
+	case dto.MetricType_UNTYPED:
+		v := m.GetUntyped().GetValue()
+		return []emfValue{{name, "", v}}, e.gaugeChanged(key, v, now)
+	case dto.MetricType_HISTOGRAM:
+		d, ok := e.distribution(key, m.GetHistogram())
+		return []emfValue{{name, unitOf(name), d}}, ok
+	case dto.MetricType_SUMMARY:
+		s := m.GetSummary()
+		return e.countSum(key, name, float64(s.GetSampleCount()), s.GetSampleSum())
+	}
+	return nil, false
+}
+
+func (e *EMF) countSum(key, name string, count, sum float64) ([]emfValue, bool) {
+	var (
+		dc = e.delta(key+"_count", count)
+		ds = e.delta(key+"_sum", sum)
+	)
+	return []emfValue{
+		{name + "_count", "Count", dc},
+		{name + "_sum", unitOf(name), ds},
+	}, dc != 0
+}
+
+// gaugeChanged reports whether the gauge is to be written: it changed since
+// it was last written, or was written more than the gauge interval ago.
+func (e *EMF) gaugeChanged(key string, v float64, now time.Time) bool {
+	prev, ok := e.last[key]
+	if ok && prev == v && now.Sub(e.written[key]) < e.gaugeInterval {
+		return false
+	}
+	e.last[key] = v
+	e.written[key] = now
+	return true
+}
+
+// distribution returns the observations of the histogram since the previous
+// flush, and whether there are any. An observation is counted at the middle
+// of its bucket, or at the upper bound of the first bucket when it is not
+// positive, and at the last bound when it is above all of them.
+func (e *EMF) distribution(key string, h *dto.Histogram) (*emfDistribution, bool) {
+	var (
+		d         = &emfDistribution{}
+		lower     float64
+		prevCount float64
+	)
+	add := func(v, cum float64) {
+		if c := cum - prevCount; c > 0 {
+			d.Values = append(d.Values, v)
+			d.Counts = append(d.Counts, c)
+		}
+		prevCount = cum
+	}
+
+	for i, b := range h.GetBucket() {
+		upper := b.GetUpperBound()
+		if math.IsInf(upper, 1) {
+			break
+		}
+		v := upper
+		if i > 0 || upper > 0 {
+			v = (lower + upper) / 2
+		}
+		add(v, e.delta(key+"|le="+strconv.FormatFloat(upper, 'g', -1, 64), float64(b.GetCumulativeCount())))
+		lower = upper
+	}
+	add(lower, e.delta(key+"_count", float64(h.GetSampleCount())))
+	return d, len(d.Values) > 0
+}
+
+// delta returns the increase of the cumulative value since the previous
+// flush, or the value itself after a reset.
+func (e *EMF) delta(key string, v float64) float64 {
+	prev, ok := e.last[key]
+	e.last[key] = v
+	if !ok || v < prev {
+		return v
+	}
+	return v - prev
+}
+
+func labelKey(m *dto.Metric) string {
+	var b strings.Builder
+	for _, l := range m.GetLabel() {
+		b.WriteString("|")
+		b.WriteString(l.GetName())
+		b.WriteString("=")
+		b.WriteString(l.GetValue())
+	}
+	return b.String()
+}
+
+func unitOf(name string) string {
+	switch {
+	case strings.HasSuffix(name, "_seconds"):
+		return "Seconds"
+	case strings.HasSuffix(name, "_bytes"):
+		return "Bytes"
+	}
+	return ""
+}
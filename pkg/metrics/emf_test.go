@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// flushLines flushes the writer and returns the decoded lines by metric name.
+func flushLines(t *testing.T, e *EMF, buf *bytes.Buffer) map[string]map[string]any {
+	t.Helper()
+
+	buf.Reset()
+	if err := e.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	lines := map[string]map[string]any{}
+	for _, l := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if l == "" {
+			continue
+		}
+		var line map[string]any
+		if err := json.Unmarshal([]byte(l), &line); err != nil {
+			t.Fatalf("invalid line %q: %v", l, err)
+		}
+		for k := range line {
+			if k != "_aws" && k != "route" {
+				lines[k] = line
+			}
+		}
+	}
+	return lines
+}
+
+func TestEMFHistogram(t *testing.T) {
+	var (
+		reg = prometheus.NewRegistry()
+		h   = prometheus.NewHistogram(prometheus.HistogramOpts{Name: "latency_seconds", Buckets: []float64{0.1, 0.5, 1}})
+		buf bytes.Buffer
+		e   = NewEMF(reg, "test", &buf)
+	)
+	reg.MustRegister(h)
+
+	tests := []struct {
+		name    string
+		observe []float64
+		want    *emfDistribution
+	}{
+		{name: "nothing observed"},
+		{
+			name:    "observations",
+			observe: []float64{0.05, 0.07, 0.3, 2},
+			want:    &emfDistribution{Values: []float64{0.05, 0.3, 1}, Counts: []float64{2, 1, 1}},
+		},
+		{
+			name:    "observations since the previous flush",
+			observe: []float64{0.7},
+			want:    &emfDistribution{Values: []float64{0.75}, Counts: []float64{1}},
+		},
+		{name: "nothing new"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, v := range tt.observe {
+				h.Observe(v)
+			}
+			line, ok := flushLines(t, e, &buf)["latency_seconds"]
+			if (tt.want != nil) != ok {
+				t.Fatalf("written = %v, want %v", ok, tt.want != nil)
+			}
+			if !ok {
+				return
+			}
+
+			b, _ := json.Marshal(line["latency_seconds"])
+			got := &emfDistribution{}
+			if err := json.Unmarshal(b, got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("distribution = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEMFGauge(t *testing.T) {
+	var (
+		reg = prometheus.NewRegistry()
+		g   = prometheus.NewGauge(prometheus.GaugeOpts{Name: "connections"})
+		buf bytes.Buffer
+		e   = NewEMF(reg, "test", &buf).WithGaugeInterval(50 * time.Millisecond)
+	)
+	reg.MustRegister(g)
+
+	tests := []struct {
+		name        string
+		update      func()
+		wantWritten bool
+	}{
+		{name: "first flush", wantWritten: true},
+		{name: "unchanged", wantWritten: false},
+		{name: "changed", update: func() { g.Set(3) }, wantWritten: true},
+		{name: "unchanged after the interval", update: func() { time.Sleep(60 * time.Millisecond) }, wantWritten: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.update != nil {
+				tt.update()
+			}
+			if _, ok := flushLines(t, e, &buf)["connections"]; ok != tt.wantWritten {
+				t.Errorf("written = %v, want %v", ok, tt.wantWritten)
+			}
+		})
+	}
+}
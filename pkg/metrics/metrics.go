@@ -0,0 +1,68 @@
+// Package metrics serves the Prometheus metrics of the server, or writes them
+// as CloudWatch embedded metric format log lines where nothing scrapes them.
+package metrics
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Handler serves the metrics of the gatherer. When token is set, requests
+// must send it as a bearer token.
+func Handler(g prometheus.Gatherer, token string) http.Handler {
+	h := promhttp.HandlerFor(g, promhttp.HandlerOpts{})
+	if token == "" {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// Serve serves the metrics of the gatherer on /metrics at addr until ctx is
+// done.
+func Serve(ctx context.Context, addr string, g prometheus.Gatherer, token string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler(g, token))
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// NewRegistry creates a registry, with the Go runtime and process metrics
+// when runtime is set.
+func NewRegistry(runtime bool) *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	if runtime {
+		reg.MustRegister(
+			collectors.NewGoCollector(),
+			collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		)
+	}
+	return reg
+}
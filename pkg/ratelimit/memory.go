@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often the expired counters are removed.
+const sweepInterval = time.Minute
+
+// Memory is a store keeping the counters in the process, so that each
+// instance, such as each Lambda function instance, has its own limits.
+type Memory struct {
+	mu        sync.Mutex
+	counters  map[string]*memoryCounter
+	lastSweep time.Time
+}
+
+type memoryCounter struct {
+	count     int64
+	expiresAt time.Time
+}
+
+// NewMemory creates a new Memory store.
+func NewMemory() *Memory {
+	return &Memory{
+		counters:  make(map[string]*memoryCounter),
+		lastSweep: time.Now(),
+	}
+}
+
+// Incr implements Store.
+func (m *Memory) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(m.lastSweep) > sweepInterval {
+		m.sweep(now)
+	}
+
+	c, ok := m.counters[key]
+	if !ok || !now.Before(c.expiresAt) {
+		c = &memoryCounter{expiresAt: now.Add(ttl)}
+		m.counters[key] = c
+	}
+	c.count++
+	return c.count, nil
+}
+
+// Get implements Store.
+func (m *Memory) Get(ctx context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.counters[key]
+	if !ok || !time.Now().Before(c.expiresAt) {
+		return 0, nil
+	}
+	return c.count, nil
+}
+
+func (m *Memory) sweep(now time.Time) {
+	for k, c := range m.counters {
+		if !now.Before(c.expiresAt) {
+			delete(m.counters, k)
+		}
+	}
+	m.lastSweep = now
+}
@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/cirius-go/portfolio-server/pkg/db"
+)
+
+// Counter is a counter of the Postgres store.
+type Counter struct {
+	Key       string    `gorm:"primaryKey"`
+	Count     int64     `gorm:"not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+// TableName implements schema.Tabler.
+func (Counter) TableName() string {
+	return "rate_limit_counters"
+}
+
+// Postgres is a store keeping the counters in the database, shared by every
+// instance. The counters are read from the primary, and the expired ones are
+// deleted by the hits at most once per sweep interval.
+type Postgres struct {
+	db        *gorm.DB
+	lastSweep atomic.Int64
+}
+
+// NewPostgres creates a new Postgres store.
+func NewPostgres(db *gorm.DB) *Postgres {
+	p := &Postgres{db: db}
+	p.lastSweep.Store(time.Now().UnixNano())
+	return p
+}
+
+// Incr implements Store.
+func (p *Postgres) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	now := time.Now()
+	if last := p.lastSweep.Load(); now.UnixNano()-last > int64(sweepInterval) && p.lastSweep.CompareAndSwap(last, now.UnixNano()) {
+		if err := p.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&Counter{}).Error; err != nil {
+			return 0, err
+		}
+	}
+
+	var count int64
+	err := p.db.WithContext(ctx).Raw(`
+		INSERT INTO rate_limit_counters (key, count, expires_at) VALUES (@key, 1, @expires_at)
+		ON CONFLICT (key) DO UPDATE SET
+			count = CASE WHEN rate_limit_counters.expires_at <= @now THEN 1 ELSE rate_limit_counters.count + 1 END,
+			expires_at = CASE WHEN rate_limit_counters.expires_at <= @now THEN EXCLUDED.expires_at ELSE rate_limit_counters.expires_at END
+		RETURNING count`,
+		map[string]any{"key": key, "expires_at": now.Add(ttl), "now": now},
+	).Scan(&count).Error
+	return count, err
+}
+
+// Get implements Store.
+func (p *Postgres) Get(ctx context.Context, key string) (int64, error) {
+	var counters []Counter
+	err := p.db.WithContext(db.WithPrimary(ctx)).
+		Where("key = ? AND expires_at > ?", key, time.Now()).
+		Limit(1).
+		Find(&counters).Error
+	if err != nil || len(counters) == 0 {
+		return 0, err
+	}
+	return counters[0].Count, nil
+}
@@ -0,0 +1,39 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rate is a number of hits allowed per window, written as 10/1m.
+type Rate struct {
+	Limit  int
+	Window time.Duration
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (r Rate) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d/%s", r.Limit, r.Window)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (r *Rate) UnmarshalText(b []byte) error {
+	limit, window, ok := strings.Cut(string(b), "/")
+	if !ok {
+		return fmt.Errorf("invalid rate %q, expected limit/window such as 10/1m", b)
+	}
+
+	l, err := strconv.Atoi(limit)
+	if err != nil || l <= 0 {
+		return fmt.Errorf("invalid limit of rate %q", b)
+	}
+	w, err := time.ParseDuration(window)
+	if err != nil || w <= 0 {
+		return fmt.Errorf("invalid window of rate %q", b)
+	}
+
+	*r = Rate{Limit: l, Window: w}
+	return nil
+}
@@ -0,0 +1,143 @@
+// Package ratelimit counts the hits of keys within sliding windows.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/cirius-go/portfolio-server/pkg/cache"
+)
+
+// Driver represents the store of the counters.
+// ENUM(memory,postgres,redis)
+//
+//go:generate go-enum --marshal --names --values
+type Driver string
+
+// Config contains the rate limiter configuration.
+type Config struct {
+	Driver Driver            `envconfig:"DRIVER" validate:"enum"`
+	Prefix string            `envconfig:"PREFIX"` // prepended to every key.
+	Redis  cache.RedisConfig `envconfig:"REDIS"`
+	// Policies override the rates of the policies by name, e.g.
+	// cms-login:20/1m.
+	Policies map[string]Rate `envconfig:"POLICIES"`
+}
+
+// Store holds the counters of the hits. A counter expires ttl after its
+// first hit.
+type Store interface {
+	// Incr adds a hit to the counter of key and returns its count.
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	// Get returns the count of key, 0 when it has expired.
+	Get(ctx context.Context, key string) (int64, error)
+}
+
+// Limiter allows a number of hits per window. The window slides: the hits of
+// the previous fixed window are weighted by how much of it still overlaps
+// the sliding one, so that a burst at the edge of two windows is not allowed
+// twice the limit.
+type Limiter struct {
+	store     Store
+	prefix    string
+	now       func() time.Time
+	overrides atomic.Pointer[map[string]Rate]
+}
+
+// New creates the limiter with the store selected by cfg.Driver. The
+// postgres store keeps its counters in db.
+func New(cfg Config, db *gorm.DB) (*Limiter, error) {
+	var s Store
+	switch cfg.Driver {
+	case DriverMemory:
+		s = NewMemory()
+	case DriverPostgres:
+		s = NewPostgres(db)
+	case DriverRedis:
+		s = NewRedis(&cfg.Redis)
+	default:
+		return nil, fmt.Errorf("unsupported rate limit driver %q", cfg.Driver)
+	}
+	l := NewWithStore(s, cfg.Prefix)
+	l.SetOverrides(cfg.Policies)
+	return l, nil
+}
+
+// NewWithStore creates the limiter on top of the store.
+func NewWithStore(s Store, prefix string) *Limiter {
+	return &Limiter{
+		store:  s,
+		prefix: prefix,
+		now:    time.Now,
+	}
+}
+
+// SetOverrides replaces the rates overriding the ones of the policies, by
+// policy name.
+func (l *Limiter) SetOverrides(rates map[string]Rate) {
+	l.overrides.Store(&rates)
+}
+
+// Override returns the rate overriding the one of the policy, if any.
+func (l *Limiter) Override(policy string) (Rate, bool) {
+	rates := l.overrides.Load()
+	if rates == nil {
+		return Rate{}, false
+	}
+	r, ok := (*rates)[policy]
+	return r, ok
+}
+
+// Close closes the store if it holds any resource.
+func (l *Limiter) Close() error {
+	if l == nil {
+		return nil
+	}
+	if cl, ok := l.store.(interface{ Close() error }); ok {
+		return cl.Close()
+	}
+	return nil
+}
+
+// Result is the state of a key after a hit.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is when the current fixed window ends and the count decreases.
+	Reset time.Duration
+}
+
+// Allow records a hit of key and reports whether the key is within limit
+// hits per window.
+func (l *Limiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (*Result, error) {
+	var (
+		now     = l.now()
+		idx     = now.UnixNano() / int64(window)
+		elapsed = time.Duration(now.UnixNano() - idx*int64(window))
+		prefix  = l.prefix + key + ":"
+	)
+
+	curr, err := l.store.Incr(ctx, prefix+strconv.FormatInt(idx, 10), 2*window)
+	if err != nil {
+		return nil, err
+	}
+	prev, err := l.store.Get(ctx, prefix+strconv.FormatInt(idx-1, 10))
+	if err != nil {
+		return nil, err
+	}
+
+	count := float64(prev)*(1-float64(elapsed)/float64(window)) + float64(curr)
+	return &Result{
+		Allowed:   count <= float64(limit),
+		Limit:     limit,
+		Remaining: max(0, limit-int(math.Ceil(count))),
+		Reset:     window - elapsed,
+	}, nil
+}
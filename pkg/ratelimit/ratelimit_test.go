@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func stores(t *testing.T) map[string]func(t *testing.T) Store {
+	return map[string]func(t *testing.T) Store{
+		"memory": func(t *testing.T) Store {
+			return NewMemory()
+		},
+		"redis": func(t *testing.T) Store {
+			srv := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+			t.Cleanup(func() { client.Close() })
+			return NewRedisWithClient(client)
+		},
+	}
+}
+
+func TestLimiterAllow(t *testing.T) {
+	const (
+		limit  = 3
+		window = time.Minute
+	)
+	var (
+		ctx = context.Background()
+		t0  = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	)
+
+	// the hits are sent in order to the same key.
+	hits := []struct {
+		name          string
+		at            time.Duration
+		wantAllowed   bool
+		wantRemaining int
+		wantReset     time.Duration
+	}{
+		{name: "first hit", at: 0, wantAllowed: true, wantRemaining: 2, wantReset: window},
+		{name: "second hit", at: 10 * time.Second, wantAllowed: true, wantRemaining: 1, wantReset: 50 * time.Second},
+		{name: "last allowed hit", at: 20 * time.Second, wantAllowed: true, wantRemaining: 0, wantReset: 40 * time.Second},
+		{name: "over the limit", at: 30 * time.Second, wantAllowed: false, wantRemaining: 0, wantReset: 30 * time.Second},
+		// the 4 hits of the previous window weigh 2 halfway through the next.
+		{name: "previous window half over", at: window + 30*time.Second, wantAllowed: true, wantRemaining: 0, wantReset: 30 * time.Second},
+		{name: "previous window still counts", at: window + 31*time.Second, wantAllowed: false, wantRemaining: 0, wantReset: 29 * time.Second},
+		{name: "windows expired", at: 3 * window, wantAllowed: true, wantRemaining: 2, wantReset: window},
+	}
+
+	for name, newStore := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			var (
+				l   = NewWithStore(newStore(t), "test:")
+				now time.Time
+			)
+			l.now = func() time.Time { return now }
+
+			for _, h := range hits {
+				now = t0.Add(h.at)
+				res, err := l.Allow(ctx, "key", limit, window)
+				if err != nil {
+					t.Fatalf("%s: Allow() error = %v", h.name, err)
+				}
+				if res.Allowed != h.wantAllowed || res.Remaining != h.wantRemaining || res.Reset != h.wantReset {
+					t.Errorf("%s: Allow() = %+v, want allowed %v, remaining %d, reset %s",
+						h.name, res, h.wantAllowed, h.wantRemaining, h.wantReset)
+				}
+			}
+
+			// the keys are counted apart.
+			now = t0.Add(31 * time.Second)
+			if res, err := l.Allow(ctx, "other", limit, window); err != nil || !res.Allowed {
+				t.Errorf("Allow() of another key = %+v, %v, want allowed", res, err)
+			}
+		})
+	}
+}
+
+func TestRateUnmarshalText(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Rate
+		wantErr bool
+	}{
+		{in: "10/1m", want: Rate{Limit: 10, Window: time.Minute}},
+		{in: "5/15m", want: Rate{Limit: 5, Window: 15 * time.Minute}},
+		{in: "10", wantErr: true},
+		{in: "0/1m", wantErr: true},
+		{in: "10/0s", wantErr: true},
+		{in: "10/soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var got Rate
+			err := got.UnmarshalText([]byte(tt.in))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnmarshalText() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("UnmarshalText() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/cirius-go/portfolio-server/pkg/cache"
+)
+
+// Redis is a store keeping the counters in redis, shared by every instance.
+type Redis struct {
+	client redis.UniversalClient
+	closer func() error
+}
+
+// NewRedis creates a new Redis store connected with the config.
+func NewRedis(cfg *cache.RedisConfig) *Redis {
+	opts := &redis.Options{
+		Addr:     cfg.Addr,
+		Username: cfg.Username,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	}
+	if cfg.TLS {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	client := redis.NewClient(opts)
+	return &Redis{client: client, closer: client.Close}
+}
+
+// NewRedisWithClient creates a new Redis store on top of the client. The
+// client is not closed by the store.
+func NewRedisWithClient(client redis.UniversalClient) *Redis {
+	return &Redis{client: client}
+}
+
+// Incr implements Store. The counter is created with its expiry so that
+// the later hits keep it.
+func (r *Redis) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	var incr *redis.IntCmd
+	_, err := r.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.SetNX(ctx, key, 0, ttl)
+		incr = p.Incr(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+// Get implements Store.
+func (r *Redis) Get(ctx context.Context, key string) (int64, error) {
+	n, err := r.client.Get(ctx, key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return n, err
+}
+
+// Close closes the connection.
+func (r *Redis) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer()
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// allowedOrigins matches the origins allowed by the CORS settings, which can
+// be replaced while the server runs.
+type allowedOrigins struct {
+	credentials bool
+	patterns    atomic.Pointer[[]*regexp.Regexp]
+}
+
+func newAllowedOrigins(cfg CORSConfig) *allowedOrigins {
+	o := &allowedOrigins{credentials: cfg.AllowCredentials}
+	o.set(cfg.AllowOrigins)
+	return o
+}
+
+// set replaces the allowed origins, any origin being allowed when there are
+// none. The origins can contain the wildcards * and ?, e.g.
+// https://*.example.com.
+func (o *allowedOrigins) set(origins []string) {
+	if len(origins) == 0 {
+		origins = []string{"*"}
+	}
+
+	patterns := make([]*regexp.Regexp, 0, len(origins))
+	for _, origin := range origins {
+		// a wildcard origin must not be sent back with the credentials.
+		if origin == "*" && o.credentials {
+			continue
+		}
+		pattern := regexp.QuoteMeta(origin)
+		pattern = strings.ReplaceAll(pattern, `\*`, ".*")
+		pattern = strings.ReplaceAll(pattern, `\?`, ".")
+		patterns = append(patterns, regexp.MustCompile("^"+pattern+"$"))
+	}
+	o.patterns.Store(&patterns)
+}
+
+// allow reports whether the origin is allowed.
+func (o *allowedOrigins) allow(origin string) (bool, error) {
+	// 253 is the maximum length of a domain name.
+	if len(origin) > 253+len("https://")+len(":65535") {
+		return false, nil
+	}
+	for _, re := range *o.patterns.Load() {
+		if re.MatchString(origin) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
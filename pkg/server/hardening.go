@@ -0,0 +1,169 @@
+package server
+
+import (
+	"sort"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// SecureHeadersConfig contains the security headers of the responses.
+type SecureHeadersConfig struct {
+	Disabled bool     `envconfig:"DISABLED"`
+	Skip     []string `envconfig:"SKIP"` // route patterns, see MiddlewareSkipper.
+	// HSTSMaxAge is in seconds, HSTS is only sent over TLS and not at all when
+	// zero.
+	HSTSMaxAge            int    `envconfig:"HSTS_MAX_AGE"`
+	ContentSecurityPolicy string `envconfig:"CSP"`
+	// RelaxedContentSecurityPolicy is sent instead of ContentSecurityPolicy on
+	// the routes matching RelaxedRoutes, such as the swagger ui which loads
+	// scripts and styles.
+	RelaxedContentSecurityPolicy string   `envconfig:"RELAXED_CSP"`
+	RelaxedRoutes                []string `envconfig:"RELAXED_ROUTES"`
+	ReferrerPolicy               string   `envconfig:"REFERRER_POLICY"`
+	FrameOptions                 string   `envconfig:"FRAME_OPTIONS"`
+}
+
+// DefaultSecureHeaders returns the headers of an api which serves no page
+// but the swagger ui.
+func DefaultSecureHeaders() SecureHeadersConfig {
+	return SecureHeadersConfig{
+		HSTSMaxAge:                   365 * 24 * 60 * 60,
+		ContentSecurityPolicy:        "default-src 'none'; frame-ancestors 'none'",
+		RelaxedContentSecurityPolicy: "default-src 'self'; img-src 'self' data:; style-src 'self' 'unsafe-inline'; script-src 'self' 'unsafe-inline'; frame-ancestors 'none'",
+		RelaxedRoutes:                []string{"/swagger/*"},
+		ReferrerPolicy:               "no-referrer",
+		FrameOptions:                 "DENY",
+	}
+}
+
+// BodyLimitConfig contains the max sizes of the request bodies, such as
+// "512K" or "4M".
+type BodyLimitConfig struct {
+	Disabled bool     `envconfig:"DISABLED"`
+	Skip     []string `envconfig:"SKIP"` // route patterns, see MiddlewareSkipper.
+	Default  string   `envconfig:"DEFAULT" validate:"omitempty,bytesize"`
+	// Routes maps route patterns to their limit, the longest matching pattern
+	// wins over the default. Set from the environment as "pattern:limit"
+	// pairs, the patterns match the path params with * rather than :name.
+	Routes map[string]string `envconfig:"ROUTES" validate:"dive,bytesize"`
+}
+
+// DefaultBodyLimit returns the limits of an api taking small JSON bodies.
+func DefaultBodyLimit() BodyLimitConfig {
+	return BodyLimitConfig{
+		Default: "1M",
+	}
+}
+
+// TimeoutConfig contains the deadline of the context of each request, which
+// the services and the database queries honour.
+type TimeoutConfig struct {
+	Disabled bool          `envconfig:"DISABLED"`
+	Skip     []string      `envconfig:"SKIP"` // route patterns, see MiddlewareSkipper.
+	Duration time.Duration `envconfig:"DURATION"`
+}
+
+// DefaultTimeout returns the timeout of the requests.
+func DefaultTimeout() TimeoutConfig {
+	return TimeoutConfig{
+		Duration: 30 * time.Second,
+	}
+}
+
+// hardening returns the enabled middlewares of the configs.
+func hardening(headers SecureHeadersConfig, limit BodyLimitConfig, timeout TimeoutConfig) []echo.MiddlewareFunc {
+	var res []echo.MiddlewareFunc
+	if !headers.Disabled {
+		res = append(res, SecureHeaders(headers)...)
+	}
+	if !limit.Disabled && (limit.Default != "" || len(limit.Routes) > 0) {
+		res = append(res, BodyLimit(limit))
+	}
+	if !timeout.Disabled && timeout.Duration > 0 {
+		res = append(res, middleware.ContextTimeoutWithConfig(middleware.ContextTimeoutConfig{
+			Skipper: MiddlewareSkipper(timeout.Skip...),
+			Timeout: timeout.Duration,
+		}))
+	}
+	return res
+}
+
+// SecureHeaders returns the middlewares setting the security headers, with
+// the relaxed policy on the relaxed routes.
+func SecureHeaders(cfg SecureHeadersConfig) []echo.MiddlewareFunc {
+	var (
+		skip    = MiddlewareSkipper(cfg.Skip...)
+		relaxed = MiddlewareSkipper(cfg.RelaxedRoutes...)
+		secure  = func(csp string, skipper middleware.Skipper) echo.MiddlewareFunc {
+			return middleware.SecureWithConfig(middleware.SecureConfig{
+				Skipper:               skipper,
+				XSSProtection:         "0",
+				ContentTypeNosniff:    "nosniff",
+				XFrameOptions:         cfg.FrameOptions,
+				HSTSMaxAge:            cfg.HSTSMaxAge,
+				ContentSecurityPolicy: csp,
+				ReferrerPolicy:        cfg.ReferrerPolicy,
+			})
+		}
+	)
+
+	return []echo.MiddlewareFunc{
+		secure(cfg.ContentSecurityPolicy, func(c echo.Context) bool {
+			return skip(c) || relaxed(c)
+		}),
+		secure(cfg.RelaxedContentSecurityPolicy, func(c echo.Context) bool {
+			return skip(c) || !relaxed(c)
+		}),
+	}
+}
+
+// BodyLimit returns the middleware limiting the size of the request bodies
+// by route.
+func BodyLimit(cfg BodyLimitConfig) echo.MiddlewareFunc {
+	type route struct {
+		match middleware.Skipper
+		limit echo.MiddlewareFunc
+	}
+
+	patterns := make([]string, 0, len(cfg.Routes))
+	for p := range cfg.Routes {
+		patterns = append(patterns, p)
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		return len(patterns[i]) > len(patterns[j])
+	})
+
+	routes := make([]route, 0, len(patterns))
+	for _, p := range patterns {
+		routes = append(routes, route{
+			match: MiddlewareSkipper(p),
+			limit: middleware.BodyLimit(cfg.Routes[p]),
+		})
+	}
+	skip := MiddlewareSkipper(cfg.Skip...)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		def := next
+		if cfg.Default != "" {
+			def = middleware.BodyLimit(cfg.Default)(next)
+		}
+		limited := make([]echo.HandlerFunc, len(routes))
+		for i, r := range routes {
+			limited[i] = r.limit(next)
+		}
+
+		return func(c echo.Context) error {
+			if skip(c) {
+				return next(c)
+			}
+			for i, r := range routes {
+				if r.match(c) {
+					return limited[i](c)
+				}
+			}
+			return def(c)
+		}
+	}
+}
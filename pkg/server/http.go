@@ -2,20 +2,31 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
-	"os"
-	"os/signal"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/bmatcuk/doublestar"
-	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
+// CORSConfig contains the cross-origin resource sharing settings of a server.
+type CORSConfig struct {
+	AllowOrigins     []string `envconfig:"ALLOW_ORIGINS"`
+	AllowMethods     []string `envconfig:"ALLOW_METHODS"`
+	AllowHeaders     []string `envconfig:"ALLOW_HEADERS"` // the request headers are allowed when empty.
+	ExposeHeaders    []string `envconfig:"EXPOSE_HEADERS"`
+	AllowCredentials bool     `envconfig:"ALLOW_CREDENTIALS"`
+	MaxAge           int      `envconfig:"MAX_AGE" validate:"min=0"` // in seconds.
+}
+
 // HTTPConfig contains the config for an HTTP server.
 type HTTPConfig struct {
 	debug                bool
@@ -23,9 +34,20 @@ type HTTPConfig struct {
 	port                 int
 	readTimeout          time.Duration
 	writeTimeout         time.Duration
-	allowOrigins         []string
+	shutdownTimeout      time.Duration
+	drainDelay           time.Duration
+	secureHeaders        SecureHeadersConfig
+	bodyLimit            BodyLimitConfig
+	requestTimeout       TimeoutConfig
+	cors                 CORSConfig
+	trustedProxies       []string
+	tlsCertFile          string
+	tlsKeyFile           string
+	http2                bool
 	customErrorHandler   echo.HTTPErrorHandler
 	customRecoverLogFunc middleware.LogErrorFunc
+	metrics              prometheus.Registerer
+	onShutdown           []func()
 }
 
 // SetDebug set the debug.
@@ -46,6 +68,20 @@ func (h *HTTPConfig) SetCustomRecoverLogFunc(fn middleware.LogErrorFunc) *HTTPCo
 	return h
 }
 
+// SetMetrics set the registry of the request metrics, they are not recorded
+// when it is nil.
+func (h *HTTPConfig) SetMetrics(reg prometheus.Registerer) *HTTPConfig {
+	h.metrics = reg
+	return h
+}
+
+// OnShutdown adds a hook run when the server starts shutting down, before
+// the in-flight requests are drained.
+func (h *HTTPConfig) OnShutdown(fn func()) *HTTPConfig {
+	h.onShutdown = append(h.onShutdown, fn)
+	return h
+}
+
 // SetAddress set the address.
 func (h *HTTPConfig) SetAddress(host string, port int) *HTTPConfig {
 	h.host = host
@@ -65,6 +101,67 @@ func (h *HTTPConfig) SetWriteTimeout(d time.Duration) *HTTPConfig {
 	return h
 }
 
+// SetShutdownTimeout set how long the server takes at most to stop once its
+// context is done, the drain delay included.
+func (h *HTTPConfig) SetShutdownTimeout(d time.Duration) *HTTPConfig {
+	h.shutdownTimeout = d
+	return h
+}
+
+// SetDrainDelay set how long the server keeps serving after the shutdown
+// hooks ran, before it stops accepting connections, so that the load
+// balancer sees it is not ready and stops sending requests.
+func (h *HTTPConfig) SetDrainDelay(d time.Duration) *HTTPConfig {
+	h.drainDelay = d
+	return h
+}
+
+// SetSecureHeaders set the security headers of the responses.
+func (h *HTTPConfig) SetSecureHeaders(c SecureHeadersConfig) *HTTPConfig {
+	h.secureHeaders = c
+	return h
+}
+
+// SetBodyLimit set the max sizes of the request bodies.
+func (h *HTTPConfig) SetBodyLimit(c BodyLimitConfig) *HTTPConfig {
+	h.bodyLimit = c
+	return h
+}
+
+// SetRequestTimeout set the deadline of the context of each request.
+func (h *HTTPConfig) SetRequestTimeout(c TimeoutConfig) *HTTPConfig {
+	h.requestTimeout = c
+	return h
+}
+
+// SetCORS set the CORS settings.
+func (h *HTTPConfig) SetCORS(c CORSConfig) *HTTPConfig {
+	h.cors = c
+	return h
+}
+
+// SetTrustedProxies set the CIDR ranges of the proxies whose X-Forwarded-For
+// header gives the client IP. The client IP is the peer address when none is
+// set.
+func (h *HTTPConfig) SetTrustedProxies(cidrs ...string) *HTTPConfig {
+	h.trustedProxies = cidrs
+	return h
+}
+
+// SetTLS set the certificate and key files, the server is served over TLS
+// when they are set.
+func (h *HTTPConfig) SetTLS(certFile, keyFile string) *HTTPConfig {
+	h.tlsCertFile = certFile
+	h.tlsKeyFile = keyFile
+	return h
+}
+
+// SetHTTP2 set whether HTTP/2 is served, over TLS or as h2c over cleartext.
+func (h *HTTPConfig) SetHTTP2(enabled bool) *HTTPConfig {
+	h.http2 = enabled
+	return h
+}
+
 // C returns a default HTTP config.
 func C() *HTTPConfig {
 	return &HTTPConfig{
@@ -72,13 +169,23 @@ func C() *HTTPConfig {
 		port:         8080,
 		readTimeout:  60 * time.Second,
 		writeTimeout: 60 * time.Second,
+		// shutdownTimeout is below the 30s grace period of most
+		// orchestrators.
+		shutdownTimeout: 25 * time.Second,
+		cors: CORSConfig{
+			AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodPatch, http.MethodDelete, http.MethodOptions},
+		},
+		secureHeaders:  DefaultSecureHeaders(),
+		bodyLimit:      DefaultBodyLimit(),
+		requestTimeout: DefaultTimeout(),
 	}
 }
 
 // HTTP contains the dependencies for an HTTP server.
 type HTTP struct {
-	cfg  *HTTPConfig
-	Echo *echo.Echo
+	cfg     *HTTPConfig
+	origins *allowedOrigins
+	Echo    *echo.Echo
 }
 
 // NewHTTP creates a new HTTP server.
@@ -97,21 +204,32 @@ func NewHTTPWithConfig(cfg *HTTPConfig) *HTTP {
 	e.Server.ReadTimeout = cfg.readTimeout
 	e.Server.WriteTimeout = cfg.writeTimeout
 	e.Debug = cfg.debug
+	e.IPExtractor = ipExtractor(cfg.trustedProxies)
+	if cfg.http2 {
+		// h2c needs no upgrade, the clients must know that the server speaks it.
+		e.Server.Protocols = new(http.Protocols)
+		e.Server.Protocols.SetHTTP1(true)
+		e.Server.Protocols.SetHTTP2(true)
+		e.Server.Protocols.SetUnencryptedHTTP2(true)
+	}
 	if cfg.customErrorHandler != nil {
 		e.HTTPErrorHandler = cfg.customErrorHandler
 	}
+	recoverLog := cfg.customRecoverLogFunc
+	if recoverLog == nil {
+		recoverLog = logRecovered
+	}
+	origins := newAllowedOrigins(cfg.cors)
+	e.Use(Trace(), RequestID())
+	if cfg.metrics != nil {
+		e.Use(Metrics(cfg.metrics))
+	}
 	e.Use(
-		middleware.Logger(),
+		RequestLogger(),
 		middleware.RecoverWithConfig(middleware.RecoverConfig{
 			StackSize:       2 << 10,
 			DisableStackAll: true,
-			LogErrorFunc:    cfg.customRecoverLogFunc,
-		}),
-		middleware.RequestIDWithConfig(middleware.RequestIDConfig{
-			Generator: func() string {
-				u, _ := uuid.NewV7()
-				return u.String()
-			},
+			LogErrorFunc:    recoverLog,
 		}),
 		middleware.GzipWithConfig(middleware.GzipConfig{
 			Skipper: func(c echo.Context) bool {
@@ -119,42 +237,112 @@ func NewHTTPWithConfig(cfg *HTTPConfig) *HTTP {
 			},
 		}),
 		middleware.CORSWithConfig(middleware.CORSConfig{
-			AllowOrigins: cfg.allowOrigins,
-			AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodPatch, http.MethodDelete, http.MethodOptions},
+			AllowOriginFunc:  origins.allow,
+			AllowMethods:     cfg.cors.AllowMethods,
+			AllowHeaders:     cfg.cors.AllowHeaders,
+			ExposeHeaders:    cfg.cors.ExposeHeaders,
+			AllowCredentials: cfg.cors.AllowCredentials,
+			MaxAge:           cfg.cors.MaxAge,
 		}),
 	)
+	e.Use(hardening(cfg.secureHeaders, cfg.bodyLimit, cfg.requestTimeout)...)
 
 	return &HTTP{
-		cfg:  cfg,
-		Echo: e,
+		cfg:     cfg,
+		origins: origins,
+		Echo:    e,
 	}
 }
 
-// Start starts the HTTP server.
-func StartHTTP(h *HTTP) error {
-	go func() {
-		if err := h.Echo.StartServer(h.Echo.Server); err != nil {
-			if err == http.ErrServerClosed {
-				return
-			}
+// SetAllowOrigins replaces the origins allowed by CORS while the server runs.
+func (h *HTTP) SetAllowOrigins(origins ...string) {
+	h.origins.set(origins)
+}
 
-			fmt.Printf("failed to start HTTP server: %v\n", err)
+// Serve serves HTTP until ctx is done, then runs the shutdown hooks and waits
+// for the in-flight requests within the shutdown timeout. It returns the
+// error which prevented the server from starting, such as a port in use, or
+// the one of the shutdown.
+func (h *HTTP) Serve(ctx context.Context) error {
+	if h.cfg.tlsCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(h.cfg.tlsCertFile, h.cfg.tlsKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load the TLS certificate: %w", err)
+		}
+		h.Echo.Server.TLSConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			NextProtos:   []string{"http/1.1"},
+		}
+		if h.cfg.http2 {
+			h.Echo.Server.TLSConfig.NextProtos = []string{"h2", "http/1.1"}
 		}
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- h.Echo.StartServer(h.Echo.Server)
 	}()
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
-	<-quit
+	select {
+	case err := <-served:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return fmt.Errorf("failed to start HTTP server: %w", err)
+	case <-ctx.Done():
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	if err := h.Echo.Shutdown(ctx); err != nil {
-		fmt.Printf("⇨ http server shutting down error: %v\n", err)
+	for _, fn := range h.cfg.onShutdown {
+		fn()
+	}
+	if h.cfg.drainDelay > 0 {
+		time.Sleep(h.cfg.drainDelay)
 	}
 
+	sctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), h.cfg.shutdownTimeout-h.cfg.drainDelay)
+	defer cancel()
+	if err := h.Echo.Shutdown(sctx); err != nil {
+		return fmt.Errorf("failed to shut down HTTP server: %w", err)
+	}
 	return nil
 }
 
+// ipExtractor reads the client IP from the X-Forwarded-For header set by the
+// trusted proxies, ranges or single IPs, or from the peer address when there
+// is none.
+func ipExtractor(cidrs []string) echo.IPExtractor {
+	if len(cidrs) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	opts := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, cidr := range cidrs {
+		ipNet, err := parseIPRange(cidr)
+		if err != nil {
+			slog.Error("invalid trusted proxy range", "cidr", cidr, "error", err)
+			continue
+		}
+		opts = append(opts, echo.TrustIPRange(ipNet))
+	}
+	return echo.ExtractIPFromXFFHeader(opts...)
+}
+
+// parseIPRange parses a CIDR range, or a single IP as a /32 or /128 range.
+func parseIPRange(s string) (*net.IPNet, error) {
+	if ip := net.ParseIP(s); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+	}
+	_, ipNet, err := net.ParseCIDR(s)
+	return ipNet, err
+}
+
 // MiddlewareSkipper return new skipper for echo router.
 func MiddlewareSkipper(patterns ...string) middleware.Skipper {
 	return func(c echo.Context) bool {
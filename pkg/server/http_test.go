@@ -0,0 +1,35 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestIPExtractor(t *testing.T) {
+	extract := ipExtractor([]string{"10.0.0.0/8", "192.0.2.1", "2001:db8::1"})
+
+	tests := []struct {
+		name   string
+		remote string
+		xff    string
+		want   string
+	}{
+		{name: "trusted range", remote: "10.1.2.3", xff: "203.0.113.7", want: "203.0.113.7"},
+		{name: "trusted ip", remote: "192.0.2.1", xff: "203.0.113.7", want: "203.0.113.7"},
+		{name: "trusted ipv6", remote: "[2001:db8::1]", xff: "203.0.113.7", want: "203.0.113.7"},
+		{name: "untrusted peer", remote: "192.0.2.2", xff: "203.0.113.7", want: "192.0.2.2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remote + ":1234"
+			req.Header.Set(echo.HeaderXForwardedFor, tt.xff)
+			if got := extract(req); got != tt.want {
+				t.Errorf("client ip = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
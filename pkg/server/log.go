@@ -0,0 +1,65 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/cirius-go/portfolio-server/pkg/logging"
+)
+
+// RequestLogger returns a middleware which puts a logger carrying the request
+// ID and the route into the request context, and logs the request once it is
+// served.
+func RequestLogger() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var (
+				start = time.Now()
+				r     = c.Request()
+				l     = logging.FromContext(r.Context()).With(
+					"request_id", c.Response().Header().Get(echo.HeaderXRequestID),
+					"method", r.Method,
+					"route", c.Path(),
+				)
+			)
+			c.SetRequest(r.WithContext(logging.WithContext(r.Context(), l)))
+
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			// the handlers may have enriched the logger, e.g. with the user.
+			l = logging.FromContext(c.Request().Context())
+
+			status := c.Response().Status
+			level := slog.LevelInfo
+			switch {
+			case status >= http.StatusInternalServerError:
+				level = slog.LevelError
+			case status >= http.StatusBadRequest:
+				level = slog.LevelWarn
+			}
+
+			l.LogAttrs(r.Context(), level, "request",
+				slog.String("uri", r.RequestURI),
+				slog.Int("status", status),
+				slog.Duration("latency", time.Since(start)),
+				slog.Int64("bytes_out", c.Response().Size),
+				slog.String("remote_ip", c.RealIP()),
+			)
+			return nil
+		}
+	}
+}
+
+// logRecovered logs the recovered panics with their stack.
+func logRecovered(c echo.Context, err error, stack []byte) error {
+	logging.FromContext(c.Request().Context()).Error("panic recovered",
+		"error", err,
+		"stack", string(stack),
+	)
+	return err
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics returns a middleware which records the RED metrics of the requests
+// in the registry: their count, their duration and the requests in flight,
+// labeled by route template, method and status.
+func Metrics(reg prometheus.Registerer) echo.MiddlewareFunc {
+	var (
+		labels   = []string{"route", "method", "status"}
+		requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Number of served HTTP requests.",
+		}, labels)
+		duration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Duration of the served HTTP requests.",
+			Buckets: prometheus.DefBuckets,
+		}, labels)
+		inFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "Number of HTTP requests being served.",
+		})
+	)
+	reg.MustRegister(requests, duration, inFlight)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			inFlight.Inc()
+			defer inFlight.Dec()
+
+			start := time.Now()
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+			lvs := []string{route, c.Request().Method, strconv.Itoa(c.Response().Status)}
+			requests.WithLabelValues(lvs...).Inc()
+			duration.WithLabelValues(lvs...).Observe(time.Since(start).Seconds())
+			return nil
+		}
+	}
+}
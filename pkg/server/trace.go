@@ -0,0 +1,85 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/cirius-go/portfolio-server/pkg/tracing"
+)
+
+const tracerName = "github.com/cirius-go/portfolio-server/pkg/server"
+
+// Trace returns a middleware which starts a server span per request with the
+// global tracer provider. The span continues the trace of the W3C traceparent
+// header of the request, if any.
+func Trace() echo.MiddlewareFunc {
+	tracer := otel.Tracer(tracerName)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var (
+				r     = c.Request()
+				route = c.Path()
+				ctx   = otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			)
+
+			name := r.Method
+			if route != "" {
+				name = fmt.Sprintf("%s %s", r.Method, route)
+			}
+			ctx, span := tracer.Start(ctx, name,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(r.Method),
+					semconv.HTTPRoute(route),
+					semconv.URLPath(r.URL.Path),
+					semconv.ClientAddress(c.RealIP()),
+				),
+			)
+			defer span.End()
+
+			c.SetRequest(r.WithContext(ctx))
+			if err := next(c); err != nil {
+				span.RecordError(err)
+				c.Error(err)
+			}
+
+			status := c.Response().Status
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+			return nil
+		}
+	}
+}
+
+// RequestID returns a middleware which sets the X-Request-Id response header.
+// The trace ID of the request is used when it has one so that the logs, the
+// traces and the responses correlate, then the request header, then a new
+// UUID.
+func RequestID() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			id := tracing.TraceID(c.Request().Context())
+			if id == "" {
+				id = c.Request().Header.Get(echo.HeaderXRequestID)
+			}
+			if id == "" {
+				u, _ := uuid.NewV7()
+				id = u.String()
+			}
+
+			c.Response().Header().Set(echo.HeaderXRequestID, id)
+			return next(c)
+		}
+	}
+}
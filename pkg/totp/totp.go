@@ -0,0 +1,151 @@
+package totp
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"image/png"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+// recoveryCodeAlphabet excludes characters that are easy to confuse (0/O, 1/I/L).
+const recoveryCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// Key represents a generated TOTP key.
+type Key struct {
+	Secret string
+	URI    string
+	QRCode []byte // PNG encoded QR code of the URI.
+}
+
+// Config contains the config for generating TOTP keys.
+type Config struct {
+	issuer string
+	qrSize int
+}
+
+// C returns a default TOTP config.
+func C() *Config {
+	return &Config{
+		issuer: "portfolio-server",
+		qrSize: 256,
+	}
+}
+
+// SetIssuer set the issuer shown in authenticator apps.
+func (c *Config) SetIssuer(issuer string) *Config {
+	c.issuer = issuer
+	return c
+}
+
+// SetQRSize set the width and height of the QR code image.
+func (c *Config) SetQRSize(size int) *Config {
+	c.qrSize = size
+	return c
+}
+
+// TOTP generates and validates time-based one-time passwords.
+type TOTP struct {
+	cfg *Config
+}
+
+// New creates a new TOTP service with config.
+func New(cfg *Config) *TOTP {
+	return &TOTP{cfg: cfg}
+}
+
+// Generate generates a new key for the account.
+func (t *TOTP) Generate(account string) (*Key, error) {
+	k, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      t.cfg.issuer,
+		AccountName: account,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := k.Image(t.cfg.qrSize, t.cfg.qrSize)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, img); err != nil {
+		return nil, err
+	}
+
+	return &Key{
+		Secret: k.Secret(),
+		URI:    k.URL(),
+		QRCode: buf.Bytes(),
+	}, nil
+}
+
+// period is the validity of a passcode in seconds.
+const period = 30
+
+// Validate validates the passcode against the secret, allowing one period of
+// clock skew.
+func (t *TOTP) Validate(passcode, secret string) bool {
+	_, ok := t.ValidateStep(passcode, secret, 0)
+	return ok
+}
+
+// ValidateStep validates the passcode against the secret like Validate, and
+// returns the time step it belongs to. The passcodes of the steps up to
+// lastStep are rejected, so that a passcode cannot be used twice once its
+// step is stored as the last one.
+func (t *TOTP) ValidateStep(passcode, secret string, lastStep int64) (int64, bool) {
+	passcode = strings.TrimSpace(passcode)
+	now := time.Now().Unix() / period
+
+	for _, step := range []int64{now - 1, now, now + 1} {
+		if step <= lastStep {
+			continue
+		}
+		code, err := totp.GenerateCodeCustom(secret, time.Unix(step*period, 0), totp.ValidateOpts{
+			Period:    period,
+			Digits:    otp.DigitsSix,
+			Algorithm: otp.AlgorithmSHA1,
+		})
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(code), []byte(passcode)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// NewRecoveryCodes generates n random recovery codes formatted as XXXXX-XXXXX.
+func NewRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	for range n {
+		b := make([]byte, 10)
+		for i := range b {
+			idx, err := rand.Int(rand.Reader, big.NewInt(int64(len(recoveryCodeAlphabet))))
+			if err != nil {
+				return nil, err
+			}
+			b[i] = recoveryCodeAlphabet[idx.Int64()]
+		}
+		codes = append(codes, string(b[:5])+"-"+string(b[5:]))
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode returns the hash of the recovery code to be stored.
+// Codes are normalized so that dashes, spaces and case are ignored.
+func HashRecoveryCode(code string) string {
+	code = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,45 @@
+package totp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+)
+
+func TestValidateStep(t *testing.T) {
+	key, err := New(C()).Generate("user@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	code, err := totp.GenerateCode(key.Secret, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	step := now.Unix() / period
+
+	tests := []struct {
+		name     string
+		passcode string
+		lastStep int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "valid", passcode: code, wantStep: step, wantOK: true},
+		{name: "spaces are trimmed", passcode: " " + code + " ", wantStep: step, wantOK: true},
+		{name: "earlier step used", passcode: code, lastStep: step - 1, wantStep: step, wantOK: true},
+		{name: "replayed", passcode: code, lastStep: step},
+		{name: "later step used", passcode: code, lastStep: step + 1},
+		{name: "wrong code", passcode: "000000x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := New(C()).ValidateStep(tt.passcode, key.Secret, tt.lastStep)
+			if ok != tt.wantOK || got != tt.wantStep {
+				t.Errorf("ValidateStep() = %d, %v, want %d, %v", got, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// Package tracing sets up the OpenTelemetry tracer provider of the server.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporter is the destination of the spans.
+// ENUM(otlp,stdout,none)
+//
+//go:generate go-enum --marshal --names --values
+type Exporter string
+
+// Config contains the tracing configuration.
+type Config struct {
+	Exporter    Exporter `envconfig:"EXPORTER" validate:"enum"`
+	ServiceName string   `envconfig:"SERVICE_NAME"`
+	// Endpoint is the OTLP/HTTP collector, the OTEL_EXPORTER_OTLP_* variables
+	// apply when empty.
+	Endpoint string `envconfig:"ENDPOINT"`
+	Insecure bool   `envconfig:"INSECURE"` // sends to the endpoint over http.
+	// SampleRatio is the share of the new traces which are recorded, the
+	// traces started upstream follow the decision of their parent.
+	SampleRatio float64 `envconfig:"SAMPLE_RATIO" validate:"min=0,max=1"`
+}
+
+// Setup creates the tracer provider and makes it the global one, along with
+// the W3C trace context and baggage propagators.
+//
+// With no exporter, trace IDs are still generated and propagated but no span
+// is recorded. The provider must be shut down to flush the pending spans.
+func Setup(ctx context.Context, cfg Config) (*sdktrace.TracerProvider, error) {
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(semconv.ServiceName(cfg.ServiceName)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	}
+
+	switch cfg.Exporter {
+	case ExporterOtlp:
+		var httpOpts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			httpOpts = append(httpOpts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			httpOpts = append(httpOpts, otlptracehttp.WithInsecure())
+		}
+		exp, err := otlptracehttp.New(ctx, httpOpts...)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	case ExporterStdout:
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	case ExporterNone:
+		opts = append(opts, sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.NeverSample())))
+	default:
+		return nil, fmt.Errorf("unsupported trace exporter %q", cfg.Exporter)
+	}
+
+	tp := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	return tp, nil
+}
+
+// TraceID returns the trace ID of the span of ctx, or an empty string when
+// ctx has no valid span.
+func TraceID(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.HasTraceID() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
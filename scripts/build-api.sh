@@ -2,6 +2,11 @@
 
 set -e
 
+# ldflags stamps the build time read by the /version endpoint.
+ldflags() {
+  echo "-X github.com/cirius-go/portfolio-server/pkg/health.buildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+}
+
 BUILD_DIR="$PWD/cmd/infra/aws/.build/lambda"
 BUILD_FN_DIR="$BUILD_DIR/api"
 CMD_DIR="$PWD/cmd/api"
@@ -31,9 +36,9 @@ build_project() {
   fi
 
   if [[ -n "$buildTags" ]]; then
-    go build -trimpath -buildvcs=true -tags="$buildTags" -ldflags="-s -w" -o "$bootstrapFile" "$CMD_DIR"
+    go build -trimpath -buildvcs=true -tags="$buildTags" -ldflags="-s -w $(ldflags)" -o "$bootstrapFile" "$CMD_DIR"
   else
-    go build -trimpath -buildvcs=true -ldflags="-s -w" -o "$bootstrapFile" "$CMD_DIR"
+    go build -trimpath -buildvcs=true -ldflags="-s -w $(ldflags)" -o "$bootstrapFile" "$CMD_DIR"
   fi
 }
 
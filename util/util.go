@@ -99,6 +99,15 @@ func (qd *QueryDecoder) Decode(val string) error {
 	return nil
 }
 
+// String encodes the query string, sorted by key.
+func (qd QueryDecoder) String() string {
+	v := url.Values{}
+	for key, val := range qd {
+		v.Set(key, val)
+	}
+	return v.Encode()
+}
+
 // PrintJSON prints the json with indentation.
 func PrintJSON(v any, p ...bool) {
 	if len(p) > 0 && p[0] {